@@ -0,0 +1,117 @@
+// Package amqpopts provides standard AMQP 0-9-1 (e.g. RabbitMQ) connection
+// flags which build a config for github.com/rabbitmq/amqp091-go:
+//
+//	type Consume struct {
+//		amqpopts.Options
+//	}
+//
+//	func (c *Consume) Run(ctx context.Context) error {
+//		conn, err := c.Dial()
+//		if err != nil {
+//			return err
+//		}
+//		defer conn.Close()
+//		...
+//	}
+//
+// This package is a separate module so that the cli module does not depend on
+// amqp091-go.
+package amqpopts
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/isobit/cli/tlsopts"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// Options can be embedded in a command config to add AMQP connection flags.
+type Options struct {
+	URL                   string        `cli:"name=amqp-url,env=AMQP_URL,secret,placeholder=URL,help=AMQP URL (amqp:// or amqps://) including any credentials and vhost"`
+	TLSCAFile             string        `cli:"name=amqp-tls-ca,env=AMQP_TLS_CA,placeholder=PATH,help=PEM file of CA certificates to trust"`
+	TLSCertFile           string        `cli:"name=amqp-tls-cert,env=AMQP_TLS_CERT,placeholder=PATH,help=PEM client certificate file"`
+	TLSKeyFile            string        `cli:"name=amqp-tls-key,env=AMQP_TLS_KEY,placeholder=PATH,help=PEM client key file"`
+	TLSInsecureSkipVerify bool          `cli:"name=amqp-tls-insecure-skip-verify,help=skip server certificate verification (insecure)"`
+	SASLMechanism         string        `cli:"name=amqp-sasl-mechanism,env=AMQP_SASL_MECHANISM,placeholder=MECHANISM,help=SASL mechanism (PLAIN/AMQPLAIN/EXTERNAL) (PLAIN if not set)"`
+	Heartbeat             time.Duration `cli:"name=amqp-heartbeat,env=AMQP_HEARTBEAT,nodefault,placeholder=DURATION,help=heartbeat interval (10s if not set)"`
+	ConnectionName        string        `cli:"name=amqp-connection-name,env=AMQP_CONNECTION_NAME,placeholder=NAME,help=connection name to show in the broker's management UI"`
+}
+
+// Build validates the options and returns the URL and config to pass to
+// amqp.DialConfig.
+func (opts *Options) Build() (string, amqp.Config, error) {
+	if opts.URL == "" {
+		return "", amqp.Config{}, fmt.Errorf("no AMQP URL specified (use --amqp-url)")
+	}
+	uri, err := amqp.ParseURI(opts.URL)
+	if err != nil {
+		// Errors from ParseURI don't include the URL, so there's no
+		// password to leak.
+		return "", amqp.Config{}, fmt.Errorf("invalid --amqp-url: %w", err)
+	}
+
+	config := amqp.Config{
+		Heartbeat:  10 * time.Second,
+		Locale:     "en_US",
+		Properties: amqp.NewConnectionProperties(),
+	}
+	if opts.Heartbeat != 0 {
+		config.Heartbeat = opts.Heartbeat
+	}
+	if opts.ConnectionName != "" {
+		config.Properties.SetClientConnectionName(opts.ConnectionName)
+	}
+
+	usesTLS := opts.TLSCAFile != "" || opts.TLSCertFile != "" || opts.TLSInsecureSkipVerify
+	if usesTLS && uri.Scheme != "amqps" {
+		return "", amqp.Config{}, fmt.Errorf("TLS options require an amqps:// URL")
+	}
+	if uri.Scheme == "amqps" {
+		tlsConfig, err := tlsopts.Load(opts.TLSCAFile, opts.TLSCertFile, opts.TLSKeyFile, opts.TLSInsecureSkipVerify)
+		if err != nil {
+			return "", amqp.Config{}, err
+		}
+		tlsConfig.ServerName = uri.Host
+		config.TLSClientConfig = tlsConfig
+	}
+
+	switch strings.ToUpper(opts.SASLMechanism) {
+	case "", "PLAIN":
+		config.SASL = []amqp.Authentication{uri.PlainAuth()}
+	case "AMQPLAIN":
+		config.SASL = []amqp.Authentication{uri.AMQPlainAuth()}
+	case "EXTERNAL":
+		if opts.TLSCertFile == "" {
+			return "", amqp.Config{}, fmt.Errorf("SASL mechanism EXTERNAL requires a client certificate (use --amqp-tls-cert)")
+		}
+		config.SASL = []amqp.Authentication{&amqp.ExternalAuth{}}
+	default:
+		return "", amqp.Config{}, fmt.Errorf("unsupported SASL mechanism %q: must be one of: PLAIN, AMQPLAIN, EXTERNAL", opts.SASLMechanism)
+	}
+	return opts.URL, config, nil
+}
+
+// Redacted returns the URL with any password masked, for logging.
+func (opts *Options) Redacted() string {
+	u, err := url.Parse(opts.URL)
+	if err != nil {
+		return ""
+	}
+	return u.Redacted()
+}
+
+// Dial connects to the broker using the options built by Build.
+func (opts *Options) Dial() (*amqp.Connection, error) {
+	u, config, err := opts.Build()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := amqp.DialConfig(u, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", opts.Redacted(), err)
+	}
+	return conn, nil
+}
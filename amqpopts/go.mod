@@ -0,0 +1,18 @@
+module github.com/isobit/cli/amqpopts
+
+go 1.22
+
+require (
+	github.com/isobit/cli v0.0.0
+	github.com/rabbitmq/amqp091-go v1.10.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/huandu/xstrings v1.4.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/stretchr/testify v1.9.0
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/isobit/cli => ../
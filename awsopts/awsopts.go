@@ -0,0 +1,72 @@
+// Package awsopts provides standard AWS flags which build an aws.Config for
+// use with the AWS SDK for Go v2:
+//
+//	type Sync struct {
+//		awsopts.Options
+//	}
+//
+//	func (s *Sync) Run(ctx context.Context) error {
+//		cfg, err := s.Build(ctx)
+//		if err != nil {
+//			return err
+//		}
+//		client := s3.NewFromConfig(cfg)
+//		...
+//	}
+//
+// The flags' environment variables are the ones used by the SDK (e.g.
+// AWS_REGION and AWS_PROFILE), so they behave the same as in other AWS tools.
+//
+// This package is a separate module so that the cli module does not depend on
+// the AWS SDK.
+package awsopts
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+)
+
+// Options can be embedded in a command config to add AWS flags. Empty values
+// are left to the SDK's default configuration chain.
+type Options struct {
+	Region                string `cli:"name=aws-region,env=AWS_REGION,placeholder=REGION,help=AWS region"`
+	Profile               string `cli:"name=aws-profile,env=AWS_PROFILE,placeholder=NAME,help=AWS shared config profile"`
+	SharedConfigFile      string `cli:"name=aws-config-file,env=AWS_CONFIG_FILE,placeholder=PATH,help=AWS shared config file (~/.aws/config if not set)"`
+	SharedCredentialsFile string `cli:"name=aws-shared-credentials-file,env=AWS_SHARED_CREDENTIALS_FILE,placeholder=PATH,help=AWS shared credentials file (~/.aws/credentials if not set)"`
+	EndpointURL           string `cli:"name=aws-endpoint-url,env=AWS_ENDPOINT_URL,placeholder=URL,help=base URL for AWS service endpoints (e.g. for LocalStack)"`
+}
+
+// Build loads an aws.Config using the SDK's default configuration chain with
+// the options applied. Additional load options can be passed to override
+// them.
+func (opts *Options) Build(ctx context.Context, optFns ...func(*config.LoadOptions) error) (aws.Config, error) {
+	var loadOpts []func(*config.LoadOptions) error
+	if opts.Region != "" {
+		loadOpts = append(loadOpts, config.WithRegion(opts.Region))
+	}
+	if opts.Profile != "" {
+		loadOpts = append(loadOpts, config.WithSharedConfigProfile(opts.Profile))
+	}
+	if opts.SharedConfigFile != "" {
+		loadOpts = append(loadOpts, config.WithSharedConfigFiles([]string{opts.SharedConfigFile}))
+	}
+	if opts.SharedCredentialsFile != "" {
+		loadOpts = append(loadOpts, config.WithSharedCredentialsFiles([]string{opts.SharedCredentialsFile}))
+	}
+	loadOpts = append(loadOpts, optFns...)
+
+	cfg, err := config.LoadDefaultConfig(ctx, loadOpts...)
+	if err != nil {
+		return aws.Config{}, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	if cfg.Region == "" {
+		return aws.Config{}, fmt.Errorf("no AWS region specified (use --aws-region or set it in the profile)")
+	}
+	if opts.EndpointURL != "" {
+		cfg.BaseEndpoint = aws.String(opts.EndpointURL)
+	}
+	return cfg, nil
+}
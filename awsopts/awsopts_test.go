@@ -0,0 +1,69 @@
+package awsopts
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/isobit/cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func clearEnv(t *testing.T) {
+	for _, key := range []string{
+		"AWS_REGION", "AWS_DEFAULT_REGION", "AWS_PROFILE", "AWS_CONFIG_FILE",
+		"AWS_SHARED_CREDENTIALS_FILE", "AWS_ENDPOINT_URL",
+		"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN",
+	} {
+		t.Setenv(key, "")
+	}
+}
+
+func TestBuild(t *testing.T) {
+	clearEnv(t)
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "config")
+	require.NoError(t, os.WriteFile(configFile, []byte("[profile test]\nregion = eu-west-1\n"), 0o600))
+	credentialsFile := filepath.Join(dir, "credentials")
+	require.NoError(t, os.WriteFile(credentialsFile, []byte("[test]\naws_access_key_id = AKIDTEST\naws_secret_access_key = secret\n"), 0o600))
+
+	config := &struct {
+		Options
+	}{}
+	cmd := cli.New("test", config)
+	assert.Contains(t, cmd.HelpString(), "--aws-region <REGION>")
+	require.NoError(t, cmd.ParseArgs([]string{
+		"--aws-profile", "test",
+		"--aws-config-file", configFile,
+		"--aws-shared-credentials-file", credentialsFile,
+		"--aws-endpoint-url", "http://localhost:4566",
+	}).Err)
+
+	cfg, err := config.Build(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "eu-west-1", cfg.Region)
+	require.NotNil(t, cfg.BaseEndpoint)
+	assert.Equal(t, "http://localhost:4566", *cfg.BaseEndpoint)
+	creds, err := cfg.Credentials.Retrieve(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "AKIDTEST", creds.AccessKeyID)
+
+	config.Region = "us-east-2"
+	cfg, err = config.Build(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "us-east-2", cfg.Region)
+}
+
+func TestBuildNoRegion(t *testing.T) {
+	clearEnv(t)
+	dir := t.TempDir()
+	opts := &Options{
+		SharedConfigFile:      filepath.Join(dir, "config"),
+		SharedCredentialsFile: filepath.Join(dir, "credentials"),
+	}
+	_, err := opts.Build(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no AWS region specified")
+}
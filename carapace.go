@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// carapaceSpec is a command in the carapace-spec format
+// (https://github.com/carapace-sh/carapace-spec). The format is YAML, but
+// since YAML is a superset of JSON, it is written as JSON.
+type carapaceSpec struct {
+	Name        string            `json:"name"`
+	Aliases     []string          `json:"aliases,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Flags       map[string]string `json:"flags,omitempty"`
+	Commands    []carapaceSpec    `json:"commands,omitempty"`
+}
+
+// WriteCarapaceSpec writes a spec for the command and all of its visible
+// subcommands in the carapace-spec format, which universal completion
+// frameworks such as carapace can use to provide completions for most shells
+// without per-shell completion scripts. Save the output as <name>.yaml in
+// carapace's specs directory (see "carapace --help").
+func (cmd *Command) WriteCarapaceSpec(w io.Writer) error {
+	b, err := json.MarshalIndent(cmd.carapaceSpec(), "", "  ")
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+	_, err = w.Write(b)
+	return err
+}
+
+func (cmd *Command) carapaceSpec() carapaceSpec {
+	spec := carapaceSpec{
+		Name:        cmd.name,
+		Description: cmd.help,
+		Flags:       map[string]string{},
+	}
+	for _, f := range cmd.Fields() {
+		if f.Hidden {
+			continue
+		}
+		spec.Flags[carapaceFlag(f)] = f.Help
+		if f.Negatable {
+			spec.Flags["--no-"+f.Name] = f.Help
+		}
+	}
+	for _, subCmd := range cmd.commands {
+		if subCmd.hidden || !cmd.commandAvailable(subCmd) {
+			continue
+		}
+		spec.Commands = append(spec.Commands, subCmd.carapaceSpec())
+	}
+	return spec
+}
+
+// carapaceFlag returns the flag definition for f, e.g. "-n, --name=", where
+// the suffix modifiers are "*" for repeatable flags, "=" for flags which take
+// a value, and "!" for required flags.
+func carapaceFlag(f FieldInfo) string {
+	sb := strings.Builder{}
+	if f.Short != "" {
+		sb.WriteString("-" + f.Short + ", ")
+	}
+	sb.WriteString("--" + f.Name)
+	if f.Repeatable {
+		sb.WriteString("*")
+	}
+	if f.HasArg {
+		sb.WriteString("=")
+	}
+	if f.Required {
+		sb.WriteString("!")
+	}
+	return sb.String()
+}
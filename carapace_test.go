@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteCarapaceSpec(t *testing.T) {
+	cmd := New(
+		"test",
+		&struct {
+			Verbose bool `cli:"short=v,help=verbose output"`
+			Secret  bool `cli:"hidden"`
+		}{},
+		New(
+			"run",
+			&struct {
+				Name string   `cli:"required,help=name to use"`
+				Tags []string `cli:"append"`
+			}{},
+			WithHelp("run something"),
+		),
+		New("internal", &struct{}{}, WithHidden(true)),
+	)
+	b := &strings.Builder{}
+	require.NoError(t, cmd.WriteCarapaceSpec(b))
+
+	expected := `{
+		"name": "test",
+		"flags": {
+			"-h, --help": "show usage help",
+			"-v, --verbose": "verbose output"
+		},
+		"commands": [
+			{
+				"name": "run",
+				"description": "run something",
+				"flags": {
+					"-h, --help": "show usage help",
+					"--name=!": "name to use",
+					"--tags*=": ""
+				}
+			}
+		]
+	}`
+	assert.JSONEq(t, expected, b.String())
+}
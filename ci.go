@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"io"
+	"os"
+	"strconv"
+)
+
+// CIEnvironment describes a continuous integration environment detected by
+// DetectCI, along with suggested adjustments to default behavior.
+type CIEnvironment struct {
+	// Provider is a short name for the CI provider, e.g. "github-actions" or
+	// "gitlab". It is "unknown" if CI=true is set but the provider could not
+	// be identified.
+	Provider string
+
+	// DisableColor is true if the provider's log viewer does not render ANSI
+	// escape sequences, so output should not be styled.
+	DisableColor bool
+
+	// NoInput is true if commands should not prompt for input. This is always
+	// true, since there is nobody to answer prompts in CI.
+	NoInput bool
+
+	// PlainProgress is true if progress should be reported with plain log
+	// lines rather than animated spinners or progress bars.
+	PlainProgress bool
+}
+
+type ciProvider struct {
+	name         string
+	envVar       string
+	rendersColor bool
+}
+
+var ciProviders = []ciProvider{
+	{name: "github-actions", envVar: "GITHUB_ACTIONS", rendersColor: true},
+	{name: "gitlab", envVar: "GITLAB_CI", rendersColor: true},
+	{name: "buildkite", envVar: "BUILDKITE", rendersColor: true},
+	{name: "circleci", envVar: "CIRCLECI", rendersColor: true},
+	{name: "travis", envVar: "TRAVIS", rendersColor: true},
+	{name: "azure-pipelines", envVar: "TF_BUILD"},
+	{name: "bitbucket", envVar: "BITBUCKET_BUILD_NUMBER"},
+	{name: "appveyor", envVar: "APPVEYOR"},
+	{name: "drone", envVar: "DRONE"},
+	{name: "codebuild", envVar: "CODEBUILD_BUILD_ID"},
+	{name: "jenkins", envVar: "JENKINS_URL"},
+	{name: "teamcity", envVar: "TEAMCITY_VERSION"},
+}
+
+// DetectCI returns information about the CI environment the process is
+// running in, based on environment variables set by common CI providers. The
+// second return value is false if no CI environment was detected.
+func DetectCI() (CIEnvironment, bool) {
+	for _, p := range ciProviders {
+		if os.Getenv(p.envVar) != "" {
+			return CIEnvironment{
+				Provider:      p.name,
+				DisableColor:  !p.rendersColor,
+				NoInput:       true,
+				PlainProgress: true,
+			}, true
+		}
+	}
+	if isCIEnvSet() {
+		return CIEnvironment{
+			Provider:      "unknown",
+			DisableColor:  true,
+			NoInput:       true,
+			PlainProgress: true,
+		}, true
+	}
+	return CIEnvironment{}, false
+}
+
+// isCIEnvSet returns true if the CI environment variable is set to a true
+// value, which most CI providers do.
+func isCIEnvSet() bool {
+	ci, err := strconv.ParseBool(os.Getenv("CI"))
+	return err == nil && ci
+}
+
+// detectedCI returns the detected CI environment if DetectCI is enabled on
+// the CLI.
+func (cli *CLI) detectedCI() (CIEnvironment, bool) {
+	if !cli.DetectCI {
+		return CIEnvironment{}, false
+	}
+	return DetectCI()
+}
+
+// styledWriter is like the styledWriter function, but it also strips styling
+// if a CI environment which doesn't render color has been detected.
+func (cli *CLI) styledWriter(w io.Writer) io.Writer {
+	if ci, ok := cli.detectedCI(); ok && ci.DisableColor {
+		return &ansiStripWriter{w: w}
+	}
+	return styledWriter(w)
+}
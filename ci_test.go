@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func clearCIEnv(t *testing.T) {
+	for _, key := range []string{"CI", "NO_INPUT"} {
+		t.Setenv(key, "")
+		os.Unsetenv(key)
+	}
+	for _, p := range ciProviders {
+		t.Setenv(p.envVar, "")
+		os.Unsetenv(p.envVar)
+	}
+}
+
+func TestDetectCI(t *testing.T) {
+	clearCIEnv(t)
+	_, ok := DetectCI()
+	assert.False(t, ok)
+
+	t.Setenv("CI", "true")
+	ci, ok := DetectCI()
+	assert.True(t, ok)
+	assert.Equal(t, CIEnvironment{
+		Provider:      "unknown",
+		DisableColor:  true,
+		NoInput:       true,
+		PlainProgress: true,
+	}, ci)
+
+	t.Setenv("GITHUB_ACTIONS", "true")
+	ci, ok = DetectCI()
+	assert.True(t, ok)
+	assert.Equal(t, "github-actions", ci.Provider)
+	assert.False(t, ci.DisableColor)
+}
+
+func TestCLIDetectCI(t *testing.T) {
+	clearCIEnv(t)
+	t.Setenv("JENKINS_URL", "http://jenkins")
+	ctx := WithInteractive(context.Background(), true)
+
+	c := &interactiveTestCmd{}
+	err := New("test", c).ParseArgs([]string{}).RunWithContext(ctx)
+	require.NoError(t, err)
+	assert.True(t, c.interactive)
+
+	c = &interactiveTestCmd{}
+	cli := NewCLI()
+	cli.DetectCI = true
+	err = cli.New("test", c).ParseArgs([]string{}).RunWithContext(ctx)
+	require.NoError(t, err)
+	assert.False(t, c.interactive)
+
+	b := &strings.Builder{}
+	w := cli.styledWriter(b)
+	w.Write([]byte("\x1b[1mbold\x1b[0m"))
+	assert.Equal(t, "bold", b.String())
+}
@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"context"
 	"io"
 	"os"
 )
@@ -10,19 +11,48 @@ import (
 // for most cases, but custom CLI structs can be used to modify behavior.
 type CLI struct {
 	// HelpWriter is used to print help output when calling ParseResult.Run
-	// (and other similar methods).
+	// (and other similar methods). ANSI escape sequences in help text are
+	// stripped if HelpWriter is not a terminal.
 	HelpWriter io.Writer
 
 	// ErrWriter is used to print errors when calling ParseResult.Run (and
-	// other similar methods).
+	// other similar methods). ANSI escape sequences in errors are stripped if
+	// ErrWriter is not a terminal.
 	ErrWriter io.Writer
 
+	// Stdin, Stdout, and Stderr are the streams which commands should use
+	// for input and output, accessed using the Stdin, Stdout, and Stderr
+	// functions with the context passed to Run. If nil, os.Stdin, os.Stdout,
+	// and os.Stderr are used. Setting these allows capturing all output in
+	// tests or when embedding a CLI.
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+
+	// Exit is called with the exit code by RunFatal (and the other RunFatal
+	// methods) instead of os.Exit, if set. This allows testing programs
+	// whose main function calls RunFatal, e.g. with testable examples.
+	Exit func(code int)
+
+	// TeeOutput, if set, receives a copy of everything the command writes to
+	// Stdout and Stderr (as returned by the Stdout and Stderr functions),
+	// e.g. a log file or buffer to attach to reports. Writes are serialized,
+	// so the streams are interleaved in the order they were written.
+	TeeOutput io.Writer
+
 	// LookupEnv is called during parsing for any fields which define an env
 	// var key, but are not set by argument.
 	LookupEnv LookupEnvFunc
 
+	// LookupEnvBatch, if set, is used instead of LookupEnv to look up the
+	// env var keys for all of a command's fields in a single call (in field
+	// order), so that remote providers can fetch values in one round trip.
+	LookupEnvBatch LookupEnvBatchFunc
+
 	// Setter can be used to define custom setters for arbitrary field types,
-	// or to override the default field setters.
+	// or to override the default field setters. If the returned Setter also
+	// implements ContextSetter, it will be passed information about the field
+	// being set, such as its name and tags.
 	//
 	// Here is an example which uses a custom layout for parsing any time.Time
 	// fields:
@@ -47,6 +77,229 @@ type CLI struct {
 	//  	}
 	//  }
 	Setter SetterFunc
+
+	// FlagNameFunc is called to derive a flag name from a struct field name
+	// when the field does not have an explicit name. If FlagNameFunc is nil
+	// or returns an empty string, the field name is converted to kebab-case
+	// (e.g. "FooBar" becomes "foo-bar").
+	FlagNameFunc func(fieldName string) string
+
+	// FallbackNameTags lists struct tag keys (e.g. "json" or "yaml") which
+	// will be checked, in order, for a field name when the cli tag does not
+	// specify one. This allows structs which are shared between config files
+	// and flags to only need one naming source. Setting it also enables
+	// fields with an empty env tag to use an environment variable named
+	// after the flag (e.g. "listen_addr" becomes "LISTEN_ADDR").
+	FallbackNameTags []string
+
+	// UsageErrorHelp controls how much help text is printed to HelpWriter
+	// when a usage error occurs. Explicitly requested help is always printed
+	// in full.
+	UsageErrorHelp HelpVerbosity
+
+	// HelpFlagName is the name of the help flag which is automatically added
+	// to every command. If empty, "help" is used.
+	HelpFlagName string
+
+	// HelpFlagShortName is the short name of the help flag. If empty, "h" is
+	// used. The short name is not added if another field already uses it.
+	HelpFlagShortName string
+
+	// DisableHelpFlag disables automatically adding the help flag.
+	DisableHelpFlag bool
+
+	// DisableHelpFlagShortName disables adding a short name to the help flag,
+	// e.g. so that "-h" is not accidentally treated as help when it is
+	// intended to mean something else.
+	DisableHelpFlagShortName bool
+
+	// AutoShortNames enables automatic assignment of short names to fields
+	// which do not have one, using the first letter of the field name when
+	// that letter is unambiguous and not already in use. The assignments can
+	// be listed using Command.AssignedShortNames. Hidden fields are not
+	// assigned short names.
+	AutoShortNames bool
+
+	// NegatableFlags makes every bool field negatable, as if it had the
+	// negatable tag, so that it can be set to false using a "--no-" prefixed
+	// flag (e.g. "--no-color" for --color).
+	NegatableFlags bool
+
+	// SortFields sorts fields alphabetically by name in help text, rather
+	// than in declaration order, so that help output doesn't change when
+	// struct fields are reordered or moved between embedded structs. Fields
+	// with an order tag are still grouped by it.
+	SortFields bool
+
+	// SlashFlags enables parsing of Windows-style "/flag value" and
+	// "/flag:value" arguments, in addition to the usual dash-prefixed forms.
+	// Arguments which start with a slash but do not match a known flag name
+	// are treated as non-flag arguments.
+	SlashFlags bool
+
+	// ResponseFiles enables expansion of "@file" arguments, which are
+	// replaced by the arguments read from the file (one per line) before
+	// parsing. This is useful when argument lists would otherwise exceed OS
+	// limits. Only whole arguments are expanded, so "--flag=@file" can still
+	// be used to pass a file to json and yaml fields. See the README for the
+	// response file syntax.
+	ResponseFiles bool
+
+	// ArgPreprocessor, if set, is called with the args passed to
+	// Command.ParseArgs (after any response file expansion), and its result
+	// is parsed instead. This can be used to implement custom expansions,
+	// such as globbing or alias substitution, in one place.
+	ArgPreprocessor func(args []string) ([]string, error)
+
+	// CommandAliases, if set, is called to load user-defined command aliases
+	// (similar to git's alias.* config) when the root command is passed an
+	// unknown subcommand name. If an alias matches, it is replaced by its
+	// expansion, which is split into arguments using shell-like quoting
+	// rules. Aliases cannot override existing commands. See AliasFile for
+	// loading aliases from a file.
+	CommandAliases func() (map[string]string, error)
+
+	// DisableEnv disables environment variable parsing for all commands, so
+	// that LookupEnv is never called for their fields. Parsing can also be
+	// disabled for individual commands using Command.DisableEnv.
+	DisableEnv bool
+
+	// ExpandEnv enables expansion of "${VAR}" sequences in flag and
+	// environment variable values using LookupEnv. Unset variables expand to
+	// an empty string, and "$$" can be used to escape a literal "$". Values
+	// are not expanded for commands with environment variables disabled.
+	ExpandEnv bool
+
+	// Transform, if set, is called with every raw value (from any source)
+	// before it is passed to the field's setter, and the returned value is
+	// set instead. This can be used to implement conventions such as
+	// decrypting or base64-decoding values without needing per-type setters.
+	// The SetterContext can be used to check the field name and tags (e.g.
+	// extension tags, see ExtensionTagPrefixes).
+	Transform func(ctx SetterContext, raw string) (string, error)
+
+	// AutoEnv enables environment variables for all fields, even if they do
+	// not have an env tag. Environment variable names are derived from the
+	// flag name (e.g. "foo-bar" becomes "FOO_BAR"), prefixed by EnvPrefix.
+	AutoEnv bool
+
+	// EnvPrefix is prepended to derived environment variable names, i.e. for
+	// fields with an empty env tag, or all fields if AutoEnv is enabled.
+	// Explicit env tag values are not affected. An empty env tag has no
+	// effect unless EnvPrefix, FallbackNameTags, or AutoEnv is set.
+	EnvPrefix string
+
+	// WarnUnknownEnv enables printing a warning to ErrWriter for any
+	// variables in the environment which start with EnvPrefix but do not
+	// match any field in the command tree, to help catch typos.
+	WarnUnknownEnv bool
+
+	// ConfigFile is the path to a JSON config file whose values are used for
+	// any fields which are not set by argument or environment variable. The
+	// file should contain an object whose keys are flag names; arrays can be
+	// used to set append fields multiple times. If the file does not exist,
+	// it is ignored. See also WatchConfig.
+	ConfigFile string
+
+	// ValueSources are consulted, in order, for the values of any fields
+	// which are not set by argument, environment variable, or ConfigFile.
+	// See MapValueSource for adapting other configuration libraries.
+	ValueSources []ValueSource
+
+	// SecretResolvers maps schemes to SecretResolvers. Fields with an env tag
+	// of the form "scheme:ref" (e.g. "env=vault:secret/data/app#token") will
+	// have their value resolved by passing ref to the SecretResolver
+	// registered for scheme, instead of calling LookupEnv. See also
+	// RegisterSecretResolver.
+	SecretResolvers map[string]SecretResolver
+
+	// ExtensionTagPrefixes lists prefixes of cli tag keys which should be
+	// collected as field metadata rather than causing an unknown tag error.
+	// This allows frameworks built on top of this package to attach their
+	// own per-field metadata, which can be retrieved using
+	// Command.FieldMeta. See also AllowExtensionTags.
+	ExtensionTagPrefixes []string
+
+	// DetectCI enables detection of CI environments using the DetectCI
+	// function. If a CI environment is detected, Interactive will return
+	// false, and ANSI styling is stripped from help and error output if the
+	// CI provider doesn't render it.
+	DetectCI bool
+
+	// Middleware is a list of functions which wrap the Run method of every
+	// command, in order (i.e. the first Middleware is the outermost). See
+	// also Use.
+	Middleware []Middleware
+
+	// ExperimentalEnv is the name of an environment variable which enables
+	// experimental commands and flags when set to a true value. They can
+	// also be enabled by embedding ExperimentalOptions in a command config.
+	ExperimentalEnv string
+
+	// FeatureEnabled is called to check whether a named feature is enabled,
+	// so that feature flag systems can be integrated. It is consulted for
+	// experimental commands (using the command name as the feature name)
+	// and experimental flags (using the experimental tag value, or the flag
+	// name), and is available to Run methods using the FeatureEnabled
+	// function.
+	FeatureEnabled func(name string) bool
+
+	// Authorize is called for each command being run (the root command
+	// first, then each subcommand) before its Before method, so that
+	// policies such as requiring a role or specific environment can be
+	// enforced consistently, e.g. based on command annotations. If it
+	// returns an error, parsing stops and the error is returned wrapped in an
+	// AuthorizationError.
+	Authorize func(cmd *Command) error
+
+	// HelpRenderer, if set, is used to render help text instead of the
+	// built-in templates. Usage, help topic, and field help text are still
+	// rendered using the built-in templates. If Render returns an error, it
+	// is printed to ErrWriter and DefaultHelpRenderer is used instead.
+	HelpRenderer HelpRenderer
+
+	// HelpCommandLimit limits the number of subcommands listed in help
+	// text, which keeps help readable for commands with hundreds of
+	// subcommands. If more are omitted, a hint to use "help --filter" to
+	// search the subcommands is shown instead. Zero means no limit.
+	HelpCommandLimit int
+
+	// CacheHelp enables caching of rendered help text, which is useful when
+	// help is rendered repeatedly (e.g. by completion engines introspecting
+	// many commands). Each command's cache is invalidated when the command
+	// (or a subcommand listed in its help) is modified or its fields are set,
+	// and experimental commands and flags are rechecked each time, but
+	// changes made directly to config structs (or to the environment, other
+	// than enabling experimental features) after building are not detected.
+	CacheHelp bool
+
+	// ProfilingFlags enables hidden --cpuprofile, --memprofile, and --trace
+	// flags on every command, which write pprof profiles or an execution
+	// trace to the given file around the command's Run method.
+	ProfilingFlags bool
+
+	// Version is the version of the program, which is included in crash
+	// reports.
+	Version string
+
+	// CrashReports enables writing a diagnostic bundle to a temp file when a
+	// command's Run method panics or returns an error (other than a usage
+	// error), and printing its path to ErrWriter so that users can attach it
+	// to bug reports. The bundle contains the version, args, environment
+	// variables starting with EnvPrefix, the error or panic stack trace, and
+	// the run duration. Values of fields with the secret tag are redacted.
+	CrashReports bool
+
+	// OnSecondSignal is called by RunWithSigCancel and RunFatalWithSigCancel
+	// if a second shutdown signal (see ContextWithSigCancel) is received
+	// after the first one cancelled the command's context, e.g. to exit
+	// immediately with ExitOnSignal or to log that shutdown is still in
+	// progress. Further signals are ignored after it returns. If nil, the
+	// second signal terminates the process using the default Go runtime
+	// behavior.
+	OnSecondSignal func(sig os.Signal)
+
+	configWatch *configWatch
 }
 
 func NewCLI() *CLI {
@@ -58,6 +311,30 @@ func NewCLI() *CLI {
 	}
 }
 
+// AllowExtensionTags adds prefix to ExtensionTagPrefixes, and returns the CLI
+// for further method chaining. An empty prefix allows all unknown tag keys.
+func (cli *CLI) AllowExtensionTags(prefix string) *CLI {
+	cli.ExtensionTagPrefixes = append(cli.ExtensionTagPrefixes, prefix)
+	return cli
+}
+
+// RegisterSecretResolver adds a SecretResolver for the given scheme to
+// SecretResolvers, and returns the CLI for further method chaining.
+func (cli *CLI) RegisterSecretResolver(scheme string, resolver SecretResolver) *CLI {
+	if cli.SecretResolvers == nil {
+		cli.SecretResolvers = map[string]SecretResolver{}
+	}
+	cli.SecretResolvers[scheme] = resolver
+	return cli
+}
+
+// Use adds middleware to Middleware, and returns the CLI for further method
+// chaining.
+func (cli *CLI) Use(middleware ...Middleware) *CLI {
+	cli.Middleware = append(cli.Middleware, middleware...)
+	return cli
+}
+
 var defaultCLI *CLI = NewCLI()
 
 // osLookupEnv wraps os.LookupEnv as a LookupEnvFunc
@@ -89,6 +366,33 @@ func Build(name string, config interface{}, opts ...CommandOption) (*Command, er
 	return defaultCLI.Build(name, config, opts...)
 }
 
+// HelpVerbosity controls how much help text is printed. See
+// CLI.UsageErrorHelp.
+type HelpVerbosity int
+
+const (
+	// HelpFull prints the full help text.
+	HelpFull HelpVerbosity = iota
+
+	// HelpUsage prints only the USAGE section of the help text.
+	HelpUsage
+
+	// HelpNone does not print any help text.
+	HelpNone
+)
+
 type LookupEnvFunc func(key string) (val string, ok bool, err error)
 
+// LookupEnvBatchFunc looks up the values of multiple keys at once. The
+// returned map should only contain the keys which are set.
+type LookupEnvBatchFunc func(keys []string) (map[string]string, error)
+
 type SetterFunc func(interface{}) Setter
+
+// Middleware wraps the Run method of a command. It must call next (usually
+// with the same context) to run the command, and should return its error.
+type Middleware func(ctx context.Context, cmd *Command, next func(context.Context) error) error
+
+// SecretResolver resolves a secret reference (the part of an env tag after the
+// scheme) to its value. If the secret does not exist, ok should be false.
+type SecretResolver func(ref string) (val string, ok bool, err error)
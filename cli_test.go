@@ -1,6 +1,8 @@
 package cli
 
 import (
+	"context"
+	"encoding/base64"
 	"fmt"
 	"net/url"
 	"strings"
@@ -337,6 +339,105 @@ func TestCLIArgsField(t *testing.T) {
 	assert.Equal(t, expected, cmd)
 }
 
+func TestCLIPositionalArgs(t *testing.T) {
+	type Cmd struct {
+		Force bool     `cli:"short=f"`
+		Dst   string   `cli:"arg=1,required,help=destination path"`
+		Src   string   `cli:"arg=0,required,help=source path"`
+		Mode  int      `cli:"arg=2,placeholder=MODE"`
+		Rest  []string `cli:"args"`
+	}
+
+	cmd := &Cmd{Mode: 644}
+	r := New("cp", cmd).ParseArgs([]string{"-f", "a.txt", "b.txt"})
+	require.NoError(t, r.Err)
+	assert.Equal(t, &Cmd{Force: true, Src: "a.txt", Dst: "b.txt", Mode: 644}, cmd)
+
+	cmd = &Cmd{}
+	r = New("cp", cmd).ParseArgs([]string{"a.txt", "b.txt", "600", "x", "y"})
+	require.NoError(t, r.Err)
+	assert.Equal(t, &Cmd{Src: "a.txt", Dst: "b.txt", Mode: 600, Rest: []string{"x", "y"}}, cmd)
+
+	r = New("cp", &Cmd{}).ParseArgs([]string{"a.txt"})
+	require.Error(t, r.Err)
+	assert.Equal(t, "missing required argument: DST", r.Err.Error())
+
+	r = New("cp", &Cmd{}).ParseArgs([]string{"a.txt", "b.txt", "rw"})
+	require.Error(t, r.Err)
+	assert.Contains(t, r.Err.Error(), `invalid value "rw" for argument MODE`)
+
+	help := New("cp", &Cmd{Mode: 644}).HelpString()
+	assert.Contains(t, help, "cp [OPTIONS] <SRC> <DST> [MODE] [ARGS]")
+	assert.Contains(t, help, "<SRC>   source path")
+	assert.Contains(t, help, "[MODE]  (default: 644)")
+}
+
+func TestCLIPositionalArgsSetFieldValue(t *testing.T) {
+	type Cmd struct {
+		Name string `cli:"arg=0"`
+		Pin  int    `cli:"arg=1,secret"`
+	}
+	cli := NewCLI()
+	cli.LookupEnv = func(key string) (string, bool, error) {
+		return map[string]string{"NAME": "world"}[key], key == "NAME", nil
+	}
+	cli.ExpandEnv = true
+	cli.Transform = func(ctx SetterContext, raw string) (string, error) {
+		return strings.TrimSpace(raw), nil
+	}
+	cmd := &Cmd{}
+	r := cli.New("test", cmd).ParseArgs([]string{"hello-${NAME}", " 1234 "})
+	require.NoError(t, r.Err)
+	assert.Equal(t, &Cmd{Name: "hello-world", Pin: 1234}, cmd)
+
+	r = cli.New("test", &Cmd{}).ParseArgs([]string{"x", "abcd"})
+	require.Error(t, r.Err)
+	assert.Contains(t, r.Err.Error(), "invalid value for argument PIN")
+	assert.NotContains(t, r.Err.Error(), "abcd")
+}
+
+func TestCLIPositionalArgsTooMany(t *testing.T) {
+	type Cmd struct {
+		Name string `cli:"arg=0"`
+	}
+	cmd := &Cmd{}
+	r := New("test", cmd).ParseArgs([]string{})
+	require.NoError(t, r.Err)
+
+	r = New("test", cmd).ParseArgs([]string{"a", "b"})
+	require.Error(t, r.Err)
+	assert.Equal(t, "too many arguments (expected at most 1)", r.Err.Error())
+}
+
+func TestCLIPositionalArgsInvalid(t *testing.T) {
+	_, err := NewCLI().Build("test", &struct {
+		A string `cli:"arg=0"`
+		B string `cli:"arg=2"`
+	}{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no field defined for arg=1")
+
+	_, err = NewCLI().Build("test", &struct {
+		A string `cli:"arg=0"`
+		B string `cli:"arg=0"`
+	}{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "multiple fields defined for arg=0")
+
+	_, err = NewCLI().Build("test", &struct {
+		A string `cli:"arg=0"`
+		B string `cli:"arg=1,required"`
+	}{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "required argument B cannot follow optional argument A")
+
+	_, err = NewCLI().Build("test", &struct {
+		A string `cli:"arg=first"`
+	}{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "arg must be a non-negative integer index")
+}
+
 type BoomBeforeCmd struct{}
 
 func (BoomBeforeCmd) Before() error {
@@ -388,3 +489,653 @@ func TestCLIConflicting(t *testing.T) {
 	_, err := Build("test", &Cmd{})
 	require.Error(t, err)
 }
+
+func TestCLIBoolPointer(t *testing.T) {
+	type Cmd struct {
+		Unset *bool
+		True  *bool
+		False *bool `cli:"short=f"`
+	}
+	cmd := &Cmd{}
+	r := New("test", cmd).
+		ParseArgs([]string{
+			"--true",
+			"-f=false",
+		})
+	require.NoError(t, r.Err)
+
+	assert.Nil(t, cmd.Unset)
+	require.NotNil(t, cmd.True)
+	assert.True(t, *cmd.True)
+	require.NotNil(t, cmd.False)
+	assert.False(t, *cmd.False)
+}
+
+func TestCLIIsSet(t *testing.T) {
+	type Cmd struct {
+		Foo string `cli:"short=f"`
+		Bar string
+		Baz string `cli:"env=BAZ"`
+	}
+	t.Setenv("BAZ", "")
+	c := New("test", &Cmd{})
+	r := c.ParseArgs([]string{"-f", ""})
+	require.NoError(t, r.Err)
+	assert.True(t, c.IsSet("foo"))
+	assert.True(t, c.IsSet("f"))
+	assert.False(t, c.IsSet("bar"))
+	assert.True(t, c.IsSet("baz"))
+	assert.False(t, c.IsSet("not-a-field"))
+}
+
+func TestCLIAutoShortNames(t *testing.T) {
+	type Cmd struct {
+		Verbose bool
+		Force   bool
+		Format  string
+		Output  string `cli:"short=x"`
+		Host    string
+		Xtra    string
+		Name    string
+	}
+	cli := NewCLI()
+	cli.AutoShortNames = true
+	cmd := &Cmd{}
+	c := cli.New("test", cmd)
+	assert.Equal(t, map[string]string{"verbose": "v", "name": "n"}, c.AssignedShortNames())
+
+	r := c.ParseArgs([]string{"-v", "-n", "foo"})
+	require.NoError(t, r.Err)
+	assert.True(t, cmd.Verbose)
+	assert.Equal(t, "foo", cmd.Name)
+
+	// Hidden fields (including profiling flags) are not assigned short names,
+	// and don't make the short names of other fields ambiguous.
+	type HiddenCmd struct {
+		Config  string
+		Mode    string
+		Timeout string
+		Dir     string
+		Debug   bool `cli:"hidden"`
+	}
+	cli.ProfilingFlags = true
+	c = cli.New("test", &HiddenCmd{})
+	assert.Equal(
+		t,
+		map[string]string{"config": "c", "mode": "m", "timeout": "t", "dir": "d"},
+		c.AssignedShortNames(),
+	)
+}
+
+func TestCLIAlias(t *testing.T) {
+	type Cmd struct {
+		Remove      bool   `cli:"alias=rm"`
+		Interactive bool   `cli:"short=i"`
+		Tty         bool   `cli:"short=t"`
+		Image       string `cli:"alias=img"`
+	}
+	cmd := &Cmd{}
+	r := New("test", cmd).
+		ParseArgs([]string{
+			"-rm",
+			"-it",
+			"-img=foo",
+		})
+	require.NoError(t, r.Err)
+
+	expected := &Cmd{
+		Remove:      true,
+		Interactive: true,
+		Tty:         true,
+		Image:       "foo",
+	}
+	assert.Equal(t, expected, cmd)
+}
+
+func TestCLISingleDashLongName(t *testing.T) {
+	type Cmd struct {
+		All     bool `cli:"short=a"`
+		Brief   bool `cli:"short=b"`
+		Ab      bool
+		Verbose bool
+	}
+	cmd := &Cmd{}
+	r := New("test", cmd).ParseArgs([]string{"-ab"})
+	require.NoError(t, r.Err)
+	assert.Equal(t, &Cmd{All: true, Brief: true}, cmd)
+
+	r = New("test", &Cmd{}).ParseArgs([]string{"-verbose"})
+	assert.Error(t, r.Err)
+}
+
+func TestCLIMultipleAliases(t *testing.T) {
+	type Cmd struct {
+		Output string `cli:"short=o,alias='out|outp',deprecated-alias='output-file|outfile',help=output path"`
+	}
+	for _, arg := range []string{"--output", "--out", "-out", "--output-file", "-outfile"} {
+		cmd := &Cmd{}
+		r := New("test", cmd).ParseArgs([]string{arg, "foo"})
+		require.NoError(t, r.Err, arg)
+		assert.Equal(t, "foo", cmd.Output, arg)
+	}
+
+	help := New("test", &Cmd{}).HelpString()
+	assert.Contains(t, help, "-o, -out, -outp, --output <VALUE>")
+	assert.NotContains(t, help, "output-file")
+	assert.NotContains(t, help, "outfile")
+
+	assert.Panics(t, func() {
+		New("test", &struct {
+			Output string `cli:"deprecated-alias='out|o'"`
+		}{})
+	})
+}
+
+func TestCLINegatableFlags(t *testing.T) {
+	type Cmd struct {
+		Color   bool `cli:"negatable,help=colorize output"`
+		Verbose bool
+	}
+	cmd := &Cmd{Color: true}
+	c := New("test", cmd)
+	r := c.ParseArgs([]string{"--no-color"})
+	require.NoError(t, r.Err)
+	assert.False(t, cmd.Color)
+	assert.True(t, c.IsSet("color"))
+	assert.Equal(t, []FlagValue{{Name: "color", Value: "false"}}, c.FlagSequence())
+
+	cmd = &Cmd{Color: true}
+	r = New("test", cmd).ParseArgs([]string{"--no-color", "--color"})
+	require.NoError(t, r.Err)
+	assert.True(t, cmd.Color)
+
+	r = New("test", &Cmd{}).ParseArgs([]string{"--no-color=true"})
+	require.Error(t, r.Err)
+	assert.Contains(t, r.Err.Error(), "flag no-color does not take a value")
+
+	r = New("test", &Cmd{}).ParseArgs([]string{"--no-verbose"})
+	require.Error(t, r.Err)
+	assert.Contains(t, r.Err.Error(), "flag provided but not defined: no-verbose")
+
+	help := New("test", &Cmd{}).HelpString()
+	assert.Contains(t, help, "--[no-]color  colorize output")
+	assert.NotContains(t, help, "[no-]verbose")
+
+	cli := NewCLI()
+	cli.NegatableFlags = true
+	cmd = &Cmd{Verbose: true}
+	r = cli.New("test", cmd).ParseArgs([]string{"--no-verbose"})
+	require.NoError(t, r.Err)
+	assert.False(t, cmd.Verbose)
+
+	_, err := NewCLI().Build("test", &struct {
+		Name string `cli:"negatable"`
+	}{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "field has negatable tag but is not a bool")
+
+	_, err = NewCLI().Build("test", &struct {
+		Cache   bool `cli:"negatable"`
+		NoCache bool
+	}{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "multiple fields defined for name: no-cache")
+}
+
+func TestCLISlashFlags(t *testing.T) {
+	type Cmd struct {
+		Verbose bool   `cli:"short=v"`
+		Output  string `cli:"short=o"`
+		Level   int
+		Args    []string `cli:"args"`
+	}
+	cli := NewCLI()
+	cli.SlashFlags = true
+	cmd := &Cmd{}
+	r := cli.New("test", cmd).
+		ParseArgs([]string{
+			"/v",
+			"/output", "out.txt",
+			"/level:3",
+			"/usr/bin",
+		})
+	require.NoError(t, r.Err)
+
+	expected := &Cmd{
+		Verbose: true,
+		Output:  "out.txt",
+		Level:   3,
+		Args:    []string{"/usr/bin"},
+	}
+	assert.Equal(t, expected, cmd)
+}
+
+func TestCLIArgPreprocessor(t *testing.T) {
+	type Cmd struct {
+		Verbose bool
+		Level   int
+	}
+	cli := NewCLI()
+	cli.ArgPreprocessor = func(args []string) ([]string, error) {
+		processed := []string{}
+		for _, arg := range args {
+			switch arg {
+			case "--debug":
+				processed = append(processed, "--verbose", "--level", "9")
+			case "--boom":
+				return nil, fmt.Errorf("boom")
+			default:
+				processed = append(processed, arg)
+			}
+		}
+		return processed, nil
+	}
+
+	cmd := &Cmd{}
+	r := cli.New("test", cmd).ParseArgs([]string{"--debug"})
+	require.NoError(t, r.Err)
+	assert.Equal(t, &Cmd{Verbose: true, Level: 9}, cmd)
+
+	r = cli.New("test", &Cmd{}).ParseArgs([]string{"--boom"})
+	assert.EqualError(t, r.Err, "boom")
+}
+
+func TestCLIPerCommandOverrides(t *testing.T) {
+	cli := NewCLI()
+	cli.LookupEnv = func(key string) (string, bool, error) {
+		return "root", true, nil
+	}
+
+	cmd := &struct {
+		Foo string `cli:"env=FOO"`
+	}{}
+	subcmd := &struct {
+		Bar  string    `cli:"env=BAR"`
+		Time time.Time `cli:"env=TIME"`
+	}{}
+	otherSubcmd := &struct {
+		Baz string `cli:"env=BAZ"`
+	}{}
+
+	r := cli.New(
+		"test", cmd,
+		cli.New(
+			"sub", subcmd,
+			WithLookupEnv(func(key string) (string, bool, error) {
+				if key == "TIME" {
+					return "12:30PM", true, nil
+				}
+				return "sub", true, nil
+			}),
+			WithSetter(func(i interface{}) Setter {
+				if v, ok := i.(*time.Time); ok {
+					return (*customTime)(v)
+				}
+				return nil
+			}),
+		),
+		cli.New("other", otherSubcmd),
+	).
+		ParseArgs([]string{"sub"})
+	require.NoError(t, r.Err)
+	assert.Equal(t, "root", cmd.Foo)
+	assert.Equal(t, "sub", subcmd.Bar)
+	assert.Equal(t, time.Date(0, time.January, 1, 12, 30, 0, 0, time.UTC), subcmd.Time)
+
+	// Overrides should not leak into the shared CLI.
+	assert.Nil(t, cli.Setter)
+	r = cli.New("test", otherSubcmd).ParseArgs([]string{})
+	require.NoError(t, r.Err)
+	assert.Equal(t, "root", otherSubcmd.Baz)
+}
+
+func TestCLISecretResolver(t *testing.T) {
+	cli := NewCLI().
+		RegisterSecretResolver("vault", func(ref string) (string, bool, error) {
+			switch ref {
+			case "secret/data/app#token":
+				return "s3cr3t", true, nil
+			case "secret/data/app#boom":
+				return "", false, fmt.Errorf("boom")
+			default:
+				return "", false, nil
+			}
+		})
+	cli.LookupEnv = func(key string) (string, bool, error) {
+		return "", false, nil
+	}
+
+	type Cmd struct {
+		Token   string `cli:"env=vault:secret/data/app#token"`
+		Missing string `cli:"env=vault:secret/data/app#missing"`
+	}
+	cmd := &Cmd{Missing: "default"}
+	r := cli.New("test", cmd).ParseArgs([]string{})
+	require.NoError(t, r.Err)
+	assert.Equal(t, &Cmd{Token: "s3cr3t", Missing: "default"}, cmd)
+
+	r = cli.New("test", &struct {
+		Boom string `cli:"env=vault:secret/data/app#boom"`
+	}{}).ParseArgs([]string{})
+	assert.Error(t, r.Err)
+
+	r = cli.New("test", &struct {
+		Unknown string `cli:"env=sops:foo"`
+	}{}).ParseArgs([]string{})
+	assert.Error(t, r.Err)
+}
+
+func TestCLITransform(t *testing.T) {
+	cli := NewCLI().AllowExtensionTags("x-")
+	cli.LookupEnv = func(key string) (string, bool, error) {
+		return "aGVsbG8=", true, nil
+	}
+	cli.Transform = func(ctx SetterContext, raw string) (string, error) {
+		if _, ok := ctx.Tags["x-base64"]; !ok {
+			return raw, nil
+		}
+		b, err := base64.StdEncoding.DecodeString(raw)
+		if err != nil {
+			return "", fmt.Errorf("invalid base64 for %s: %w", ctx.FieldName, err)
+		}
+		return string(b), nil
+	}
+
+	type Cmd struct {
+		Plain   string
+		Encoded string `cli:"x-base64"`
+		FromEnv string `cli:"x-base64,env=FROM_ENV"`
+	}
+	cmd := &Cmd{}
+	r := cli.New("test", cmd).ParseArgs([]string{
+		"--plain", "aGVsbG8=",
+		"--encoded", "d29ybGQ=",
+	})
+	require.NoError(t, r.Err)
+	assert.Equal(t, &Cmd{Plain: "aGVsbG8=", Encoded: "world", FromEnv: "hello"}, cmd)
+
+	r = cli.New("test", &Cmd{}).ParseArgs([]string{"--encoded", "!!!"})
+	assert.Error(t, r.Err)
+}
+
+func TestCLIEnvNonEmpty(t *testing.T) {
+	type Cmd struct {
+		Foo string `cli:"env=FOO,env-nonempty"`
+		Bar string `cli:"env=BAR"`
+	}
+
+	t.Setenv("FOO", "")
+	t.Setenv("BAR", "")
+	r := New("test", &Cmd{}).ParseArgs([]string{})
+	require.Error(t, r.Err)
+	assert.Contains(t, r.Err.Error(), "FOO is set but empty")
+
+	cmd := &Cmd{Bar: "default"}
+	r = New("test", cmd).ParseArgs([]string{"--foo", "ok"})
+	require.NoError(t, r.Err)
+	assert.Equal(t, &Cmd{Foo: "ok", Bar: ""}, cmd)
+}
+
+type defaultsTestCmd struct {
+	Addr    string
+	Timeout time.Duration
+}
+
+func (cmd *defaultsTestCmd) Defaults() {
+	cmd.Addr = "localhost:8080"
+	cmd.Timeout = 30 * time.Second
+}
+
+func TestCLIDefaults(t *testing.T) {
+	cmd := &defaultsTestCmd{}
+	c := New("test", cmd)
+	assert.Regexp(t, `--addr <VALUE> +\(default: localhost:8080\)`, c.HelpString())
+	assert.Regexp(t, `--timeout <VALUE> +\(default: 30s\)`, c.HelpString())
+
+	r := c.ParseArgs([]string{"--addr", ":9090"})
+	require.NoError(t, r.Err)
+	assert.Equal(t, &defaultsTestCmd{Addr: ":9090", Timeout: 30 * time.Second}, cmd)
+}
+
+type middlewareTestCmd struct {
+	Token string `cli:"secret"`
+	ran   bool
+}
+
+func (cmd *middlewareTestCmd) Run() error {
+	cmd.ran = true
+	return nil
+}
+
+func TestCLIMiddleware(t *testing.T) {
+	calls := []string{}
+	mw := func(name string) Middleware {
+		return func(ctx context.Context, cmd *Command, next func(context.Context) error) error {
+			calls = append(calls, name+" "+cmd.FullName())
+			assert.True(t, cmd.IsSecret("token"))
+			return next(ctx)
+		}
+	}
+
+	cli := NewCLI().Use(mw("a"), mw("b"))
+	cmd := &middlewareTestCmd{Token: "hunter2"}
+	c := cli.New("test", &struct{}{}, cli.New("sub", cmd))
+	assert.NotContains(t, c.commandMap["sub"].HelpString(), "hunter2")
+
+	err := c.ParseArgs([]string{"sub", "--token", "x"}).Run()
+	require.NoError(t, err)
+	assert.True(t, cmd.ran)
+	assert.Equal(t, []string{"a test sub", "b test sub"}, calls)
+}
+
+func TestCLIAnnotations(t *testing.T) {
+	c := New("test", &struct{}{},
+		New("admin", &struct{}{}, WithAnnotation("role", "admin")).
+			SetAnnotation("docs", "skip"),
+		New("user", &struct{}{}),
+	)
+
+	roles := map[string]string{}
+	for _, sub := range c.Commands() {
+		if role, ok := sub.Annotation("role"); ok {
+			roles[sub.FullName()] = role
+		}
+	}
+	assert.Equal(t, map[string]string{"test admin": "admin"}, roles)
+	assert.Equal(t, map[string]string{"role": "admin", "docs": "skip"}, c.Commands()[0].Annotations())
+	assert.Equal(t, map[string]string{}, c.Annotations())
+}
+
+type authorizeTestCmd struct {
+	before bool
+}
+
+func (cmd *authorizeTestCmd) Before() error {
+	cmd.before = true
+	return nil
+}
+
+func (cmd *authorizeTestCmd) Run() error {
+	return nil
+}
+
+func TestCLIAuthorize(t *testing.T) {
+	authorized := []string{}
+	cli := NewCLI()
+	cli.Authorize = func(cmd *Command) error {
+		if role, ok := cmd.Annotation("role"); ok && role == "admin" {
+			return fmt.Errorf("requires admin role")
+		}
+		authorized = append(authorized, cmd.FullName())
+		return nil
+	}
+
+	admin := &authorizeTestCmd{}
+	user := &authorizeTestCmd{}
+	c := cli.New("test", &struct{}{},
+		cli.New("admin", admin, WithAnnotation("role", "admin")),
+		cli.New("user", user),
+	)
+
+	require.NoError(t, c.ParseArgs([]string{"user"}).Err)
+	assert.True(t, user.before)
+	assert.Equal(t, []string{"test", "test user"}, authorized)
+
+	err := c.ParseArgs([]string{"admin"}).Err
+	require.Error(t, err)
+	assert.False(t, admin.before)
+	assert.Equal(t, "not authorized to run test admin: requires admin role", err.Error())
+	var authErr *AuthorizationError
+	require.ErrorAs(t, err, &authErr)
+	assert.Equal(t, 77, authErr.ExitCode())
+}
+
+type mountTestCmd struct {
+	URL string
+	ran bool
+}
+
+func (cmd *mountTestCmd) Run() error {
+	cmd.ran = true
+	return nil
+}
+
+func TestCLIMount(t *testing.T) {
+	otherCLI := NewCLI()
+	otherCLI.EnvPrefix = "DB_"
+	otherCLI.AutoEnv = true
+	migrate := &mountTestCmd{}
+	other := otherCLI.New("dbtool", &struct{}{},
+		otherCLI.New("migrate", migrate),
+	)
+
+	c := New("test", &struct{}{}).Mount("tools db", other)
+	assert.Contains(t, c.HelpString(), "tools")
+	assert.Contains(t, c.commandMap["tools"].HelpString(), "db")
+	assert.Contains(t, other.commandMap["migrate"].HelpString(), "test tools db migrate [OPTIONS]")
+	assert.Contains(t, other.commandMap["migrate"].HelpString(), "DB_URL")
+
+	err := c.ParseArgs([]string{"tools", "db", "migrate", "--url", "x"}).Run()
+	require.NoError(t, err)
+	assert.True(t, migrate.ran)
+	assert.Equal(t, "x", migrate.URL)
+
+	assert.Panics(t, func() {
+		c.Mount("tools db", New("again", &struct{}{}))
+	})
+}
+
+func TestCLIDisableEnv(t *testing.T) {
+	type Cmd struct {
+		Foo string `cli:"env=FOO"`
+	}
+	lookups := []string{}
+	cli := NewCLI()
+	cli.LookupEnv = func(key string) (string, bool, error) {
+		lookups = append(lookups, key)
+		return "env", true, nil
+	}
+
+	root := &Cmd{}
+	version := &Cmd{}
+	c := cli.New("test", root,
+		cli.New("version", version).DisableEnv(),
+	)
+	assert.NotContains(t, c.commandMap["version"].HelpString(), "FOO")
+
+	require.NoError(t, c.ParseArgs([]string{"version"}).Err)
+	assert.Equal(t, "env", root.Foo)
+	assert.Equal(t, "", version.Foo)
+	assert.Equal(t, []string{"FOO"}, lookups)
+
+	lookups = []string{}
+	cli.DisableEnv = true
+	root = &Cmd{}
+	require.NoError(t, cli.New("test", root).ParseArgs([]string{}).Err)
+	assert.Equal(t, "", root.Foo)
+	assert.Empty(t, lookups)
+
+	// Values are not expanded using LookupEnv either.
+	lookups = []string{}
+	cli.DisableEnv = false
+	cli.ExpandEnv = true
+	root = &Cmd{}
+	require.NoError(t, cli.New("test", root).DisableEnv().ParseArgs([]string{"--foo", "${BAR}"}).Err)
+	assert.Equal(t, "${BAR}", root.Foo)
+	assert.Empty(t, lookups)
+}
+
+func TestCLILookupEnvBatch(t *testing.T) {
+	type Cmd struct {
+		Foo    string `cli:"env=FOO"`
+		Bar    string `cli:"env=BAR"`
+		Baz    string `cli:"env=BAZ"`
+		Secret string `cli:"env=vault:secret"`
+	}
+	batches := [][]string{}
+	cli := NewCLI()
+	cli.LookupEnv = func(key string) (string, bool, error) {
+		t.Fatalf("LookupEnv should not be called (got %s)", key)
+		return "", false, nil
+	}
+	cli.LookupEnvBatch = func(keys []string) (map[string]string, error) {
+		batches = append(batches, keys)
+		return map[string]string{"FOO": "foo", "BAZ": "baz"}, nil
+	}
+	cli.RegisterSecretResolver("vault", func(ref string) (string, bool, error) {
+		return "resolved " + ref, true, nil
+	})
+
+	cmd := &Cmd{}
+	r := cli.New("test", cmd).ParseArgs([]string{"--bar", "arg"})
+	require.NoError(t, r.Err)
+	assert.Equal(t, [][]string{{"FOO", "BAZ"}}, batches)
+	assert.Equal(t, &Cmd{Foo: "foo", Bar: "arg", Baz: "baz", Secret: "resolved secret"}, cmd)
+}
+
+func TestCLILookupEnvBatchOverride(t *testing.T) {
+	type Cmd struct {
+		Foo string `cli:"env=FOO"`
+	}
+	cli := NewCLI()
+	cli.LookupEnvBatch = func(keys []string) (map[string]string, error) {
+		return map[string]string{"FOO": "from-batch"}, nil
+	}
+
+	cmd := &Cmd{}
+	r := cli.New("test", cmd, WithLookupEnv(func(key string) (string, bool, error) {
+		return "from-override", true, nil
+	})).ParseArgs([]string{})
+	require.NoError(t, r.Err)
+	assert.Equal(t, "from-override", cmd.Foo)
+
+	cmd = &Cmd{}
+	r = cli.New("test", cmd).ParseArgs([]string{})
+	require.NoError(t, r.Err)
+	assert.Equal(t, "from-batch", cmd.Foo)
+}
+
+type flagSequenceConfig struct {
+	Env     []string `cli:"short=e,append"`
+	Volume  []string `cli:"short=v,append"`
+	Verbose bool
+
+	cmd *Command
+}
+
+func (c *flagSequenceConfig) SetupCommand(cmd *Command) {
+	c.cmd = cmd
+}
+
+func TestFlagSequence(t *testing.T) {
+	config := &flagSequenceConfig{}
+	r := New("test", config).ParseArgs([]string{"-e", "A=1", "--volume=/a:/b", "--verbose", "-e", "B=2"})
+	require.NoError(t, r.Err)
+	assert.Equal(t, []string{"A=1", "B=2"}, config.Env)
+	assert.Equal(t, []FlagValue{
+		{Name: "env", Value: "A=1"},
+		{Name: "volume", Value: "/a:/b"},
+		{Name: "verbose", Value: "true"},
+		{Name: "env", Value: "B=2"},
+	}, config.cmd.FlagSequence())
+}
@@ -0,0 +1,44 @@
+// Package clitest provides helpers for testing and benchmarking commands
+// built with the cli package.
+package clitest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/isobit/cli"
+)
+
+// BenchmarkCommand measures the time and allocations taken to build the
+// command tree returned by build and parse args with it, which together make
+// up most of a CLI's startup time. Build and parse times are also reported
+// separately, as the build-ns/op and parse-ns/op metrics.
+//
+//	func BenchmarkStartup(b *testing.B) {
+//		clitest.BenchmarkCommand(b, newRootCommand, []string{"sub", "--flag"})
+//	}
+//
+// The benchmark fails if parsing returns an error (other than cli.ErrHelp).
+func BenchmarkCommand(b *testing.B, build func() *cli.Command, args []string) {
+	b.Helper()
+	b.ReportAllocs()
+
+	var buildTime, parseTime time.Duration
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		start := time.Now()
+		cmd := build()
+		built := time.Now()
+		r := cmd.ParseArgs(args)
+		parseTime += time.Since(built)
+		buildTime += built.Sub(start)
+
+		if r.Err != nil && r.Err != cli.ErrHelp {
+			b.Fatalf("failed to parse args %q: %s", args, r.Err)
+		}
+	}
+	b.StopTimer()
+
+	b.ReportMetric(float64(buildTime.Nanoseconds())/float64(b.N), "build-ns/op")
+	b.ReportMetric(float64(parseTime.Nanoseconds())/float64(b.N), "parse-ns/op")
+}
@@ -0,0 +1,38 @@
+package clitest
+
+import (
+	"testing"
+
+	"github.com/isobit/cli"
+	"github.com/stretchr/testify/assert"
+)
+
+type testRoot struct {
+	Verbose bool `cli:"short=v"`
+}
+
+type testSub struct {
+	Name  string
+	Count int
+}
+
+func (*testSub) Run() error {
+	return nil
+}
+
+func newTestCommand() *cli.Command {
+	return cli.New("test", &testRoot{},
+		cli.New("sub", &testSub{}),
+	)
+}
+
+func BenchmarkTestCommand(b *testing.B) {
+	BenchmarkCommand(b, newTestCommand, []string{"-v", "sub", "--name", "foo", "--count", "3"})
+}
+
+func TestBenchmarkCommand(t *testing.T) {
+	result := testing.Benchmark(BenchmarkTestCommand)
+	assert.NotZero(t, result.N)
+	assert.Contains(t, result.Extra, "build-ns/op")
+	assert.Contains(t, result.Extra, "parse-ns/op")
+}
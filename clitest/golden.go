@@ -0,0 +1,86 @@
+package clitest
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/isobit/cli"
+)
+
+// UpdateEnvVar is the environment variable which, if set to a non-empty
+// value, causes golden file helpers such as AssertHelpGolden to write the
+// golden file instead of comparing against it:
+//
+//	CLITEST_UPDATE=1 go test ./...
+const UpdateEnvVar = "CLITEST_UPDATE"
+
+// AssertHelpGolden checks that the help text of cmd matches the contents of
+// the golden file at path (conventionally under testdata), failing the test
+// with the first differing line if not. If the CLITEST_UPDATE environment
+// variable is set, the golden file (and any missing parent directories) is
+// written instead, so that intended changes to help output can be accepted
+// and reviewed in version control:
+//
+//	func TestHelp(t *testing.T) {
+//		clitest.AssertHelpGolden(t, newRootCommand(), "testdata/help.golden")
+//	}
+func AssertHelpGolden(t testing.TB, cmd *cli.Command, path string) {
+	t.Helper()
+	assertGolden(t, cmd.HelpString(), path)
+}
+
+func assertGolden(t testing.TB, got string, path string) {
+	t.Helper()
+
+	if os.Getenv(UpdateEnvVar) != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create golden file directory: %s", err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatalf("failed to write golden file: %s", err)
+		}
+		return
+	}
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("golden file %s does not exist (run with %s=1 to create it)", path, UpdateEnvVar)
+	}
+	if err != nil {
+		t.Fatalf("failed to read golden file: %s", err)
+	}
+	want := string(b)
+	if got == want {
+		return
+	}
+
+	line, wantLine, gotLine := firstDiff(want, got)
+	t.Errorf(
+		"output does not match golden file %s (run with %s=1 to update it)\nfirst difference at line %d:\n  want: %q\n   got: %q\nfull output:\n%s",
+		path, UpdateEnvVar, line, wantLine, gotLine, got,
+	)
+}
+
+// firstDiff returns the 1-based number of the first line which differs
+// between want and got, along with the differing lines. A line which is
+// missing from one side is returned as "<EOF>".
+func firstDiff(want, got string) (int, string, string) {
+	wantLines := strings.Split(want, "\n")
+	gotLines := strings.Split(got, "\n")
+	for i := 0; ; i++ {
+		wantLine, gotLine := "<EOF>", "<EOF>"
+		if i < len(wantLines) {
+			wantLine = wantLines[i]
+		}
+		if i < len(gotLines) {
+			gotLine = gotLines[i]
+		}
+		if wantLine != gotLine {
+			return i + 1, wantLine, gotLine
+		}
+	}
+}
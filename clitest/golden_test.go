@@ -0,0 +1,85 @@
+package clitest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingTB is a testing.TB which records failures instead of failing the
+// test.
+type recordingTB struct {
+	testing.TB
+	failed bool
+	msg    string
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) Errorf(format string, args ...interface{}) {
+	r.failed = true
+	r.msg = fmt.Sprintf(format, args...)
+}
+
+func (r *recordingTB) Fatalf(format string, args ...interface{}) {
+	r.Errorf(format, args...)
+	runtime.Goexit()
+}
+
+// record runs fn with a recordingTB in a separate goroutine, so that Fatalf
+// can stop it.
+func record(t *testing.T, fn func(tb testing.TB)) *recordingTB {
+	r := &recordingTB{TB: t}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn(r)
+	}()
+	<-done
+	return r
+}
+
+func TestAssertHelpGolden(t *testing.T) {
+	AssertHelpGolden(t, newTestCommand(), "testdata/help.golden")
+}
+
+func TestAssertHelpGoldenMismatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "help.golden")
+	require.NoError(t, os.WriteFile(path, []byte("USAGE:\n    old\n"), 0o644))
+
+	r := record(t, func(tb testing.TB) {
+		AssertHelpGolden(tb, newTestCommand(), path)
+	})
+	assert.True(t, r.failed)
+	assert.Contains(t, r.msg, "first difference at line 2")
+	assert.Contains(t, r.msg, `want: "    old"`)
+	assert.Contains(t, r.msg, `got: "    test [OPTIONS] <COMMAND>"`)
+}
+
+func TestAssertHelpGoldenMissing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "help.golden")
+	r := record(t, func(tb testing.TB) {
+		AssertHelpGolden(tb, newTestCommand(), path)
+	})
+	assert.True(t, r.failed)
+	assert.Contains(t, r.msg, "does not exist (run with CLITEST_UPDATE=1 to create it)")
+}
+
+func TestAssertHelpGoldenUpdate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "testdata", "help.golden")
+	t.Setenv(UpdateEnvVar, "1")
+
+	r := record(t, func(tb testing.TB) {
+		AssertHelpGolden(tb, newTestCommand(), path)
+	})
+	assert.False(t, r.failed)
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, newTestCommand().HelpString(), string(b))
+}
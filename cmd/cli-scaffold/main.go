@@ -0,0 +1,45 @@
+// Command cli-scaffold generates the skeleton of a new CLI project which uses
+// github.com/isobit/cli (see the scaffold package).
+package main
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	"github.com/isobit/cli"
+	"github.com/isobit/cli/scaffold"
+)
+
+type Scaffold struct {
+	Module   string   `cli:"required,short=m,placeholder=PATH,help=Go module path of the new project (e.g. example.com/mytool)"`
+	Name     string   `cli:"help=name of the CLI (the last element of the module path if not set)"`
+	Commands []string `cli:"append,short=c,placeholder=NAME,help=subcommand to generate a package for (can be repeated)"`
+	Dir      string   `cli:"short=o,placeholder=DIR,help=directory to write the project to (./NAME if not set)"`
+}
+
+func (s *Scaffold) Run(ctx context.Context) error {
+	dir := s.Dir
+	if dir == "" {
+		dir = s.Name
+		if dir == "" {
+			dir = path.Base(s.Module)
+		}
+	}
+	err := scaffold.Generate(dir, scaffold.Options{
+		Module:   s.Module,
+		Name:     s.Name,
+		Commands: s.Commands,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cli.Stdout(ctx), "Created %s; run \"go mod tidy\" in it to add dependencies.\n", dir)
+	return nil
+}
+
+func main() {
+	cli.New("cli-scaffold", &Scaffold{}, cli.WithHelp("generate a new CLI project")).
+		Parse().
+		RunFatal()
+}
@@ -2,10 +2,12 @@ package cli
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
-	"os/signal"
-	"syscall"
+	"runtime/debug"
+	"strings"
+	"time"
 )
 
 type Runner interface {
@@ -20,6 +22,13 @@ type Beforer interface {
 	Before() error
 }
 
+// Defaulter can be implemented by configs to set default values. Defaults is
+// called by Build before fields are read, so defaults set by it are shown in
+// help text.
+type Defaulter interface {
+	Defaults()
+}
+
 type Setuper interface {
 	SetupCommand(cmd *Command)
 }
@@ -33,14 +42,32 @@ type Command struct {
 	name          string
 	help          string
 	description   string
+	hidden        bool
+	experimental  bool
+	disableEnv    bool
+	annotations   map[string]string
 	config        interface{}
+	runFunc       *runFunc
 	helpRequested bool
+	helpTopic     string
+	helpTopics    []HelpTopic
+	helpFilter    string
 	fields        []field
 	fieldMap      map[string]field
 	argsField     *argsField
 	parent        *Command
 	commands      []*Command
 	commandMap    map[string]*Command
+	ownsCLI       bool
+	parsedArgs    []string
+	flagSequence  []FlagValue
+	snapshot      *ConfigSnapshot
+	configSources []ValueSource
+	timings       commandTimings
+	profiling     profilingOptions
+	helpCache     helpCache
+
+	assignedShortNames map[string]string
 }
 
 func (cli *CLI) New(name string, config interface{}, opts ...CommandOption) *Command {
@@ -55,6 +82,9 @@ func (cli *CLI) Build(name string, config interface{}, opts ...CommandOption) (*
 	if config == nil {
 		config = &struct{}{}
 	}
+	if defaulter, ok := config.(Defaulter); ok {
+		defaulter.Defaults()
+	}
 	cmd := &Command{
 		cli:        cli,
 		name:       name,
@@ -65,34 +95,50 @@ func (cli *CLI) Build(name string, config interface{}, opts ...CommandOption) (*
 		commandMap: map[string]*Command{},
 	}
 
-	configFields, argsField, err := cli.getFieldsFromConfig(config)
+	// Apply any CLI overrides first, since they may affect how fields are
+	// built.
+	for _, opt := range opts {
+		if o, ok := opt.(cliOverrideOption); ok {
+			o(cmd.overrideCLI())
+		}
+	}
+
+	// Keep a snapshot of the initial config so that a fresh copy can be
+	// parsed when the watched config file changes.
+	if cmd.cli.configWatch != nil {
+		snapshot, err := SnapshotConfig(config)
+		if err != nil {
+			return nil, err
+		}
+		cmd.snapshot = snapshot
+	}
+
+	configFields, argsField, err := cmd.cli.getFieldsFromConfig(config)
 	if err != nil {
 		return nil, err
 	}
 	cmd.argsField = argsField
+	if argsField != nil {
+		for _, p := range argsField.positionals {
+			p.value.onSet = cmd.invalidateHelpCache
+		}
+	}
 	for _, f := range configFields {
 		if err := cmd.addField(f, false); err != nil {
 			return nil, err
 		}
 	}
 
-	if _, ok := cmd.fieldMap["help"]; !ok {
-		helpField := field{
-			Name:   "help",
-			Help:   "show usage help",
-			HasArg: false,
-			value: &fieldValue{
-				Setter:     &scanfSetter{&cmd.helpRequested},
-				stringer:   staticStringer(""),
-				isBoolFlag: true,
-			},
-		}
-		if _, ok := cmd.fieldMap["h"]; !ok {
-			helpField.ShortName = "h"
-		}
-		if err := cmd.addField(helpField, true); err != nil {
-			return nil, err
-		}
+	if err := cmd.addHelpField(); err != nil {
+		return nil, err
+	}
+
+	if err := cmd.addProfilingFields(); err != nil {
+		return nil, err
+	}
+
+	if cmd.cli.AutoShortNames {
+		cmd.assignShortNames()
 	}
 
 	if setuper, ok := cmd.config.(Setuper); ok {
@@ -107,6 +153,7 @@ func (cli *CLI) Build(name string, config interface{}, opts ...CommandOption) (*
 }
 
 func (cmd *Command) addField(f field, prepend bool) error {
+	f.value.onSet = cmd.invalidateHelpCache
 	if prepend {
 		cmd.fields = append([]field{f}, cmd.fields...)
 	} else {
@@ -125,19 +172,231 @@ func (cmd *Command) addField(f field, prepend bool) error {
 		cmd.fieldMap[f.ShortName] = f
 	}
 
+	for _, alias := range f.allAliases() {
+		if _, ok := cmd.fieldMap[alias]; ok {
+			return fmt.Errorf("multiple fields defined for name: %s", alias)
+		}
+		cmd.fieldMap[alias] = f
+	}
+
+	if f.Negatable {
+		if _, ok := cmd.fieldMap[f.negatedName()]; ok {
+			return fmt.Errorf("multiple fields defined for name: %s", f.negatedName())
+		}
+		cmd.fieldMap[f.negatedName()] = f
+	}
+
 	return nil
 }
 
+// addHelpField adds the help flag according to the CLI's help flag settings,
+// unless it is disabled or a field with the same name already exists. The help
+// flag short name is only added if it is not already in use.
+func (cmd *Command) addHelpField() error {
+	if cmd.cli.DisableHelpFlag {
+		return nil
+	}
+	name := cmd.cli.HelpFlagName
+	if name == "" {
+		name = "help"
+	}
+	if _, ok := cmd.fieldMap[name]; ok {
+		return nil
+	}
+	helpField := field{
+		Name:   name,
+		Help:   "show usage help",
+		HasArg: false,
+		value: &fieldValue{
+			Setter:     helpSetter{cmd},
+			stringer:   staticStringer(""),
+			isBoolFlag: true,
+		},
+	}
+	shortName := cmd.cli.HelpFlagShortName
+	if shortName == "" {
+		shortName = "h"
+	}
+	if _, ok := cmd.fieldMap[shortName]; !ok && !cmd.cli.DisableHelpFlagShortName {
+		helpField.ShortName = shortName
+	}
+	return cmd.addField(helpField, true)
+}
+
+// assignShortNames assigns short names to fields which do not have one, using
+// the first letter of the field name, so long as that letter is not already
+// in use and is not the first letter of any other field without a short name.
+// Hidden fields are not assigned short names.
+func (cmd *Command) assignShortNames() {
+	candidates := map[string]int{}
+	for _, f := range cmd.fields {
+		if f.ShortName == "" && !f.Hidden {
+			if short, ok := shortNameCandidate(f.Name); ok {
+				candidates[short] += 1
+			}
+		}
+	}
+
+	cmd.assignedShortNames = map[string]string{}
+	for i, f := range cmd.fields {
+		if f.ShortName != "" || f.Hidden {
+			continue
+		}
+		short, ok := shortNameCandidate(f.Name)
+		if !ok || candidates[short] > 1 {
+			continue
+		}
+		if _, ok := cmd.fieldMap[short]; ok {
+			continue
+		}
+		f.ShortName = short
+		cmd.fields[i] = f
+		cmd.fieldMap[f.Name] = f
+		cmd.fieldMap[short] = f
+		for _, alias := range f.allAliases() {
+			cmd.fieldMap[alias] = f
+		}
+		if f.Negatable {
+			cmd.fieldMap[f.negatedName()] = f
+		}
+		cmd.assignedShortNames[f.Name] = short
+	}
+}
+
+func shortNameCandidate(name string) (string, bool) {
+	if len(name) == 0 {
+		return "", false
+	}
+	c := name[0]
+	if (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') {
+		return string(c), true
+	}
+	return "", false
+}
+
+// AssignedShortNames returns a map of field names to the short names which
+// were automatically assigned to them because CLI.AutoShortNames was enabled.
+func (cmd *Command) AssignedShortNames() map[string]string {
+	return cmd.assignedShortNames
+}
+
+// overrideCLI replaces the Command's CLI with a copy, if it has not been
+// already, so that settings can be overridden for this Command without
+// affecting other Commands constructed by the same CLI.
+func (cmd *Command) overrideCLI() *CLI {
+	if !cmd.ownsCLI {
+		c := *cmd.cli
+		cmd.cli = &c
+		cmd.ownsCLI = true
+	}
+	return cmd.cli
+}
+
+// SetLookupEnv overrides the CLI's LookupEnv for this Command only, so that it
+// can consult a different environment namespace or secrets provider. The
+// CLI's LookupEnvBatch, if any, is not used for this Command, since it would
+// otherwise take precedence. Subcommands are not affected.
+func (cmd *Command) SetLookupEnv(lookupEnv LookupEnvFunc) *Command {
+	c := cmd.overrideCLI()
+	c.LookupEnv = lookupEnv
+	c.LookupEnvBatch = nil
+	return cmd
+}
+
 func (cmd *Command) SetHelp(help string) *Command {
+	cmd.invalidateParentHelpCache()
 	cmd.help = help
 	return cmd
 }
 
 func (cmd *Command) SetDescription(description string) *Command {
+	cmd.invalidateHelpCache()
 	cmd.description = description
 	return cmd
 }
 
+// SetHidden sets whether the Command is hidden from its parent's help text.
+// Hidden commands can still be run.
+func (cmd *Command) SetHidden(hidden bool) *Command {
+	cmd.invalidateParentHelpCache()
+	cmd.hidden = hidden
+	return cmd
+}
+
+// DisableEnv disables environment variable parsing for the Command, so that
+// LookupEnv is never called for its fields (and "${VAR}" sequences are not
+// expanded, even if CLI.ExpandEnv is enabled). This is useful for commands
+// such as "version" or "completion" which should be fast and deterministic.
+// See also CLI.DisableEnv.
+func (cmd *Command) DisableEnv() *Command {
+	cmd.invalidateHelpCache()
+	cmd.disableEnv = true
+	return cmd
+}
+
+// envDisabled returns true if environment variable parsing is disabled for
+// the Command, or for its CLI.
+func (cmd *Command) envDisabled() bool {
+	return cmd.disableEnv || cmd.cli.DisableEnv
+}
+
+// SetExperimental sets whether the Command is experimental. Experimental
+// commands are hidden from help text and can't be run unless experimental
+// features are enabled (see CLI.ExperimentalEnv and ExperimentalOptions).
+func (cmd *Command) SetExperimental(experimental bool) *Command {
+	cmd.invalidateParentHelpCache()
+	cmd.experimental = experimental
+	return cmd
+}
+
+// SetAnnotation sets an arbitrary key-value pair of metadata on the Command,
+// which can be used by higher-level frameworks (e.g. doc generators or
+// permission systems) and retrieved with Annotation.
+func (cmd *Command) SetAnnotation(key string, value string) *Command {
+	if cmd.annotations == nil {
+		cmd.annotations = map[string]string{}
+	}
+	cmd.annotations[key] = value
+	return cmd
+}
+
+// Annotation returns the value of the annotation with the given key, and
+// whether it was set.
+func (cmd *Command) Annotation(key string) (string, bool) {
+	value, ok := cmd.annotations[key]
+	return value, ok
+}
+
+// Annotations returns a copy of all of the Command's annotations.
+func (cmd *Command) Annotations() map[string]string {
+	annotations := make(map[string]string, len(cmd.annotations))
+	for k, v := range cmd.annotations {
+		annotations[k] = v
+	}
+	return annotations
+}
+
+// Commands returns the Command's subcommands, in the order they were added,
+// so that the command tree can be walked.
+func (cmd *Command) Commands() []*Command {
+	commands := make([]*Command, len(cmd.commands))
+	copy(commands, cmd.commands)
+	return commands
+}
+
+// AddHelpTopic registers a help topic, which is a page of prose documentation
+// that can be shown using "help <name>" (or "--help <name>"), similar to git's
+// help topics. Topics are listed in the Command's help text.
+func (cmd *Command) AddHelpTopic(name string, title string, text string) *Command {
+	cmd.invalidateHelpCache()
+	cmd.helpTopics = append(cmd.helpTopics, HelpTopic{
+		Name:  name,
+		Title: title,
+		Text:  text,
+	})
+	return cmd
+}
+
 // AddCommand registers another Command instance as a subcommand of this Command
 // instance.
 func (cmd *Command) AddCommand(subCmd *Command) *Command {
@@ -148,6 +407,37 @@ func (cmd *Command) AddCommand(subCmd *Command) *Command {
 	subCmd.parent = cmd
 	cmd.commands = append(cmd.commands, subCmd)
 	cmd.commandMap[subCmd.name] = subCmd
+	cmd.invalidateHelpCache()
+	subCmd.invalidateHelpCacheTree()
+	return cmd
+}
+
+// Mount grafts another Command tree under this Command at prefix, which is a
+// list of subcommand names separated by spaces (e.g. "tools db"). Any
+// intermediate commands which don't exist yet are created, and the other
+// Command is renamed to the last name in prefix. This allows composing CLIs
+// from independently built command trees, which keep their own CLI settings.
+func (cmd *Command) Mount(prefix string, other *Command) *Command {
+	names := strings.Fields(prefix)
+	if len(names) == 0 {
+		// TODO return error
+		panic("cli: mount prefix must not be empty")
+	}
+	parent := cmd
+	for _, name := range names[:len(names)-1] {
+		next, ok := parent.commandMap[name]
+		if !ok {
+			next = parent.cli.New(name, &struct{}{})
+			parent.AddCommand(next)
+		}
+		parent = next
+	}
+	name := names[len(names)-1]
+	if _, ok := parent.commandMap[name]; ok {
+		panic(fmt.Sprintf("cli: cannot mount at %q, command already exists", prefix))
+	}
+	other.name = name
+	parent.AddCommand(other)
 	return cmd
 }
 
@@ -169,53 +459,126 @@ func (cmd *Command) Parse() ParseResult {
 //
 // If a Before method is implemented on the config, this method will call it
 // before calling Run or recursing into any subcommand parsing.
+//
+// If CLI.ResponseFiles is enabled, any "@file" arguments are first replaced by
+// the arguments read from that file. Then, if CLI.ArgPreprocessor is set, the
+// args are replaced by its result.
 func (cmd *Command) ParseArgs(args []string) ParseResult {
 	if args == nil {
 		args = []string{}
 	}
 
+	if cmd.cli.ResponseFiles {
+		expanded, err := expandResponseFiles(args)
+		if err != nil {
+			return ParseResult{Command: cmd, Err: err}
+		}
+		args = expanded
+	}
+
+	if cmd.cli.WarnUnknownEnv && cmd.cli.EnvPrefix != "" {
+		cmd.warnUnknownEnvVars(os.Environ())
+	}
+
+	if cmd.cli.ArgPreprocessor != nil {
+		processed, err := cmd.cli.ArgPreprocessor(args)
+		if err != nil {
+			return ParseResult{Command: cmd, Err: err}
+		}
+		args = processed
+	}
+
+	return cmd.parseArgs(args)
+}
+
+func (cmd *Command) parseArgs(args []string) ParseResult {
 	r := ParseResult{Command: cmd}
+	cmd.parsedArgs = args
+	cmd.flagSequence = nil
 
-	p := parser{fields: cmd.fieldMap, args: args}
+	p := parser{
+		fields:     cmd.fieldMap,
+		args:       args,
+		slashFlags: cmd.cli.SlashFlags,
+		setValue:   cmd.setArgFieldValue,
+	}
 
 	// Parse arguments using the flagset.
 	if err := p.parse(args); err != nil {
 		return r.err(UsageErrorf("failed to parse args: %w", err))
 	}
 
+	// Expand user-defined command aliases, then continue parsing in case the
+	// expansion starts with flags.
+	if cmd.parent == nil && len(cmd.commandMap) > 0 && len(p.args) > 0 {
+		expanded, ok, err := cmd.expandCommandAlias(p.args)
+		if err != nil {
+			return r.err(err)
+		}
+		if ok {
+			if err := p.parse(expanded); err != nil {
+				return r.err(UsageErrorf("failed to parse args: %w", err))
+			}
+		}
+	}
+
+	// Return an error if experimental flags were passed without experimental
+	// features being enabled.
+	if err := cmd.checkExperimentalFields(); err != nil {
+		return r.err(UsageError(err))
+	}
+
 	// Return ErrHelp if help was requested.
+	// A help topic can also be passed as the next argument, e.g.
+	// "--help timeout".
 	if cmd.helpRequested {
+		if cmd.helpTopic == "" && len(p.args) > 0 && cmd.isHelpTopic(p.args[0]) {
+			cmd.helpTopic = p.args[0]
+		}
+		if cmd.helpTopic != "" && !cmd.isHelpTopic(cmd.helpTopic) {
+			return r.err(UsageErrorf("unknown help topic: %s", cmd.helpTopic))
+		}
 		return r.err(ErrHelp)
 	}
 
 	// Help command
 	if cmd.parent == nil && cmd.argsField == nil && len(p.args) > 0 && p.args[0] == "help" {
+		helpArgs, filter, err := parseHelpCommandArgs(p.args[1:])
+		if err != nil {
+			return r.err(err)
+		}
 		curCmd := cmd
-		for i := 1; i < len(p.args); i++ {
-			cmdName := p.args[i]
+		for i, cmdName := range helpArgs {
 			if subCmd, ok := curCmd.commandMap[cmdName]; ok {
 				curCmd = subCmd
+			} else if i == len(helpArgs)-1 && curCmd.isHelpTopic(cmdName) {
+				curCmd.helpTopic = cmdName
 			} else {
-				return r.err(UsageErrorf("unknown command: %s", cmdName))
+				return r.err(curCmd.unknownCommandError(cmdName))
 			}
 		}
+		curCmd.helpFilter = filter
+		curCmd.invalidateHelpCache()
 		return ParseResult{Command: curCmd, Err: ErrHelp}
 	}
 
 	// Handle remaining arguments so we get unknown command errors before
 	// invoking Before.
 	var subCmd *Command
-	if len(p.args) > 0 {
+	if cmd.argsField != nil {
+		if err := cmd.argsField.set(p.args, cmd.setFieldValue); err != nil {
+			return r.err(UsageError(err))
+		}
+	} else if len(p.args) > 0 {
 		switch {
-		case cmd.argsField != nil:
-			cmd.argsField.setter(p.args)
-
 		case len(cmd.commandMap) > 0:
 			cmdName := p.args[0]
-			if cmd, ok := cmd.commandMap[cmdName]; ok {
-				subCmd = cmd
+			if c, ok := cmd.commandMap[cmdName]; ok && !cmd.commandAvailable(c) {
+				return r.err(UsageErrorf("command %s is experimental%s", cmdName, cmd.experimentalHint()))
+			} else if ok {
+				subCmd = c
 			} else {
-				return r.err(UsageErrorf("unknown command: %s", cmdName))
+				return r.err(cmd.unknownCommandError(cmdName))
 			}
 
 		default:
@@ -223,30 +586,38 @@ func (cmd *Command) ParseArgs(args []string) ParseResult {
 		}
 	}
 
-	// Parse environment variables.
-	if err := cmd.parseEnvVars(); err != nil {
-		return r.err(UsageErrorf("failed to parse environment variables: %w", err))
+	// Parse environment variables, config files, and other value sources.
+	if err := cmd.parseNonArgSources(); err != nil {
+		return r.err(UsageError(err))
 	}
 
-	// Return an error if any required fields were not set at least once.
-	if err := cmd.checkRequired(); err != nil {
-		return r.err(UsageError(err))
+	// Check that the command is authorized to run before calling Before.
+	if cmd.cli.Authorize != nil {
+		if err := cmd.cli.Authorize(cmd); err != nil {
+			return r.err(&AuthorizationError{Command: cmd.fullName(), Err: err})
+		}
 	}
 
 	// If the config implements a Before method, run it before we recursively
 	// parse subcommands.
 	if beforer, ok := cmd.config.(Beforer); ok {
-		if err := beforer.Before(); err != nil {
+		start := time.Now()
+		err := beforer.Before()
+		cmd.timings.before = time.Since(start)
+		if err != nil {
 			return r.err(err)
 		}
 	}
 
 	// Recursive to subcommand parsing, if applicable.
 	if subCmd != nil {
-		return subCmd.ParseArgs(p.args[1:])
+		return subCmd.parseArgs(p.args[1:])
 	}
 
-	r.runFunc = getRunFunc(cmd.config)
+	r.runFunc = cmd.runFunc
+	if r.runFunc == nil {
+		r.runFunc = getRunFunc(cmd.config)
+	}
 	if r.runFunc == nil && len(cmd.commands) != 0 {
 		return r.err(UsageErrorf("no command specified"))
 	}
@@ -254,6 +625,24 @@ func (cmd *Command) ParseArgs(args []string) ParseResult {
 	return r
 }
 
+// parseNonArgSources sets fields which weren't set by arguments from
+// environment variables, env files, and other value sources (e.g. config
+// files), in that order of precedence, and then returns an error if any
+// required fields were not set at least once. It is shared by parseArgs and
+// reloadConfig so that reloaded configs are parsed the same way.
+func (cmd *Command) parseNonArgSources() error {
+	if err := cmd.parseEnvVars(); err != nil {
+		return fmt.Errorf("failed to parse environment variables: %w", err)
+	}
+	if err := cmd.parseEnvFiles(); err != nil {
+		return fmt.Errorf("failed to parse environment files: %w", err)
+	}
+	if err := cmd.parseValueSources(); err != nil {
+		return err
+	}
+	return cmd.checkRequired()
+}
+
 type runFunc struct {
 	run             func(context.Context) error
 	supportsContext bool
@@ -278,37 +667,226 @@ func getRunFunc(config interface{}) *runFunc {
 	return nil
 }
 
+// setFieldValue sets the value of a field from any source, after applying
+// any value processing configured on the CLI.
+func (cmd *Command) setFieldValue(f field, s string) error {
+	if cmd.cli.ExpandEnv && !cmd.envDisabled() {
+		expanded, err := expandEnv(s, cmd.cli.LookupEnv)
+		if err != nil {
+			return err
+		}
+		s = expanded
+	}
+	if cmd.cli.Transform != nil {
+		transformed, err := cmd.cli.Transform(f.value.setterContext(), s)
+		if err != nil {
+			return err
+		}
+		s = transformed
+	}
+	return f.value.Set(s)
+}
+
+// setArgFieldValue is like setFieldValue, but it also records the flag and
+// value in the command's flag sequence.
+func (cmd *Command) setArgFieldValue(f field, s string) error {
+	if err := cmd.setFieldValue(f, s); err != nil {
+		return err
+	}
+	cmd.flagSequence = append(cmd.flagSequence, FlagValue{Name: f.Name, Value: s})
+	return nil
+}
+
+// FlagValue is a flag name and value which was passed as an argument.
+type FlagValue struct {
+	Name  string
+	Value string
+}
+
+// FlagSequence returns the flags which were passed as arguments to this
+// command (not including flags passed to its parents or subcommands), in the
+// order they were passed, using the flags' canonical names. This is useful
+// when the relative order of different repeated flags matters, e.g.
+// "-e KEY=V -v /a:/b -e ...". The config can get its Command by implementing
+// Setuper, or the Command of a ParseResult can be used.
+func (cmd *Command) FlagSequence() []FlagValue {
+	seq := make([]FlagValue, len(cmd.flagSequence))
+	copy(seq, cmd.flagSequence)
+	return seq
+}
+
 // parseEnvVars sets any unset field values using the environment variable
 // matching the "env" tag of the field, if present.
 func (cmd *Command) parseEnvVars() error {
+	if cmd.envDisabled() {
+		return nil
+	}
+
+	fields := []field{}
 	for _, f := range cmd.fields {
-		if f.EnvVarName == "" || f.value.setCount > 0 {
+		if f.EnvVarName == "" || f.value.setCount > 0 || !cmd.fieldAvailable(f) {
 			continue
 		}
-		val, ok, err := cmd.cli.LookupEnv(f.EnvVarName)
+		fields = append(fields, f)
+	}
+
+	// If the CLI supports batched lookups, look up all of the keys at once
+	// (except for secret references, which are resolved separately).
+	var batch map[string]string
+	if cmd.cli.LookupEnvBatch != nil {
+		keys := []string{}
+		for _, f := range fields {
+			if !isSecretRef(f.EnvVarName) {
+				keys = append(keys, f.EnvVarName)
+			}
+		}
+		if len(keys) > 0 {
+			var err error
+			batch, err = cmd.cli.LookupEnvBatch(keys)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, f := range fields {
+		var val string
+		var ok bool
+		var err error
+		if cmd.cli.LookupEnvBatch != nil && !isSecretRef(f.EnvVarName) {
+			val, ok = batch[f.EnvVarName]
+		} else {
+			val, ok, err = cmd.lookupEnv(f.EnvVarName)
+		}
 		if err != nil {
 			// TODO?
 			return err
 		}
+		if ok && val == "" && f.EnvNonEmpty {
+			return fmt.Errorf("%s is set but empty", f.EnvVarName)
+		}
 		if ok {
-			if err := f.value.Set(val); err != nil {
-				return fmt.Errorf("error parsing %s: %w", f.EnvVarName, err)
+			if err := cmd.setFieldValue(f, val); err != nil {
+				return &FieldError{
+					Name: f.Name,
+					Err:  fmt.Errorf("error parsing %s: %w", f.EnvVarName, err),
+				}
 			}
 		}
 	}
 	return nil
 }
 
+// lookupEnv looks up the value for a field's env key. Keys of the form
+// "scheme:ref" are resolved using the SecretResolver registered for the
+// scheme, and all other keys are looked up using LookupEnv.
+func (cmd *Command) lookupEnv(key string) (string, bool, error) {
+	if i := strings.IndexByte(key, ':'); i > 0 {
+		scheme, ref := key[:i], key[i+1:]
+		resolver, ok := cmd.cli.SecretResolvers[scheme]
+		if !ok {
+			return "", false, fmt.Errorf("no secret resolver registered for scheme: %s", scheme)
+		}
+		val, ok, err := resolver(ref)
+		if err != nil {
+			return "", false, fmt.Errorf("error resolving %s: %w", key, err)
+		}
+		return val, ok, nil
+	}
+	return cmd.cli.LookupEnv(key)
+}
+
+// isSecretRef returns true if key is of the form "scheme:ref".
+func isSecretRef(key string) bool {
+	return strings.IndexByte(key, ':') > 0
+}
+
 // checkRequired returns an error if any fields are required but have not been set.
 func (cmd *Command) checkRequired() error {
 	for _, f := range cmd.fields {
-		if f.Required && f.value.setCount < 1 {
+		if f.Required && cmd.fieldAvailable(f) && f.value.setCount < 1 {
 			return fmt.Errorf("required flag %s not set", f.Name)
 		}
 	}
 	return nil
 }
 
+// IsSet returns true if the field with the given name (or short name) was set
+// at least once during parsing, either by argument or environment variable.
+// This can be used to distinguish between a field which was explicitly set to
+// its zero value and one which was not set at all.
+func (cmd *Command) IsSet(name string) bool {
+	f, ok := cmd.fieldMap[name]
+	if !ok {
+		return false
+	}
+	return f.value.setCount > 0
+}
+
+// IsSecret returns true if the field with the given name (or short name) has
+// the secret tag, in which case its value should not be logged or otherwise
+// exposed.
+func (cmd *Command) IsSecret(name string) bool {
+	f, ok := cmd.fieldMap[name]
+	if !ok {
+		return false
+	}
+	return f.Secret
+}
+
+// FieldMeta returns the extension tag metadata (see CLI.ExtensionTagPrefixes)
+// of the field with the given name (or short name), or nil if the field does
+// not exist or has no extension tags.
+func (cmd *Command) FieldMeta(name string) map[string]string {
+	f, ok := cmd.fieldMap[name]
+	if !ok {
+		return nil
+	}
+	return f.Meta
+}
+
+// FieldError is returned (wrapped in a UsageErrorWrapper) when a value for a
+// specific field could not be parsed.
+type FieldError struct {
+	// Name is the name of the field.
+	Name string
+	Err  error
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+func (e *FieldError) Error() string {
+	return e.Err.Error()
+}
+
+// AuthorizationError is returned when CLI.Authorize returns an error for a
+// command.
+type AuthorizationError struct {
+	// Command is the full name of the command, e.g. "mycli admin".
+	Command string
+	Err     error
+}
+
+func (e *AuthorizationError) Unwrap() error {
+	return e.Err
+}
+
+func (e *AuthorizationError) Error() string {
+	return fmt.Sprintf("not authorized to run %s: %s", e.Command, e.Err)
+}
+
+// ExitCode returns the exit code of the underlying error if it implements
+// ExitCoder, otherwise 77 (EX_NOPERM from sysexits.h).
+func (e *AuthorizationError) ExitCode() int {
+	var ec ExitCoder
+	if errors.As(e.Err, &ec) {
+		return ec.ExitCode()
+	}
+	return 77
+}
+
 // UsageError wraps the given error as a UsageErrorWrapper.
 func UsageError(err error) UsageErrorWrapper {
 	return UsageErrorWrapper{Err: err}
@@ -349,13 +927,36 @@ func (r ParseResult) err(err error) ParseResult {
 	return r
 }
 
-func (r ParseResult) writeHelpIfUsageOrHelpError(err error) {
-	if err == nil || r.Command == nil || r.Command.cli.HelpWriter == nil {
+func (r ParseResult) writeHelpIfUsageOrHelpError(ctx context.Context, err error) {
+	if err == nil || r.Command == nil {
+		return
+	}
+	w := r.Command.cli.helpWriter(ctx)
+	if w == nil {
+		return
+	}
+	if err == ErrHelp {
+		if r.Command.helpTopic != "" {
+			r.Command.writeTopicHelp(w, r.Command.helpTopic)
+		} else {
+			r.Command.WriteHelp(w)
+		}
 		return
 	}
-	_, isUsageErr := err.(UsageErrorWrapper)
-	if isUsageErr || err == ErrHelp {
-		r.Command.WriteHelp(r.Command.cli.HelpWriter)
+	if _, isUsageErr := err.(UsageErrorWrapper); isUsageErr {
+		switch r.Command.cli.UsageErrorHelp {
+		case HelpFull:
+			// If the error is for a specific field, only show help for
+			// that field.
+			var fieldErr *FieldError
+			if errors.As(err, &fieldErr) {
+				r.Command.writeFieldHelp(w, fieldErr.Name)
+			} else {
+				r.Command.WriteHelp(w)
+			}
+		case HelpUsage:
+			r.Command.WriteUsage(w)
+		}
 	}
 }
 
@@ -368,18 +969,82 @@ func (r ParseResult) Run() error {
 	return r.RunWithContext(context.Background())
 }
 
+// CommandPath returns the path of the command which was parsed (see
+// Command.Path), or nil if no command was parsed.
+func (r ParseResult) CommandPath() []string {
+	if r.Command == nil {
+		return nil
+	}
+	return r.Command.Path()
+}
+
 // RunWithContext is like Run, but it accepts an explicit context which will be
 // passed to the command's Run method, if it accepts one.
 func (r ParseResult) RunWithContext(ctx context.Context) error {
 	if r.Err != nil {
-		r.writeHelpIfUsageOrHelpError(r.Err)
+		r.writeHelpIfUsageOrHelpError(ctx, r.Err)
 		return r.Err
 	}
 	if r.runFunc == nil {
 		return fmt.Errorf("no run method implemented")
 	}
-	if err := r.runFunc.run(ctx); err != nil {
-		r.writeHelpIfUsageOrHelpError(err)
+	interactive := Interactive(ctx) && !r.Command.noInput()
+	if ci, ok := r.Command.cli.detectedCI(); ok && ci.NoInput {
+		interactive = false
+	}
+	ctx = WithInteractive(ctx, interactive)
+	ctx = WithStdio(ctx, r.Command.cli.Stdin, r.Command.cli.Stdout, r.Command.cli.Stderr)
+	if r.Command.cli.TeeOutput != nil {
+		ctx = withTee(ctx, r.Command.cli.TeeOutput)
+	}
+	if r.Command.cli.FeatureEnabled != nil {
+		ctx = WithFeatures(ctx, r.Command.cli.FeatureEnabled)
+	}
+	if r.Command.cli.configWatch != nil {
+		watchCtx, stop := context.WithCancel(ctx)
+		defer stop()
+		r.Command.watchConfig(watchCtx)
+	}
+	run := func(ctx context.Context) error {
+		if err := r.Command.runSetupSteps(ctx); err != nil {
+			return err
+		}
+		setupDone(ctx)
+		return r.runFunc.run(ctx)
+	}
+	run = r.Command.withProfiling(run)
+	for i := len(r.Command.cli.Middleware) - 1; i >= 0; i-- {
+		mw, next := r.Command.cli.Middleware[i], run
+		run = func(ctx context.Context) error {
+			return mw(ctx, r.Command, next)
+		}
+	}
+	start := time.Now()
+	if r.Command.cli.CrashReports {
+		defer func() {
+			if p := recover(); p != nil {
+				r.Command.writeCrashReport(r.Command.cli.errWriter(ctx), crashReport{
+					problem:  fmt.Sprintf("panic: %v", p),
+					stack:    debug.Stack(),
+					duration: time.Since(start),
+				})
+				panic(p)
+			}
+		}()
+	}
+	err := run(ctx)
+	r.Command.timings.run = time.Since(start)
+	if _, isUsageErr := err.(UsageErrorWrapper); err != nil && !isUsageErr && r.Command.cli.CrashReports {
+		r.Command.writeCrashReport(r.Command.cli.errWriter(ctx), crashReport{
+			problem:  fmt.Sprintf("error: %s", err),
+			duration: r.Command.timings.run,
+		})
+	}
+	if errWriter := r.Command.cli.errWriter(ctx); r.Command.timingsEnabled() && errWriter != nil {
+		r.Command.writeTimings(errWriter)
+	}
+	if err != nil {
+		r.writeHelpIfUsageOrHelpError(ctx, err)
 		return err
 	}
 	return nil
@@ -402,6 +1067,10 @@ func (r ParseResult) RunWithSigCancel() error {
 // implements the ExitCoder interface, the result of ExitCode() will be used as
 // the exit code. If an error is returned that does not implement ExitCoder,
 // the exit code will be 1.
+//
+// Since RunFatal calls os.Exit (unless CLI.Exit is set), deferred functions
+// in the caller will not run; use AtExit to register cleanup functions, or use
+// Main instead.
 func (r ParseResult) RunFatal() {
 	r.RunFatalWithContext(context.Background())
 }
@@ -409,43 +1078,61 @@ func (r ParseResult) RunFatal() {
 // RunFatalWithContext is like RunFatal, but it accepts an explicit context
 // which will be passed to the command's Run method if it accepts one.
 func (r ParseResult) RunFatalWithContext(ctx context.Context) {
+	code := r.MainWithContext(ctx)
+	if r.Command != nil && r.Command.cli.Exit != nil {
+		r.Command.cli.Exit(code)
+		return
+	}
+	os.Exit(code)
+}
+
+// Main is like RunFatal, except it returns the exit code instead of exiting,
+// which allows deferred functions to run before exiting:
+//
+//	func main() {
+//		os.Exit(run())
+//	}
+//
+//	func run() int {
+//		f := openSomething()
+//		defer f.Close()
+//		return cli.New("mycli", &MyCLI{}).Parse().Main()
+//	}
+//
+// Functions registered with AtExit are run before Main returns.
+func (r ParseResult) Main() int {
+	return r.MainWithContext(context.Background())
+}
+
+// MainWithContext is like Main, but it accepts an explicit context which will
+// be passed to the command's Run method if it accepts one.
+func (r ParseResult) MainWithContext(ctx context.Context) int {
+	defer runAtExit()
 	err := r.RunWithContext(ctx)
-	if err != nil {
-		if err != ErrHelp && r.Command != nil && r.Command.cli.ErrWriter != nil {
-			fmt.Fprintf(r.Command.cli.ErrWriter, "error: %s\n", err)
-		}
-		if ec, ok := err.(ExitCoder); ok {
-			os.Exit(ec.ExitCode())
-		}
-		os.Exit(1)
+	if err == nil {
+		return 0
+	}
+	if r.Command == nil {
+		printMulticallError(err)
+	} else if errWriter := r.Command.cli.errWriter(ctx); err != ErrHelp && errWriter != nil {
+		fmt.Fprintf(r.Command.cli.styledWriter(errWriter), "error: %s\n", err)
 	}
-	os.Exit(0)
+	if ec, ok := err.(ExitCoder); ok {
+		return ec.ExitCode()
+	}
+	return 1
 }
 
 // RunFatalWithSigCancel is like RunFatal, but it automatically registers a
-// signal handler for SIGINT and SIGTERM that will cancel the context that is
-// passed to the command's Run method, if it accepts one.
+// signal handler for SIGINT and SIGTERM (or their Windows equivalents, see
+// ContextWithSigCancel) that will cancel the context that is passed to the
+// command's Run method, if it accepts one.
 func (r ParseResult) RunFatalWithSigCancel() {
 	ctx, stop := r.contextWithSigCancelIfSupported(context.Background())
 	defer stop()
 	r.RunFatalWithContext(ctx)
 }
 
-func (r ParseResult) contextWithSigCancelIfSupported(ctx context.Context) (context.Context, context.CancelFunc) {
-	if r.runFunc == nil || !r.runFunc.supportsContext {
-		return ctx, func() {}
-	}
-	ctx, cancel := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
-	go func() {
-		// Cancel the signal notify on the first signal so that subsequent
-		// SIGINT/SIGTERM immediately interrupt the program using the usual go
-		// runtime handling.
-		<-ctx.Done()
-		cancel()
-	}()
-	return ctx, cancel
-}
-
 type CommandOption interface {
 	Apply(cmd *Command)
 }
@@ -467,3 +1154,73 @@ func WithDescription(description string) CommandOption {
 		cmd.SetDescription(description)
 	})
 }
+
+// WithLookupEnv returns a CommandOption which overrides the CLI's LookupEnv
+// for the Command only. See Command.SetLookupEnv.
+func WithLookupEnv(lookupEnv LookupEnvFunc) CommandOption {
+	return commandOptionFunc(func(cmd *Command) {
+		cmd.SetLookupEnv(lookupEnv)
+	})
+}
+
+// WithSetter returns a CommandOption which overrides the CLI's Setter for the
+// Command only. Unlike other options, it is applied before the Command's
+// fields are built, since setters are resolved at that time.
+func WithSetter(setter SetterFunc) CommandOption {
+	return cliOverrideOption(func(cli *CLI) {
+		cli.Setter = setter
+	})
+}
+
+// WithHelpFlag returns a CommandOption which overrides the name and short name
+// of the Command's help flag (see CLI.HelpFlagName). If name is empty, the help
+// flag is disabled, and if shortName is empty, the help flag will not have a
+// short name.
+func WithHelpFlag(name string, shortName string) CommandOption {
+	return cliOverrideOption(func(cli *CLI) {
+		cli.HelpFlagName = name
+		cli.DisableHelpFlag = name == ""
+		cli.HelpFlagShortName = shortName
+		cli.DisableHelpFlagShortName = shortName == ""
+	})
+}
+
+// cliOverrideOption is a CommandOption which overrides settings on a copy of
+// the Command's CLI before its fields are built.
+type cliOverrideOption func(cli *CLI)
+
+func (o cliOverrideOption) Apply(cmd *Command) {
+	// Already applied by Build.
+}
+
+func WithHelpTopic(name string, title string, text string) CommandOption {
+	return commandOptionFunc(func(cmd *Command) {
+		cmd.AddHelpTopic(name, title, text)
+	})
+}
+
+func WithHidden(hidden bool) CommandOption {
+	return commandOptionFunc(func(cmd *Command) {
+		cmd.SetHidden(hidden)
+	})
+}
+
+func WithAnnotation(key string, value string) CommandOption {
+	return commandOptionFunc(func(cmd *Command) {
+		cmd.SetAnnotation(key, value)
+	})
+}
+
+func WithExperimental(experimental bool) CommandOption {
+	return commandOptionFunc(func(cmd *Command) {
+		cmd.SetExperimental(experimental)
+	})
+}
+
+// WithDisableEnv returns a CommandOption which disables environment variable
+// parsing for the Command. See Command.DisableEnv.
+func WithDisableEnv() CommandOption {
+	return commandOptionFunc(func(cmd *Command) {
+		cmd.DisableEnv()
+	})
+}
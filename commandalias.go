@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// AliasFile returns a function which can be used as CLI.CommandAliases to
+// load command aliases from the file at path. Each line of the file should
+// have the form "name = expansion"; blank lines and lines starting with "#"
+// are ignored. If the file does not exist, no aliases are loaded.
+func AliasFile(path string) func() (map[string]string, error) {
+	return func() (map[string]string, error) {
+		f, err := os.Open(path)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read alias file: %w", err)
+		}
+		defer f.Close()
+
+		aliases := map[string]string{}
+		scanner := bufio.NewScanner(f)
+		lineNum := 0
+		for scanner.Scan() {
+			lineNum += 1
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || line[0] == '#' {
+				continue
+			}
+			parts := strings.SplitN(line, "=", 2)
+			name := strings.TrimSpace(parts[0])
+			if len(parts) != 2 || name == "" {
+				return nil, fmt.Errorf("failed to parse alias file %s line %d: expected name = expansion", path, lineNum)
+			}
+			aliases[name] = strings.TrimSpace(parts[1])
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read alias file: %w", err)
+		}
+		return aliases, nil
+	}
+}
+
+// expandCommandAlias replaces the first arg with its alias expansion if it is
+// not a known command but matches an alias from CLI.CommandAliases.
+func (cmd *Command) expandCommandAlias(args []string) ([]string, bool, error) {
+	if cmd.cli.CommandAliases == nil {
+		return args, false, nil
+	}
+	if _, ok := cmd.commandMap[args[0]]; ok {
+		return args, false, nil
+	}
+
+	aliases, err := cmd.cli.CommandAliases()
+	if err != nil {
+		return nil, false, err
+	}
+	expansion, ok := aliases[args[0]]
+	if !ok {
+		return args, false, nil
+	}
+
+	expandedArgs, err := splitShellWords(expansion)
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid expansion for alias %s: %w", args[0], err)
+	}
+	return append(expandedArgs, args[1:]...), true, nil
+}
+
+// splitShellWords splits s into words separated by whitespace, respecting
+// single quotes, double quotes, and backslash escapes like a POSIX shell.
+func splitShellWords(s string) ([]string, error) {
+	words := []string{}
+	word := strings.Builder{}
+	inWord := false
+	var quote rune
+	escaped := false
+	for _, c := range s {
+		switch {
+		case escaped:
+			word.WriteRune(c)
+			escaped = false
+		case c == '\\' && quote != '\'':
+			escaped = true
+			inWord = true
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else {
+				word.WriteRune(c)
+			}
+		case c == '\'' || c == '"':
+			quote = c
+			inWord = true
+		case c == ' ' || c == '\t' || c == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(c)
+			inWord = true
+		}
+	}
+	if escaped {
+		return nil, fmt.Errorf("trailing backslash")
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote")
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type aliasTestCmd struct {
+	Verbose bool
+}
+
+type aliasTestSubCmd struct {
+	Message string
+	Force   bool
+}
+
+func (cmd *aliasTestSubCmd) Run() error {
+	return nil
+}
+
+func TestCommandAliases(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "aliases")
+	content := `
+# comment
+hi = --verbose greet --message 'hello, world'
+fg = greet --force
+greet = not-a-command
+`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+	cli := NewCLI()
+	cli.CommandAliases = AliasFile(path)
+
+	cmd := &aliasTestCmd{}
+	subcmd := &aliasTestSubCmd{}
+	r := cli.New("test", cmd, cli.New("greet", subcmd)).
+		ParseArgs([]string{"hi", "--force"})
+	require.NoError(t, r.Err)
+	assert.True(t, cmd.Verbose)
+	assert.Equal(t, &aliasTestSubCmd{Message: "hello, world", Force: true}, subcmd)
+
+	subcmd = &aliasTestSubCmd{}
+	r = cli.New("test", &aliasTestCmd{}, cli.New("greet", subcmd)).
+		ParseArgs([]string{"greet"})
+	require.NoError(t, r.Err)
+	assert.Equal(t, &aliasTestSubCmd{}, subcmd)
+
+	r = cli.New("test", &aliasTestCmd{}, cli.New("greet", &aliasTestSubCmd{})).
+		ParseArgs([]string{"unknown"})
+	assert.Error(t, r.Err)
+}
+
+func TestAliasFileMissing(t *testing.T) {
+	aliases, err := AliasFile(filepath.Join(t.TempDir(), "missing"))()
+	require.NoError(t, err)
+	assert.Empty(t, aliases)
+}
+
+func TestSplitShellWords(t *testing.T) {
+	cases := []struct {
+		in  string
+		out []string
+	}{
+		{"", []string{}},
+		{"foo bar", []string{"foo", "bar"}},
+		{"  foo\t bar  ", []string{"foo", "bar"}},
+		{`foo 'bar baz'`, []string{"foo", "bar baz"}},
+		{`foo "bar \"baz\""`, []string{"foo", `bar "baz"`}},
+		{`foo 'a\b'`, []string{"foo", `a\b`}},
+		{`foo\ bar ''`, []string{"foo bar", ""}},
+	}
+	for _, c := range cases {
+		out, err := splitShellWords(c.in)
+		require.NoError(t, err)
+		assert.Equal(t, c.out, out, c.in)
+	}
+
+	_, err := splitShellWords(`foo 'bar`)
+	assert.Error(t, err)
+	_, err = splitShellWords(`foo\`)
+	assert.Error(t, err)
+}
@@ -0,0 +1,165 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// readConfigFile reads a config file at path into a map of flag names to
+// values. The format is determined by the file extension: ".yaml" and ".yml"
+// files are YAML, ".env" files are dotenv files (see readDotEnvFile), and all
+// other files are JSON. Scalar values are converted to strings, arrays are
+// converted to multiple values (so they can be used with append fields), and
+// objects are kept as JSON strings. If the file does not exist, an empty map
+// is returned.
+func readConfigFile(path string) (map[string][]string, error) {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string][]string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	raw := map[string]interface{}{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".env":
+		return readDotEnvFile(path, b)
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(b, &raw); err != nil {
+			return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+	default:
+		dec := json.NewDecoder(bytes.NewReader(b))
+		dec.UseNumber()
+		if err := dec.Decode(&raw); err != nil {
+			return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+	}
+
+	values := map[string][]string{}
+	for key, val := range raw {
+		vals, err := configValueStrings(val)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse config file %s: invalid value for %s: %w", path, key, err)
+		}
+		values[key] = vals
+	}
+	return values, nil
+}
+
+// readDotEnvFile parses the contents b of a dotenv file, which contains lines
+// of the form KEY=value (optionally prefixed with "export", and with values
+// optionally quoted). Blank lines and lines starting with "#" are ignored.
+// Each value is stored both under its key, so it can be matched to a field's
+// environment variable, and under the key converted to a flag name (e.g.
+// "LOG_LEVEL" as "log-level").
+func readDotEnvFile(path string, b []byte) (map[string][]string, error) {
+	values := map[string][]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		k, v, found := strings.Cut(line, "=")
+		k = strings.TrimSpace(k)
+		if !found || k == "" {
+			return nil, fmt.Errorf("failed to parse config file %s: line %d: expected KEY=value", path, lineNum)
+		}
+		v = strings.TrimSpace(v)
+		switch {
+		case strings.HasPrefix(v, `"`):
+			unquoted, err := strconv.Unquote(v)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse config file %s: line %d: %w", path, lineNum, err)
+			}
+			v = unquoted
+		case len(v) >= 2 && strings.HasPrefix(v, "'") && strings.HasSuffix(v, "'"):
+			v = v[1 : len(v)-1]
+		}
+		values[k] = []string{v}
+		values[strings.ReplaceAll(strings.ToLower(k), "_", "-")] = []string{v}
+	}
+	return values, scanner.Err()
+}
+
+func configValueStrings(val interface{}) ([]string, error) {
+	switch v := val.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{v}, nil
+	case []interface{}:
+		vals := []string{}
+		for _, elem := range v {
+			elemVals, err := configValueStrings(elem)
+			if err != nil {
+				return nil, err
+			}
+			vals = append(vals, elemVals...)
+		}
+		return vals, nil
+	case map[string]interface{}:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		return []string{string(b)}, nil
+	default:
+		return []string{fmt.Sprintf("%v", v)}, nil
+	}
+}
+
+// configFileSource is a ValueSource for the values read from a config file.
+type configFileSource map[string][]string
+
+func (s configFileSource) LookupValues(name string) ([]string, bool, error) {
+	vals, ok := s[name]
+	return vals, ok, nil
+}
+
+// configFileSources reads the config files referenced by any fields with the
+// config tag which have a non-empty value. It is an error for a file which was
+// set explicitly (rather than by default) not to exist.
+func (cmd *Command) configFileSources() ([]ValueSource, error) {
+	sources := []ValueSource{}
+	for _, f := range cmd.fields {
+		if !f.ConfigFile || !cmd.fieldAvailable(f) {
+			continue
+		}
+		target := f.value.target
+		if target.Kind() == reflect.Ptr {
+			if target.IsNil() {
+				continue
+			}
+			target = target.Elem()
+		}
+		path := target.String()
+		if path == "" {
+			continue
+		}
+		if f.value.setCount > 0 {
+			if _, err := os.Stat(path); err != nil {
+				return nil, fmt.Errorf("failed to read config file: %w", err)
+			}
+		}
+		values, err := readConfigFile(path)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, configFileSource(values))
+	}
+	return sources, nil
+}
@@ -0,0 +1,154 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	content := `{
+		"addr": ":8080",
+		"level": 3,
+		"verbose": true,
+		"tags": ["a", "b"],
+		"from-env": "file",
+		"from-arg": "file",
+		"unknown": "ignored",
+		"nothing": null
+	}`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+	type Cmd struct {
+		Addr    string
+		Level   int
+		Verbose bool
+		Tags    []string `cli:"append"`
+		FromEnv string   `cli:"env=FROM_ENV"`
+		FromArg string
+		Nothing string
+	}
+	cli := NewCLI()
+	cli.ConfigFile = path
+	cli.LookupEnv = func(key string) (string, bool, error) {
+		return "env", key == "FROM_ENV", nil
+	}
+	cmd := &Cmd{Nothing: "default"}
+	r := cli.New("test", cmd).ParseArgs([]string{"--from-arg", "arg"})
+	require.NoError(t, r.Err)
+
+	expected := &Cmd{
+		Addr:    ":8080",
+		Level:   3,
+		Verbose: true,
+		Tags:    []string{"a", "b"},
+		FromEnv: "env",
+		FromArg: "arg",
+		Nothing: "default",
+	}
+	assert.Equal(t, expected, cmd)
+}
+
+func TestConfigFileMissing(t *testing.T) {
+	cli := NewCLI()
+	cli.ConfigFile = filepath.Join(t.TempDir(), "missing.json")
+	r := cli.New("test", &struct{ Foo string }{}).ParseArgs([]string{})
+	require.NoError(t, r.Err)
+}
+
+func TestConfigFileInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"level": "high"}`), 0644))
+
+	cli := NewCLI()
+	cli.ConfigFile = path
+	r := cli.New("test", &struct{ Level int }{}).ParseArgs([]string{})
+	assert.Error(t, r.Err)
+}
+
+func TestConfigTag(t *testing.T) {
+	dir := t.TempDir()
+	yamlPath := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(yamlPath, []byte(`
+addr: ":8080"
+level: 3
+tags: [a, b]
+from-env: file
+from-arg: file
+sub-flag: from-yaml
+`), 0644))
+	envPath := filepath.Join(dir, "app.env")
+	require.NoError(t, os.WriteFile(envPath, []byte(`
+# comment
+export ADDR=":9090"
+APP_LEVEL=4
+TAGS='c'
+`), 0644))
+
+	type Sub struct {
+		SubFlag string
+	}
+	type Cmd struct {
+		Config  string `cli:"config"`
+		Addr    string
+		Level   int      `cli:"env=APP_LEVEL"`
+		Tags    []string `cli:"append"`
+		FromEnv string   `cli:"env=FROM_ENV"`
+		FromArg string
+	}
+	lookupEnv := func(key string) (string, bool, error) {
+		return "env", key == "FROM_ENV", nil
+	}
+
+	c := NewCLI()
+	c.LookupEnv = lookupEnv
+	cmd := &Cmd{}
+	sub := &Sub{}
+	r := c.New("test", cmd, c.New("sub", sub)).ParseArgs([]string{
+		"--config", yamlPath, "--from-arg", "arg", "sub",
+	})
+	require.NoError(t, r.Err)
+	assert.Equal(t, &Cmd{
+		Config:  yamlPath,
+		Addr:    ":8080",
+		Level:   3,
+		Tags:    []string{"a", "b"},
+		FromEnv: "env",
+		FromArg: "arg",
+	}, cmd)
+	assert.Equal(t, "from-yaml", sub.SubFlag)
+
+	// Dotenv files are matched by flag name and environment variable name.
+	c = NewCLI()
+	c.LookupEnv = lookupEnv
+	cmd = &Cmd{Config: envPath}
+	require.NoError(t, c.New("test", cmd).ParseArgs([]string{}).Err)
+	assert.Equal(t, ":9090", cmd.Addr)
+	assert.Equal(t, 4, cmd.Level)
+	assert.Equal(t, []string{"c"}, cmd.Tags)
+
+	// Missing default config files are ignored, but not explicit ones.
+	require.NoError(t, New("test", &Cmd{Config: filepath.Join(dir, "missing.yaml")}).ParseArgs([]string{}).Err)
+	r = New("test", &Cmd{}).ParseArgs([]string{"--config", filepath.Join(dir, "missing.yaml")})
+	require.Error(t, r.Err)
+	assert.Contains(t, r.Err.Error(), "failed to read config file")
+
+	assert.Panics(t, func() {
+		New("test", &struct {
+			Config int `cli:"config"`
+		}{})
+	})
+}
+
+func TestReadDotEnvFileErrors(t *testing.T) {
+	_, err := readDotEnvFile("test.env", []byte("FOO=1\nBAR\n"))
+	assert.EqualError(t, err, "failed to parse config file test.env: line 2: expected KEY=value")
+
+	_, err = readDotEnvFile("test.env", []byte(`FOO="unterminated`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "line 1")
+}
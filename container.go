@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// ListenOptions can be embedded in a command config to add standard flags
+// for the address a service listens on, which can also be set using the
+// PORT and BIND_ADDR environment variables that container platforms (e.g.
+// Cloud Run, Heroku, and many Kubernetes setups) conventionally use. Use
+// ListenAddr to get the address to listen on.
+type ListenOptions struct {
+	BindAddr string `cli:"name=bind-addr,env=BIND_ADDR,placeholder=HOST[:PORT],help=address to listen on"`
+	Port     int    `cli:"name=port,env=PORT,help=port to listen on (overrides the port in --bind-addr)"`
+}
+
+// ListenAddr returns the "host:port" address to listen on. As usual, flags
+// take precedence over environment variables. The port is taken from --port
+// (or PORT) if set, otherwise from --bind-addr (or BIND_ADDR) if it includes
+// one, otherwise defaultPort is used. The host is taken from --bind-addr (or
+// BIND_ADDR), and is empty (i.e. all interfaces) by default.
+func (o ListenOptions) ListenAddr(defaultPort int) string {
+	host := o.BindAddr
+	port := strconv.Itoa(defaultPort)
+	if h, p, err := net.SplitHostPort(o.BindAddr); err == nil {
+		host, port = h, p
+	}
+	if o.Port != 0 {
+		port = strconv.Itoa(o.Port)
+	}
+	return net.JoinHostPort(host, port)
+}
+
+// WriteDockerfileEnv writes a Dockerfile snippet with an ARG and ENV
+// instruction for each of the command's fields which can be set by an
+// environment variable, so that images can be built with different defaults
+// (e.g. "docker build --build-arg PORT=9000"):
+//
+//	# port to listen on
+//	ARG PORT=8080
+//	ENV PORT=${PORT}
+//
+// Fields without a default only get an ARG instruction, since an ENV
+// instruction would set the variable to an empty string when the build arg
+// isn't passed, which is treated as set and fails to parse for non-string
+// fields; they can still be set when the container is run. Secret fields are
+// omitted, since build args are visible in the image history.
+func (cmd *Command) WriteDockerfileEnv(w io.Writer) error {
+	first := true
+	for _, f := range cmd.Fields() {
+		if f.Env == "" || isSecretRef(f.Env) || f.Secret {
+			continue
+		}
+		if !first {
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+		first = false
+
+		sb := strings.Builder{}
+		if f.Help != "" {
+			fmt.Fprintf(&sb, "# %s\n", f.Help)
+		}
+		if f.Default != "" && !f.Unset {
+			fmt.Fprintf(&sb, "ARG %s=%s\n", f.Env, dockerfileQuote(f.Default))
+			fmt.Fprintf(&sb, "ENV %s=${%s}\n", f.Env, f.Env)
+		} else {
+			fmt.Fprintf(&sb, "ARG %s\n", f.Env)
+		}
+		if _, err := io.WriteString(w, sb.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dockerfileQuote quotes s if it contains characters which have special
+// meaning in Dockerfile ARG and ENV instructions.
+func dockerfileQuote(s string) string {
+	if strings.ContainsAny(s, " \t\n\"'\\$") {
+		return strconv.Quote(s)
+	}
+	return s
+}
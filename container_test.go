@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListenAddr(t *testing.T) {
+	cases := []struct {
+		opts     ListenOptions
+		expected string
+	}{
+		{ListenOptions{}, ":8080"},
+		{ListenOptions{BindAddr: "127.0.0.1"}, "127.0.0.1:8080"},
+		{ListenOptions{BindAddr: "127.0.0.1:9000"}, "127.0.0.1:9000"},
+		{ListenOptions{BindAddr: "127.0.0.1:9000", Port: 9001}, "127.0.0.1:9001"},
+		{ListenOptions{BindAddr: "::1"}, "[::1]:8080"},
+		{ListenOptions{Port: 3000}, ":3000"},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.expected, c.opts.ListenAddr(8080), c.opts)
+	}
+}
+
+func TestListenOptionsEnv(t *testing.T) {
+	t.Setenv("PORT", "9000")
+	t.Setenv("BIND_ADDR", "0.0.0.0:80")
+	config := &struct {
+		ListenOptions
+	}{}
+	require.NoError(t, New("test", config).ParseArgs([]string{}).Err)
+	assert.Equal(t, "0.0.0.0:9000", config.ListenAddr(8080))
+
+	require.NoError(t, New("test", config).ParseArgs([]string{"--port", "1234"}).Err)
+	assert.Equal(t, "0.0.0.0:1234", config.ListenAddr(8080))
+}
+
+func TestWriteDockerfileEnv(t *testing.T) {
+	config := &struct {
+		Greeting string `cli:"env=GREETING,help=greeting to print"`
+		Name     string `cli:"env=NAME"`
+		Token    string `cli:"env=TOKEN,secret"`
+		Verbose  bool
+	}{
+		Greeting: "hello world",
+	}
+	b := &strings.Builder{}
+	require.NoError(t, New("test", config).WriteDockerfileEnv(b))
+	assert.Equal(
+		t,
+		"# greeting to print\n"+
+			"ARG GREETING=\"hello world\"\n"+
+			"ENV GREETING=${GREETING}\n"+
+			"\n"+
+			"ARG NAME\n",
+		b.String(),
+	)
+}
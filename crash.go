@@ -0,0 +1,185 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+const redacted = "REDACTED"
+
+// crashReport contains diagnostic information which is written to a file by
+// writeCrashReport.
+type crashReport struct {
+	problem  string
+	stack    []byte
+	duration time.Duration
+}
+
+// writeCrashReport writes a diagnostic bundle for the command to a temp file
+// and prints its path to w (usually ErrWriter). Errors writing the bundle are
+// also printed, since there is nothing else which can be done with them.
+func (cmd *Command) writeCrashReport(w io.Writer, report crashReport) {
+	path, err := cmd.writeCrashReportFile(report)
+	if w == nil {
+		return
+	}
+	if err != nil {
+		fmt.Fprintf(w, "error: failed to write crash report: %s\n", err)
+		return
+	}
+	fmt.Fprintf(w, "crash report written to %s\n", path)
+}
+
+func (cmd *Command) writeCrashReportFile(report crashReport) (string, error) {
+	root := cmd
+	for root.parent != nil {
+		root = root.parent
+	}
+
+	f, err := os.CreateTemp("", root.name+"-crash-*.txt")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	b := strings.Builder{}
+	fmt.Fprintf(&b, "command: %s\n", cmd.fullName())
+	if cmd.cli.Version != "" {
+		fmt.Fprintf(&b, "version: %s\n", cmd.cli.Version)
+	}
+	fmt.Fprintf(&b, "go: %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(&b, "time: %s\n", time.Now().Format(time.RFC3339))
+	fmt.Fprintf(&b, "duration: %s\n", report.duration)
+	fmt.Fprintf(&b, "args: %q\n", cmd.redactArgs(root.parsedArgs))
+	if env := cmd.crashReportEnv(os.Environ()); len(env) > 0 {
+		b.WriteString("env:\n")
+		for _, kv := range env {
+			fmt.Fprintf(&b, "    %s\n", kv)
+		}
+	}
+	fmt.Fprintf(&b, "\n%s\n", report.problem)
+	if len(report.stack) > 0 {
+		fmt.Fprintf(&b, "\n%s", report.stack)
+	}
+
+	if _, err := f.WriteString(b.String()); err != nil {
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// secretFields returns a map of all of the names (including short names and
+// aliases) of secret fields in the command and its parents.
+func (cmd *Command) secretFields() map[string]field {
+	secrets := map[string]field{}
+	for c := cmd; c != nil; c = c.parent {
+		for name, f := range c.fieldMap {
+			if f.Secret {
+				secrets[name] = f
+			}
+		}
+	}
+	return secrets
+}
+
+// redactArgs returns a copy of args with the values of any secret fields
+// replaced. Arguments are split into flag names and values in the same way as
+// the parser, so combined short flags (e.g. "-vp secret") and slash flags (if
+// enabled) are also handled.
+func (cmd *Command) redactArgs(args []string) []string {
+	secrets := cmd.secretFields()
+	known := map[string]bool{}
+	aliases := map[string]bool{}
+	for c := cmd; c != nil; c = c.parent {
+		for name, f := range c.fieldMap {
+			known[name] = true
+			if f.hasAlias(name) {
+				aliases[name] = true
+			}
+		}
+	}
+
+	redactedArgs := make([]string, len(args))
+	copy(redactedArgs, args)
+	for i := 0; i < len(redactedArgs); i++ {
+		arg := redactedArgs[i]
+		if arg == "--" {
+			break
+		}
+
+		// prefix is the part of arg before the name of the flag which may
+		// take a value, and sep separates the name from an inline value.
+		var prefix, name string
+		sep := byte('=')
+		switch {
+		case strings.HasPrefix(arg, "--"):
+			prefix, name = "--", arg[2:]
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			prefix, name = "-", arg[1:]
+			// Unless the name matches an alias, each character is a
+			// separate short flag, and only the last can take a value.
+			end := len(name)
+			if eq := strings.IndexByte(name, '='); eq > 0 {
+				end = eq
+			}
+			if !aliases[name[:end]] && end > 1 {
+				prefix, name = arg[:end], name[end-1:]
+			}
+		case cmd.cli.SlashFlags && strings.HasPrefix(arg, "/"):
+			prefix, name, sep = "/", arg[1:], ':'
+			flagName := name
+			if colon := strings.IndexByte(name, ':'); colon >= 0 {
+				flagName = name[:colon]
+			}
+			if !known[flagName] {
+				continue
+			}
+		default:
+			continue
+		}
+
+		if j := strings.IndexByte(name, sep); j >= 0 {
+			if _, ok := secrets[name[:j]]; ok {
+				redactedArgs[i] = prefix + name[:j+1] + redacted
+			}
+			continue
+		}
+		if f, ok := secrets[name]; ok && f.HasArg && i+1 < len(redactedArgs) {
+			redactedArgs[i+1] = redacted
+			i++
+		}
+	}
+	return redactedArgs
+}
+
+// crashReportEnv returns the sorted environment variables which start with
+// the CLI's EnvPrefix, with the values of secret fields replaced.
+func (cmd *Command) crashReportEnv(environ []string) []string {
+	if cmd.cli.EnvPrefix == "" {
+		return nil
+	}
+	secretEnvVars := map[string]bool{}
+	for _, f := range cmd.secretFields() {
+		if f.EnvVarName != "" {
+			secretEnvVars[f.EnvVarName] = true
+		}
+	}
+	env := []string{}
+	for _, kv := range environ {
+		key := strings.SplitN(kv, "=", 2)[0]
+		if !strings.HasPrefix(key, cmd.cli.EnvPrefix) {
+			continue
+		}
+		if secretEnvVars[key] {
+			kv = key + "=" + redacted
+		}
+		env = append(env, kv)
+	}
+	sort.Strings(env)
+	return env
+}
@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type crashTestCmd struct {
+	Token string `cli:"secret,short=t,env"`
+	Name  string
+	Panic bool
+}
+
+func (cmd *crashTestCmd) Run() error {
+	if cmd.Panic {
+		panic("oh no")
+	}
+	return fmt.Errorf("failed")
+}
+
+func readCrashReport(t *testing.T, errOutput string) string {
+	m := regexp.MustCompile(`crash report written to (.*)\n`).FindStringSubmatch(errOutput)
+	require.NotNil(t, m, errOutput)
+	b, err := os.ReadFile(m[1])
+	require.NoError(t, err)
+	return string(b)
+}
+
+func TestCrashReportError(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	t.Setenv("TEST_TOKEN", "envsecret")
+	t.Setenv("TEST_OTHER", "visible")
+
+	b := &strings.Builder{}
+	cli := CLI{
+		ErrWriter:    b,
+		LookupEnv:    osLookupEnv,
+		EnvPrefix:    "TEST_",
+		Version:      "1.2.3",
+		CrashReports: true,
+	}
+	err := cli.New("test", &crashTestCmd{}).
+		ParseArgs([]string{"--token", "argsecret", "--name=foo", "-t=argsecret"}).
+		Run()
+	require.EqualError(t, err, "failed")
+
+	report := readCrashReport(t, b.String())
+	assert.Contains(t, report, "command: test\n")
+	assert.Contains(t, report, "version: 1.2.3\n")
+	assert.Contains(t, report, `args: ["--token" "REDACTED" "--name=foo" "-t=REDACTED"]`)
+	assert.Contains(t, report, "    TEST_OTHER=visible\n    TEST_TOKEN=REDACTED\n")
+	assert.Contains(t, report, "\nerror: failed\n")
+	assert.NotContains(t, report, "secret")
+}
+
+func TestCrashReportRedactArgs(t *testing.T) {
+	type Cmd struct {
+		Verbose  bool   `cli:"short=v"`
+		Password string `cli:"secret,short=p,alias=pw"`
+		Name     string `cli:"short=n"`
+	}
+	cli := NewCLI()
+	cli.SlashFlags = true
+	cmd := cli.New("test", &Cmd{})
+
+	testCases := []struct {
+		args     []string
+		expected []string
+	}{
+		{[]string{"-vp", "secret"}, []string{"-vp", redacted}},
+		{[]string{"-vp=secret"}, []string{"-vp=" + redacted}},
+		{[]string{"-p", "secret"}, []string{"-p", redacted}},
+		{[]string{"-pw=secret"}, []string{"-pw=" + redacted}},
+		{[]string{"--password", "secret"}, []string{"--password", redacted}},
+		{[]string{"/password:secret"}, []string{"/password:" + redacted}},
+		{[]string{"/password", "secret"}, []string{"/password", redacted}},
+		{[]string{"/vp", "visible"}, []string{"/vp", "visible"}},
+		{[]string{"-vn", "visible"}, []string{"-vn", "visible"}},
+		{[]string{"--", "-p", "visible"}, []string{"--", "-p", "visible"}},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, cmd.redactArgs(tc.args), "%q", tc.args)
+	}
+
+	cli.SlashFlags = false
+	cmd = cli.New("test", &Cmd{})
+	assert.Equal(t, []string{"/password", "visible"}, cmd.redactArgs([]string{"/password", "visible"}))
+}
+
+func TestCrashReportPanic(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+
+	b := &strings.Builder{}
+	cli := CLI{ErrWriter: b, LookupEnv: osLookupEnv, CrashReports: true}
+	assert.Panics(t, func() {
+		cli.New("test", &crashTestCmd{}).ParseArgs([]string{"--panic"}).Run()
+	})
+
+	report := readCrashReport(t, b.String())
+	assert.Contains(t, report, "\npanic: oh no\n")
+	assert.Contains(t, report, "goroutine")
+}
+
+func TestCrashReportDisabled(t *testing.T) {
+	b := &strings.Builder{}
+	cli := CLI{ErrWriter: b, LookupEnv: osLookupEnv}
+	err := cli.New("test", &crashTestCmd{}).ParseArgs([]string{}).Run()
+	require.Error(t, err)
+	assert.Equal(t, "", b.String())
+}
@@ -0,0 +1,281 @@
+// Package dbopts provides embeddable flags for connecting to PostgreSQL,
+// MySQL, and SQLite databases, which build DSNs or open *sql.DB handles with
+// connection pool settings applied:
+//
+//	import _ "github.com/jackc/pgx/v5/stdlib"
+//
+//	type Migrate struct {
+//		dbopts.Postgres
+//	}
+//
+//	func (m *Migrate) Run(ctx context.Context) error {
+//		db, err := m.Open("pgx")
+//		if err != nil {
+//			return err
+//		}
+//		defer db.Close()
+//		...
+//	}
+//
+// This package doesn't import any database drivers; the driver name passed to
+// Open must be registered by importing one. Passwords are marked secret, so
+// their values are not shown in help text, and are masked by Redacted.
+package dbopts
+
+import (
+	"database/sql"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Pool can be embedded in a command config to add flags for configuring a
+// *sql.DB connection pool. Zero values leave the database/sql defaults
+// unchanged.
+type Pool struct {
+	MaxOpenConns    int           `cli:"name=db-max-open-conns,env=DB_MAX_OPEN_CONNS,nodefault,placeholder=N,help=maximum number of open database connections"`
+	MaxIdleConns    int           `cli:"name=db-max-idle-conns,env=DB_MAX_IDLE_CONNS,nodefault,placeholder=N,help=maximum number of idle database connections"`
+	ConnMaxLifetime time.Duration `cli:"name=db-conn-max-lifetime,env=DB_CONN_MAX_LIFETIME,nodefault,placeholder=DURATION,help=maximum time a database connection may be reused"`
+	ConnMaxIdleTime time.Duration `cli:"name=db-conn-max-idle-time,env=DB_CONN_MAX_IDLE_TIME,nodefault,placeholder=DURATION,help=maximum time a database connection may be idle"`
+}
+
+// Apply sets the pool options on db.
+func (p *Pool) Apply(db *sql.DB) {
+	if p.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(p.MaxOpenConns)
+	}
+	if p.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(p.MaxIdleConns)
+	}
+	if p.ConnMaxLifetime > 0 {
+		db.SetConnMaxLifetime(p.ConnMaxLifetime)
+	}
+	if p.ConnMaxIdleTime > 0 {
+		db.SetConnMaxIdleTime(p.ConnMaxIdleTime)
+	}
+}
+
+func (p *Pool) validate() error {
+	if p.MaxOpenConns < 0 || p.MaxIdleConns < 0 {
+		return fmt.Errorf("connection counts must not be negative")
+	}
+	if p.MaxOpenConns > 0 && p.MaxIdleConns > p.MaxOpenConns {
+		return fmt.Errorf("--db-max-idle-conns (%d) must not be more than --db-max-open-conns (%d)", p.MaxIdleConns, p.MaxOpenConns)
+	}
+	return nil
+}
+
+// open opens a database with dsn and applies pool settings.
+func open(driverName string, dsn string, pool *Pool) (*sql.DB, error) {
+	if err := pool.validate(); err != nil {
+		return nil, err
+	}
+	db, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+	pool.Apply(db)
+	return db, nil
+}
+
+const redacted = "xxxxx"
+
+// Postgres can be embedded in a command config to add flags for connecting
+// to a PostgreSQL database. The environment variables are the ones used by
+// libpq (e.g. PGHOST), and DATABASE_URL.
+type Postgres struct {
+	URL      string `cli:"name=pg-url,env=DATABASE_URL,secret,placeholder=URL,help=PostgreSQL connection URL (overrides the other --pg options)"`
+	Host     string `cli:"name=pg-host,env=PGHOST,placeholder=HOST,help=PostgreSQL host (localhost if not set)"`
+	Port     int    `cli:"name=pg-port,env=PGPORT,nodefault,placeholder=PORT,help=PostgreSQL port (5432 if not set)"`
+	User     string `cli:"name=pg-user,env=PGUSER,placeholder=USER,help=PostgreSQL user"`
+	Password string `cli:"name=pg-password,env=PGPASSWORD,secret,placeholder=PASSWORD,help=PostgreSQL password"`
+	Database string `cli:"name=pg-database,env=PGDATABASE,placeholder=NAME,help=PostgreSQL database name"`
+	SSLMode  string `cli:"name=pg-sslmode,env=PGSSLMODE,placeholder=MODE,help=SSL mode (disable/allow/prefer/require/verify-ca/verify-full)"`
+	Pool
+}
+
+var postgresSSLModes = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+
+// DSN returns a postgres:// connection URL built from the options, or URL if
+// it is set.
+func (pg *Postgres) DSN() (string, error) {
+	u, err := pg.url()
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
+
+// Redacted returns the DSN with any password masked, for logging.
+func (pg *Postgres) Redacted() (string, error) {
+	u, err := pg.url()
+	if err != nil {
+		return "", err
+	}
+	return u.Redacted(), nil
+}
+
+func (pg *Postgres) url() (*url.URL, error) {
+	if pg.URL != "" {
+		u, err := url.Parse(pg.URL)
+		if err != nil || (u.Scheme != "postgres" && u.Scheme != "postgresql") {
+			return nil, fmt.Errorf("invalid --pg-url: must be a postgres:// URL")
+		}
+		return u, nil
+	}
+	if pg.SSLMode != "" && !contains(postgresSSLModes, pg.SSLMode) {
+		return nil, fmt.Errorf("invalid --pg-sslmode %q: must be one of: %s", pg.SSLMode, strings.Join(postgresSSLModes, ", "))
+	}
+	u := &url.URL{
+		Scheme: "postgres",
+		Host:   hostPort(pg.Host, pg.Port, 5432),
+	}
+	if pg.User != "" {
+		u.User = url.User(pg.User)
+		if pg.Password != "" {
+			u.User = url.UserPassword(pg.User, pg.Password)
+		}
+	}
+	if pg.Database != "" {
+		u.Path = "/" + pg.Database
+	}
+	if pg.SSLMode != "" {
+		u.RawQuery = url.Values{"sslmode": {pg.SSLMode}}.Encode()
+	}
+	return u, nil
+}
+
+// Open opens the database using the named driver (e.g. "pgx" or "postgres"),
+// with the pool options applied.
+func (pg *Postgres) Open(driverName string) (*sql.DB, error) {
+	dsn, err := pg.DSN()
+	if err != nil {
+		return nil, err
+	}
+	return open(driverName, dsn, &pg.Pool)
+}
+
+// MySQL can be embedded in a command config to add flags for connecting to a
+// MySQL database. DSNs use the format of github.com/go-sql-driver/mysql. The
+// environment variables are the ones used by the mysql client where they
+// exist (e.g. MYSQL_PWD).
+type MySQL struct {
+	Host      string `cli:"name=mysql-host,env=MYSQL_HOST,placeholder=HOST,help=MySQL host (localhost if not set)"`
+	Port      int    `cli:"name=mysql-port,env=MYSQL_TCP_PORT,nodefault,placeholder=PORT,help=MySQL port (3306 if not set)"`
+	User      string `cli:"name=mysql-user,env=MYSQL_USER,placeholder=USER,help=MySQL user"`
+	Password  string `cli:"name=mysql-password,env=MYSQL_PWD,secret,placeholder=PASSWORD,help=MySQL password"`
+	Database  string `cli:"name=mysql-database,env=MYSQL_DATABASE,placeholder=NAME,help=MySQL database name"`
+	TLS       string `cli:"name=mysql-tls,env=MYSQL_TLS,placeholder=MODE,help=TLS mode (true/false/skip-verify/preferred)"`
+	ParseTime bool   `cli:"name=mysql-parse-time,help=scan DATE and DATETIME values into time.Time"`
+	Pool
+}
+
+var mysqlTLSModes = []string{"true", "false", "skip-verify", "preferred"}
+
+// DSN returns a DSN built from the options.
+func (my *MySQL) DSN() (string, error) {
+	return my.dsn(my.Password)
+}
+
+// Redacted returns the DSN with any password masked, for logging.
+func (my *MySQL) Redacted() (string, error) {
+	password := my.Password
+	if password != "" {
+		password = redacted
+	}
+	return my.dsn(password)
+}
+
+func (my *MySQL) dsn(password string) (string, error) {
+	if my.TLS != "" && !contains(mysqlTLSModes, my.TLS) {
+		return "", fmt.Errorf("invalid --mysql-tls %q: must be one of: %s", my.TLS, strings.Join(mysqlTLSModes, ", "))
+	}
+	b := &strings.Builder{}
+	if my.User != "" {
+		b.WriteString(my.User)
+		if password != "" {
+			b.WriteString(":" + password)
+		}
+		b.WriteString("@")
+	}
+	fmt.Fprintf(b, "tcp(%s)/%s", hostPort(my.Host, my.Port, 3306), my.Database)
+	params := url.Values{}
+	if my.TLS != "" {
+		params.Set("tls", my.TLS)
+	}
+	if my.ParseTime {
+		params.Set("parseTime", "true")
+	}
+	if len(params) > 0 {
+		b.WriteString("?" + params.Encode())
+	}
+	return b.String(), nil
+}
+
+// Open opens the database using the named driver (usually "mysql"), with the
+// pool options applied.
+func (my *MySQL) Open(driverName string) (*sql.DB, error) {
+	dsn, err := my.DSN()
+	if err != nil {
+		return nil, err
+	}
+	return open(driverName, dsn, &my.Pool)
+}
+
+// SQLite can be embedded in a command config to add flags for opening a
+// SQLite database file. DSNs are "file:" URIs; the busy timeout uses the
+// _busy_timeout parameter of github.com/mattn/go-sqlite3.
+type SQLite struct {
+	Path        string        `cli:"name=sqlite-path,env=SQLITE_PATH,placeholder=PATH,help=path to the SQLite database file"`
+	ReadOnly    bool          `cli:"name=sqlite-read-only,help=open the database read-only"`
+	BusyTimeout time.Duration `cli:"name=sqlite-busy-timeout,env=SQLITE_BUSY_TIMEOUT,nodefault,placeholder=DURATION,help=how long to wait for locks to be released"`
+	Pool
+}
+
+// DSN returns a DSN built from the options.
+func (lite *SQLite) DSN() (string, error) {
+	if lite.Path == "" {
+		return "", fmt.Errorf("no SQLite database path specified")
+	}
+	params := url.Values{}
+	if lite.ReadOnly {
+		params.Set("mode", "ro")
+	}
+	if lite.BusyTimeout > 0 {
+		params.Set("_busy_timeout", strconv.FormatInt(lite.BusyTimeout.Milliseconds(), 10))
+	}
+	u := &url.URL{Scheme: "file", Opaque: lite.Path, RawQuery: params.Encode()}
+	return u.String(), nil
+}
+
+// Open opens the database using the named driver (e.g. "sqlite3"), with the
+// pool options applied.
+func (lite *SQLite) Open(driverName string) (*sql.DB, error) {
+	dsn, err := lite.DSN()
+	if err != nil {
+		return nil, err
+	}
+	return open(driverName, dsn, &lite.Pool)
+}
+
+func hostPort(host string, port int, defaultPort int) string {
+	if host == "" {
+		host = "localhost"
+	}
+	if port == 0 {
+		port = defaultPort
+	}
+	return net.JoinHostPort(host, strconv.Itoa(port))
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Decimal is a field type for fixed-point decimal numbers such as "19.99",
+// which are parsed exactly rather than as floats. Exponents and digit
+// separators are not accepted.
+//
+// Decimal values are immutable, so they can be copied freely. The zero value
+// is 0.
+type Decimal struct {
+	unscaled *big.Int
+	scale    int
+}
+
+// MustDecimal returns a Decimal parsed from s, which is useful for default
+// values. It panics if s is not a valid decimal.
+func MustDecimal(s string) Decimal {
+	d := Decimal{}
+	if err := d.Set(s); err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func (d *Decimal) Set(s string) error {
+	digits := strings.TrimSpace(s)
+	neg := false
+	if strings.HasPrefix(digits, "-") || strings.HasPrefix(digits, "+") {
+		neg = digits[0] == '-'
+		digits = digits[1:]
+	}
+	intPart, fracPart, _ := strings.Cut(digits, ".")
+	if intPart+fracPart == "" || !isDigits(intPart) || !isDigits(fracPart) {
+		return fmt.Errorf("invalid decimal %q", s)
+	}
+	unscaled, ok := new(big.Int).SetString(intPart+fracPart, 10)
+	if !ok {
+		return fmt.Errorf("invalid decimal %q", s)
+	}
+	if neg {
+		unscaled.Neg(unscaled)
+	}
+	d.unscaled = unscaled
+	d.scale = len(fracPart)
+	return nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns the decimal with as many fractional digits as it was
+// written with, e.g. "1.50".
+func (d Decimal) String() string {
+	unscaled := d.Unscaled()
+	digits := new(big.Int).Abs(unscaled).String()
+	if d.scale > 0 {
+		if len(digits) <= d.scale {
+			digits = strings.Repeat("0", d.scale-len(digits)+1) + digits
+		}
+		digits = digits[:len(digits)-d.scale] + "." + digits[len(digits)-d.scale:]
+	}
+	if unscaled.Sign() < 0 {
+		return "-" + digits
+	}
+	return digits
+}
+
+// Unscaled returns the decimal's digits as an integer, e.g. 150 for "1.50".
+func (d Decimal) Unscaled() *big.Int {
+	if d.unscaled == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Set(d.unscaled)
+}
+
+// Scale returns the number of fractional digits, e.g. 2 for "1.50".
+func (d Decimal) Scale() int {
+	return d.scale
+}
+
+// Rat returns the exact value of the decimal.
+func (d Decimal) Rat() *big.Rat {
+	denom := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(d.scale)), nil)
+	return new(big.Rat).SetFrac(d.Unscaled(), denom)
+}
+
+// Float64 returns the nearest float64 to the decimal.
+func (d Decimal) Float64() float64 {
+	f, _ := d.Rat().Float64()
+	return f
+}
+
+// Cmp compares d and other, returning -1, 0, or +1.
+func (d Decimal) Cmp(other Decimal) int {
+	return d.Rat().Cmp(other.Rat())
+}
+
+func (Decimal) Placeholder() string {
+	return "DECIMAL"
+}
@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecimal(t *testing.T) {
+	for _, tc := range []struct {
+		in       string
+		str      string
+		unscaled int64
+		scale    int
+	}{
+		{"19.99", "19.99", 1999, 2},
+		{"1.50", "1.50", 150, 2},
+		{"-0.05", "-0.05", -5, 2},
+		{"+7", "7", 7, 0},
+		{".5", "0.5", 5, 1},
+		{"3.", "3", 3, 0},
+	} {
+		var d Decimal
+		require.NoError(t, d.Set(tc.in), tc.in)
+		assert.Equal(t, tc.str, d.String(), tc.in)
+		assert.Equal(t, big.NewInt(tc.unscaled), d.Unscaled(), tc.in)
+		assert.Equal(t, tc.scale, d.Scale(), tc.in)
+	}
+
+	for _, in := range []string{"", ".", "1e5", "1,000", "0x10", "1.2.3", "NaN", "--1"} {
+		var d Decimal
+		err := d.Set(in)
+		require.Error(t, err, in)
+		assert.Contains(t, err.Error(), "invalid decimal")
+	}
+
+	var zero Decimal
+	assert.Equal(t, "0", zero.String())
+	assert.Equal(t, 0, zero.Cmp(MustDecimal("0.00")))
+
+	// 0.1 + 0.2 is exact, unlike with floats.
+	sum := new(big.Rat).Add(MustDecimal("0.1").Rat(), MustDecimal("0.2").Rat())
+	assert.Equal(t, 0, sum.Cmp(MustDecimal("0.3").Rat()))
+	assert.Equal(t, 0.3, MustDecimal("0.3").Float64())
+	assert.Equal(t, -1, MustDecimal("1.5").Cmp(MustDecimal("1.51")))
+}
+
+func TestDecimalField(t *testing.T) {
+	config := &struct {
+		Price Decimal
+	}{
+		Price: MustDecimal("9.90"),
+	}
+	cmd := New("test", config)
+	assert.Regexp(t, `--price <DECIMAL> +\(default: 9\.90\)`, cmd.HelpString())
+
+	require.NoError(t, cmd.ParseArgs([]string{"--price", "12.345"}).Err)
+	assert.Equal(t, "12.345", config.Price.String())
+
+	r := New("test", config).ParseArgs([]string{"--price", "1e3"})
+	require.Error(t, r.Err)
+	assert.Contains(t, r.Err.Error(), `invalid decimal "1e3"`)
+}
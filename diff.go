@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// FieldDiff describes a config field whose value differs between two
+// configs. See DiffConfig.
+type FieldDiff struct {
+	// Name is the flag name of the field.
+	Name string
+
+	// Old is the value of the field in the first config.
+	Old interface{}
+
+	// New is the value of the field in the second config.
+	New interface{}
+}
+
+// DiffConfig compares the fields of two configs of the same struct pointer
+// type, and returns a FieldDiff for each field whose value differs, in field
+// order. Only fields which would be handled by this package are compared. This
+// is useful for reporting which options changed, e.g. when reloading config in
+// a long-running process.
+func DiffConfig(a, b interface{}) ([]FieldDiff, error) {
+	return defaultCLI.DiffConfig(a, b)
+}
+
+// DiffConfig is like the package-level DiffConfig, but uses the CLI's settings
+// (e.g. FlagNameFunc) to derive field names.
+func (cli *CLI) DiffConfig(a, b interface{}) ([]FieldDiff, error) {
+	if reflect.TypeOf(a) != reflect.TypeOf(b) {
+		return nil, fmt.Errorf("cannot diff configs of different types: %T and %T", a, b)
+	}
+	fieldsA, _, err := cli.getFieldsFromConfig(a)
+	if err != nil {
+		return nil, err
+	}
+	fieldsB, _, err := cli.getFieldsFromConfig(b)
+	if err != nil {
+		return nil, err
+	}
+
+	diffs := []FieldDiff{}
+	for i := range fieldsA {
+		valA := fieldsA[i].value.target.Interface()
+		valB := fieldsB[i].value.target.Interface()
+		if !reflect.DeepEqual(valA, valB) {
+			diffs = append(diffs, FieldDiff{
+				Name: fieldsA[i].Name,
+				Old:  valA,
+				New:  valB,
+			})
+		}
+	}
+	return diffs, nil
+}
@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffConfig(t *testing.T) {
+	type Embedded struct {
+		Level int
+	}
+	type Cfg struct {
+		Embedded
+		Addr    string `cli:"name=listen"`
+		Timeout time.Duration
+		Tags    []string `cli:"append"`
+		Same    *string
+		Ignored string `cli:"-"`
+	}
+	s1, s2 := "same", "same"
+	a := &Cfg{
+		Embedded: Embedded{Level: 1},
+		Addr:     ":8080",
+		Timeout:  time.Second,
+		Tags:     []string{"a"},
+		Same:     &s1,
+		Ignored:  "a",
+	}
+	b := &Cfg{
+		Embedded: Embedded{Level: 2},
+		Addr:     ":8080",
+		Timeout:  time.Minute,
+		Tags:     []string{"a", "b"},
+		Same:     &s2,
+		Ignored:  "b",
+	}
+
+	diffs, err := DiffConfig(a, b)
+	require.NoError(t, err)
+	expected := []FieldDiff{
+		{Name: "level", Old: 1, New: 2},
+		{Name: "timeout", Old: time.Second, New: time.Minute},
+		{Name: "tags", Old: []string{"a"}, New: []string{"a", "b"}},
+	}
+	assert.Equal(t, expected, diffs)
+
+	diffs, err = DiffConfig(a, a)
+	require.NoError(t, err)
+	assert.Empty(t, diffs)
+
+	_, err = DiffConfig(a, &struct{}{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// warnUnknownEnvVars prints a warning to ErrWriter for each variable in
+// environ which starts with the CLI's EnvPrefix but does not match the env var
+// name of any field in the command tree.
+func (cmd *Command) warnUnknownEnvVars(environ []string) {
+	if cmd.cli.ErrWriter == nil {
+		return
+	}
+
+	root := cmd
+	for root.parent != nil {
+		root = root.parent
+	}
+	known := map[string]bool{}
+	root.walk(func(c *Command) {
+		for _, f := range c.fields {
+			if f.EnvVarName != "" {
+				known[f.EnvVarName] = true
+			}
+		}
+	})
+
+	unknown := []string{}
+	for _, kv := range environ {
+		key := strings.SplitN(kv, "=", 2)[0]
+		if strings.HasPrefix(key, cmd.cli.EnvPrefix) && !known[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	for _, key := range unknown {
+		fmt.Fprintf(cmd.cli.ErrWriter, "warning: unrecognized environment variable: %s\n", key)
+	}
+}
+
+// walk calls fn for the command and all of its subcommands, recursively.
+func (cmd *Command) walk(fn func(*Command)) {
+	fn(cmd)
+	for _, subCmd := range cmd.commands {
+		subCmd.walk(fn)
+	}
+}
@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAutoEnv(t *testing.T) {
+	type Cmd struct {
+		Timeout  int
+		LogLevel string
+		Explicit string `cli:"env=EXPLICIT"`
+	}
+	cli := NewCLI()
+	cli.AutoEnv = true
+	cli.EnvPrefix = "MYAPP_"
+
+	t.Setenv("MYAPP_TIMEOUT", "5")
+	t.Setenv("MYAPP_LOG_LEVEL", "debug")
+	t.Setenv("EXPLICIT", "foo")
+	cmd := &Cmd{}
+	r := cli.New("test", cmd).ParseArgs([]string{})
+	require.NoError(t, r.Err)
+	assert.Equal(t, &Cmd{Timeout: 5, LogLevel: "debug", Explicit: "foo"}, cmd)
+}
+
+func TestWarnUnknownEnv(t *testing.T) {
+	b := &strings.Builder{}
+	cli := NewCLI()
+	cli.ErrWriter = b
+	cli.EnvPrefix = "MYAPP_"
+	cli.WarnUnknownEnv = true
+
+	cmd := cli.New(
+		"test", &struct {
+			Timeout int `cli:"env"`
+		}{},
+		cli.New("sub", &struct {
+			Other string `cli:"env"`
+		}{}),
+	)
+	cmd.warnUnknownEnvVars([]string{
+		"MYAPP_TIMEOUT=5",
+		"MYAPP_OTHER=foo",
+		"MYAPP_TIMEOT=5",
+		"OTHERAPP_FOO=bar",
+		"MYAPP_EMPTY=",
+	})
+	assert.Equal(
+		t,
+		"warning: unrecognized environment variable: MYAPP_EMPTY\n"+
+			"warning: unrecognized environment variable: MYAPP_TIMEOT\n",
+		b.String(),
+	)
+}
@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"sync"
+	"time"
+)
+
+// now is used by caches to get the current time, and can be replaced in
+// tests.
+var now = time.Now
+
+type envCacheEntry struct {
+	val     string
+	ok      bool
+	expires time.Time
+}
+
+type envCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]envCacheEntry
+}
+
+func newEnvCache(ttl time.Duration) *envCache {
+	return &envCache{
+		ttl:     ttl,
+		entries: map[string]envCacheEntry{},
+	}
+}
+
+func (c *envCache) get(key string) (envCacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok {
+		return entry, false
+	}
+	if c.ttl > 0 && !now().Before(entry.expires) {
+		delete(c.entries, key)
+		return entry, false
+	}
+	return entry, true
+}
+
+func (c *envCache) set(key string, val string, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = envCacheEntry{
+		val:     val,
+		ok:      ok,
+		expires: now().Add(c.ttl),
+	}
+}
+
+// CacheLookupEnv wraps lookupEnv so that the result for each key is memoized
+// for ttl (or forever if ttl is zero). This is useful for expensive
+// LookupEnvFuncs, such as ones which call a remote secret store, since values
+// may be looked up repeatedly while parsing a command tree. Errors are not
+// cached.
+func CacheLookupEnv(lookupEnv LookupEnvFunc, ttl time.Duration) LookupEnvFunc {
+	cache := newEnvCache(ttl)
+	return func(key string) (string, bool, error) {
+		if entry, ok := cache.get(key); ok {
+			return entry.val, entry.ok, nil
+		}
+		val, ok, err := lookupEnv(key)
+		if err != nil {
+			return "", false, err
+		}
+		cache.set(key, val, ok)
+		return val, ok, nil
+	}
+}
+
+// CacheLookupEnvBatch is like CacheLookupEnv, but for LookupEnvBatchFuncs.
+// Only keys which aren't cached are passed to lookupEnvBatch.
+func CacheLookupEnvBatch(lookupEnvBatch LookupEnvBatchFunc, ttl time.Duration) LookupEnvBatchFunc {
+	cache := newEnvCache(ttl)
+	return func(keys []string) (map[string]string, error) {
+		vals := map[string]string{}
+		missing := []string{}
+		for _, key := range keys {
+			if entry, ok := cache.get(key); ok {
+				if entry.ok {
+					vals[key] = entry.val
+				}
+			} else {
+				missing = append(missing, key)
+			}
+		}
+		if len(missing) == 0 {
+			return vals, nil
+		}
+
+		fetched, err := lookupEnvBatch(missing)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range missing {
+			val, ok := fetched[key]
+			cache.set(key, val, ok)
+			if ok {
+				vals[key] = val
+			}
+		}
+		return vals, nil
+	}
+}
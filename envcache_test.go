@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setTestNow(t *testing.T, tm *time.Time) {
+	orig := now
+	now = func() time.Time { return *tm }
+	t.Cleanup(func() { now = orig })
+}
+
+func TestCacheLookupEnv(t *testing.T) {
+	tm := time.Unix(0, 0)
+	setTestNow(t, &tm)
+
+	calls := map[string]int{}
+	lookupEnv := CacheLookupEnv(func(key string) (string, bool, error) {
+		calls[key]++
+		if key == "ERR" {
+			return "", false, fmt.Errorf("failed")
+		}
+		return "val", key == "FOO", nil
+	}, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		val, ok, err := lookupEnv("FOO")
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, "val", val)
+
+		_, ok, err = lookupEnv("BAR")
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		_, _, err = lookupEnv("ERR")
+		require.Error(t, err)
+	}
+	assert.Equal(t, map[string]int{"FOO": 1, "BAR": 1, "ERR": 3}, calls)
+
+	tm = tm.Add(time.Minute)
+	lookupEnv("FOO")
+	assert.Equal(t, 2, calls["FOO"])
+}
+
+func TestCacheLookupEnvBatch(t *testing.T) {
+	tm := time.Unix(0, 0)
+	setTestNow(t, &tm)
+
+	batches := [][]string{}
+	lookupEnvBatch := CacheLookupEnvBatch(func(keys []string) (map[string]string, error) {
+		batches = append(batches, keys)
+		return map[string]string{"FOO": "foo"}, nil
+	}, 0)
+
+	vals, err := lookupEnvBatch([]string{"FOO", "BAR"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"FOO": "foo"}, vals)
+
+	tm = tm.Add(24 * time.Hour)
+	vals, err = lookupEnvBatch([]string{"FOO", "BAR", "BAZ"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"FOO": "foo"}, vals)
+	assert.Equal(t, [][]string{{"FOO", "BAR"}, {"BAZ"}}, batches)
+}
@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// parseEnvFiles sets any unset field values using the file referenced by the
+// "envfile" tag of the field, if present. This is useful in environments
+// where configuration is provided as mounted files rather than environment
+// variables, e.g. the Kubernetes downward API. Missing files are ignored.
+func (cmd *Command) parseEnvFiles() error {
+	if cmd.envDisabled() {
+		return nil
+	}
+	for _, f := range cmd.fields {
+		if f.EnvFile == "" || f.value.setCount > 0 || !cmd.fieldAvailable(f) {
+			continue
+		}
+		val, ok, err := readEnvFile(f.EnvFile)
+		if err != nil {
+			return err
+		}
+		if ok && val == "" && f.EnvNonEmpty {
+			return fmt.Errorf("%s is set but empty", f.EnvFile)
+		}
+		if ok {
+			if err := cmd.setFieldValue(f, val); err != nil {
+				return &FieldError{
+					Name: f.Name,
+					Err:  fmt.Errorf("error parsing %s: %w", f.EnvFile, err),
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// readEnvFile reads the value referenced by an envfile tag, which is either a
+// path (in which case the contents of the file, without any trailing newline,
+// are the value), or a path followed by "#" and a key (in which case the file
+// is expected to contain lines of the form key="value", like the labels and
+// annotations files written by the Kubernetes downward API). If the file or
+// key does not exist, ok is false.
+func readEnvFile(ref string) (val string, ok bool, err error) {
+	path, key := ref, ""
+	if i := strings.LastIndexByte(ref, '#'); i >= 0 {
+		path, key = ref[:i], ref[i+1:]
+	}
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	if key == "" {
+		return strings.TrimRight(string(b), "\r\n"), true, nil
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(string(b)))
+	for scanner.Scan() {
+		k, v, found := strings.Cut(scanner.Text(), "=")
+		if !found || strings.TrimSpace(k) != key {
+			continue
+		}
+		v = strings.TrimSpace(v)
+		if strings.HasPrefix(v, `"`) {
+			unquoted, err := strconv.Unquote(v)
+			if err != nil {
+				return "", false, fmt.Errorf("error parsing %s: %w", ref, err)
+			}
+			v = unquoted
+		}
+		return v, true, nil
+	}
+	return "", false, scanner.Err()
+}
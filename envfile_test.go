@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadEnvFile(t *testing.T) {
+	dir := t.TempDir()
+	labels := filepath.Join(dir, "labels")
+	require.NoError(t, os.WriteFile(labels, []byte("app=\"web\"\ntier=\"front\\\"end\"\nraw=value\n"), 0o644))
+	name := filepath.Join(dir, "name")
+	require.NoError(t, os.WriteFile(name, []byte("web-7d9f\n"), 0o644))
+
+	cases := []struct {
+		ref      string
+		expected string
+		ok       bool
+	}{
+		{labels + "#app", "web", true},
+		{labels + "#tier", `front"end`, true},
+		{labels + "#raw", "value", true},
+		{labels + "#missing", "", false},
+		{name, "web-7d9f", true},
+		{filepath.Join(dir, "missing"), "", false},
+	}
+	for _, c := range cases {
+		val, ok, err := readEnvFile(c.ref)
+		require.NoError(t, err, c.ref)
+		assert.Equal(t, c.ok, ok, c.ref)
+		assert.Equal(t, c.expected, val, c.ref)
+	}
+}
+
+func TestEnvFileTag(t *testing.T) {
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(t.TempDir()))
+	defer os.Chdir(wd)
+	require.NoError(t, os.WriteFile("labels", []byte("app=\"web\"\n"), 0o644))
+
+	type Config struct {
+		App     string `cli:"env=APP,envfile=labels#app"`
+		Missing string `cli:"envfile=missing"`
+	}
+	env := map[string]string{}
+	cli := CLI{
+		LookupEnv: func(key string) (string, bool, error) {
+			val, ok := env[key]
+			return val, ok, nil
+		},
+	}
+
+	config := &Config{Missing: "default"}
+	require.NoError(t, cli.New("test", config).ParseArgs([]string{}).Err)
+	assert.Equal(t, "web", config.App)
+	assert.Equal(t, "default", config.Missing)
+
+	// Environment variables take precedence over files.
+	env["APP"] = "from-env"
+	config = &Config{}
+	require.NoError(t, cli.New("test", config).ParseArgs([]string{}).Err)
+	assert.Equal(t, "from-env", config.App)
+
+	// Flags take precedence over both.
+	config = &Config{}
+	require.NoError(t, cli.New("test", config).ParseArgs([]string{"--app", "from-flag"}).Err)
+	assert.Equal(t, "from-flag", config.App)
+}
@@ -0,0 +1,55 @@
+// Command batch is an example of a batch processing tool which reads records
+// from stdin and writes formatted results to stdout, using typed flags for
+// validation before any work is done.
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/isobit/cli"
+)
+
+type Batch struct {
+	Lines  cli.IntRange `cli:"short=n,help=line numbers to process"`
+	Format cli.Template `cli:"help=template to format each line with (fields: .N and .Text)"`
+	Upper  bool         `cli:"help=convert lines to upper case"`
+}
+
+type line struct {
+	N    int
+	Text string
+}
+
+func (b *Batch) Run(ctx context.Context) error {
+	w := cli.Stdout(ctx)
+	scanner := bufio.NewScanner(cli.Stdin(ctx))
+	for n := 1; scanner.Scan(); n++ {
+		if b.Lines != (cli.IntRange{}) && !b.Lines.Contains(n) {
+			continue
+		}
+		text := scanner.Text()
+		if b.Upper {
+			text = strings.ToUpper(text)
+		}
+		if err := b.Format.Execute(w, line{N: n, Text: text}); err != nil {
+			return fmt.Errorf("line %d: %w", n, err)
+		}
+		fmt.Fprintln(w)
+	}
+	return scanner.Err()
+}
+
+func newCommand(c *cli.CLI) *cli.Command {
+	return c.New("batch", &Batch{
+		Format: cli.MustTemplate("{{.N}}: {{.Text}}"),
+	}, cli.WithHelp("format lines read from stdin"))
+}
+
+func main() {
+	newCommand(cli.NewCLI()).
+		Parse().
+		RunFatal()
+}
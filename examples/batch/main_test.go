@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/isobit/cli"
+)
+
+// testCLI returns a CLI which reads stdin from the given string, writes
+// everything to stdout (so that it is checked by examples), and prints exit
+// codes instead of exiting.
+func testCLI(stdin string) *cli.CLI {
+	c := cli.NewCLI()
+	c.HelpWriter = os.Stdout
+	c.ErrWriter = os.Stdout
+	c.Stdin = strings.NewReader(stdin)
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stdout
+	c.Exit = func(code int) {
+		if code != 0 {
+			fmt.Println("exit status", code)
+		}
+	}
+	return c
+}
+
+func Example() {
+	newCommand(testCLI("alpha\nbeta\ngamma\ndelta\n")).
+		ParseArgs([]string{"--lines", "2-3", "--upper"}).
+		RunFatal()
+	// Output:
+	// 2: BETA
+	// 3: GAMMA
+}
+
+func Example_format() {
+	newCommand(testCLI("alpha\nbeta\n")).
+		ParseArgs([]string{"--format", "{{.Text}} has {{len .Text}} letters"}).
+		RunFatal()
+	// Output:
+	// alpha has 5 letters
+	// beta has 4 letters
+}
+
+func Example_invalidFlags() {
+	// Flags are validated before any input is read.
+	newCommand(testCLI("")).
+		ParseArgs([]string{"--format", "{{.Text"}).
+		RunFatal()
+	newCommand(testCLI("")).
+		ParseArgs([]string{"--lines", "3-1"}).
+		RunFatal()
+	// Output:
+	// USAGE:
+	//     batch [OPTIONS]
+	//     batch help
+	//
+	// OPTIONS:
+	//     --format <VALUE>  template to format each line with (fields: .N and .Text)  (default: {{.N}}: {{.Text}})
+	//
+	// error: failed to parse args: invalid value "{{.Text" for flag format: invalid template at line 1: unclosed action
+	// exit status 1
+	// USAGE:
+	//     batch [OPTIONS]
+	//     batch help
+	//
+	// OPTIONS:
+	//     -n, --lines <MIN-MAX>  line numbers to process
+	//
+	// error: failed to parse args: invalid value "3-1" for flag lines: invalid range "3-1": max 1 is less than min 3
+	// exit status 1
+}
@@ -0,0 +1,58 @@
+// Command daemon is an example of a long-running service which listens on an
+// address given by the container conventions (--port or PORT), serves health
+// checks, and shuts down gracefully on SIGINT or SIGTERM.
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/isobit/cli"
+	"github.com/isobit/cli/healthopts"
+)
+
+type Daemon struct {
+	cli.ListenOptions
+	healthopts.Options
+
+	Message         string        `cli:"env=MESSAGE,help=message to respond to requests with"`
+	ShutdownTimeout time.Duration `cli:"help=how long to wait for requests to finish when shutting down"`
+}
+
+func (d *Daemon) Run(ctx context.Context) error {
+	ln, err := net.Listen("tcp", d.ListenAddr(8080))
+	if err != nil {
+		return err
+	}
+	srv := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, d.Message)
+		}),
+	}
+	go srv.Serve(ln)
+	fmt.Fprintln(cli.Stderr(ctx), "listening")
+
+	<-ctx.Done()
+	fmt.Fprintln(cli.Stderr(ctx), "shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), d.ShutdownTimeout)
+	defer cancel()
+	return srv.Shutdown(shutdownCtx)
+}
+
+func newCommand(c *cli.CLI) *cli.Command {
+	d := &Daemon{
+		Message:         "hello",
+		ShutdownTimeout: 10 * time.Second,
+	}
+	return c.Use(healthopts.Middleware(&d.Options)).
+		New("daemon", d, cli.WithHelp("serve a message over HTTP"))
+}
+
+func main() {
+	newCommand(cli.NewCLI()).
+		Parse().
+		RunFatalWithSigCancel()
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/isobit/cli"
+)
+
+// testCLI returns a CLI which writes everything to stdout (so that it is
+// checked by examples) and prints exit codes instead of exiting.
+func testCLI() *cli.CLI {
+	c := cli.NewCLI()
+	c.HelpWriter = os.Stdout
+	c.ErrWriter = os.Stdout
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stdout
+	c.LookupEnv = func(key string) (string, bool, error) { return "", false, nil }
+	c.Exit = func(code int) {
+		if code != 0 {
+			fmt.Println("exit status", code)
+		}
+	}
+	return c
+}
+
+func Example() {
+	// The context is cancelled after a short time, like it would be by
+	// SIGTERM when run with RunFatalWithSigCancel.
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	newCommand(testCLI()).
+		ParseArgs([]string{"--bind-addr", "127.0.0.1:0", "--message", "hi"}).
+		RunFatalWithContext(ctx)
+	// Output:
+	// listening
+	// shutting down
+}
+
+func Example_help() {
+	newCommand(testCLI()).
+		ParseArgs([]string{"--help"}).
+		RunFatal()
+	// Output:
+	// USAGE:
+	//     daemon [OPTIONS]
+	//     daemon help
+	//
+	// OPTIONS:
+	//     -h, --help                               show usage help
+	//     --bind-addr <HOST[:PORT]>   BIND_ADDR    address to listen on
+	//     --port <VALUE>              PORT         port to listen on (overrides the port in --bind-addr)  (default: 0)
+	//     --health-addr <VALUE>       HEALTH_ADDR  address to serve /livez and /readyz health checks on (e.g. :8081)
+	//     --message <VALUE>           MESSAGE      message to respond to requests with  (default: hello)
+	//     --shutdown-timeout <VALUE>               how long to wait for requests to finish when shutting down  (default: 10s)
+	//
+	// exit status 1
+}
@@ -0,0 +1,12 @@
+module github.com/isobit/cli/examples
+
+go 1.18
+
+require github.com/isobit/cli v0.0.0
+
+require (
+	github.com/huandu/xstrings v1.4.0 // indirect
+	gopkg.in/yaml.v3 v3.0.0 // indirect
+)
+
+replace github.com/isobit/cli => ../
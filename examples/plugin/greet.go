@@ -0,0 +1,28 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/isobit/cli"
+)
+
+func init() {
+	register("greet", func(c *cli.CLI, app *App) *cli.Command {
+		return c.New("greet", &greet{app: app}, cli.WithHelp("print a greeting"))
+	})
+}
+
+type greet struct {
+	app *App
+
+	Name string `cli:"required,short=n,help=name to greet"`
+}
+
+func (g *greet) Run(ctx context.Context) error {
+	if g.app.Verbose {
+		fmt.Fprintln(cli.Stderr(ctx), "greeting", g.Name)
+	}
+	fmt.Fprintf(cli.Stdout(ctx), "Hello, %s!\n", g.Name)
+	return nil
+}
@@ -0,0 +1,51 @@
+// Command plugin is an example of a plugin-style CLI, where subcommands are
+// defined in separate files (or packages) which register themselves, so that
+// adding a command doesn't require editing main.
+package main
+
+import (
+	"sort"
+
+	"github.com/isobit/cli"
+)
+
+// App is the config for the root command. Its fields are available to
+// plugins through the *App passed to their constructors.
+type App struct {
+	Verbose bool `cli:"short=v,help=enable verbose output"`
+}
+
+// A plugin constructs a subcommand given the CLI and root config.
+type plugin func(c *cli.CLI, app *App) *cli.Command
+
+var plugins = map[string]plugin{}
+
+// register registers a plugin; it is called from the init functions of
+// plugin files.
+func register(name string, p plugin) {
+	if _, ok := plugins[name]; ok {
+		panic("plugin already registered: " + name)
+	}
+	plugins[name] = p
+}
+
+func newCommand(c *cli.CLI) *cli.Command {
+	app := &App{}
+	cmd := c.New("plugin", app, cli.WithHelp("an example of a plugin-style CLI"))
+
+	names := make([]string, 0, len(plugins))
+	for name := range plugins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		cmd.AddCommand(plugins[name](c, app))
+	}
+	return cmd
+}
+
+func main() {
+	newCommand(cli.NewCLI()).
+		Parse().
+		RunFatal()
+}
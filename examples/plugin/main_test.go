@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/isobit/cli"
+)
+
+// testCLI returns a CLI which writes everything to stdout (so that it is
+// checked by examples) and prints exit codes instead of exiting.
+func testCLI() *cli.CLI {
+	c := cli.NewCLI()
+	c.HelpWriter = os.Stdout
+	c.ErrWriter = os.Stdout
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stdout
+	c.Exit = func(code int) {
+		if code != 0 {
+			fmt.Println("exit status", code)
+		}
+	}
+	return c
+}
+
+func Example() {
+	newCommand(testCLI()).
+		ParseArgs([]string{"-v", "greet", "--name", "world"}).
+		RunFatal()
+	// Output:
+	// greeting world
+	// Hello, world!
+}
+
+func Example_help() {
+	newCommand(testCLI()).
+		ParseArgs([]string{"--help"}).
+		RunFatal()
+	// Output:
+	// USAGE:
+	//     plugin [OPTIONS] <COMMAND>
+	//     plugin help [COMMAND...]
+	//
+	// OPTIONS:
+	//     -h, --help     show usage help
+	//     -v, --verbose  enable verbose output
+	//
+	// COMMANDS:
+	//     greet    print a greeting
+	//     version  print the version
+	//
+	// exit status 1
+}
+
+func Example_unknownCommand() {
+	newCommand(testCLI()).
+		ParseArgs([]string{"gret"}).
+		RunFatal()
+	// Output:
+	// USAGE:
+	//     plugin [OPTIONS] <COMMAND>
+	//     plugin help [COMMAND...]
+	//
+	// OPTIONS:
+	//     -h, --help     show usage help
+	//     -v, --verbose  enable verbose output
+	//
+	// COMMANDS:
+	//     greet    print a greeting
+	//     version  print the version
+	//
+	// error: unknown command: gret (did you mean greet?)
+	// exit status 1
+}
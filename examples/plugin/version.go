@@ -0,0 +1,19 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/isobit/cli"
+)
+
+var version = "v0.1.0"
+
+func init() {
+	register("version", func(c *cli.CLI, app *App) *cli.Command {
+		return c.NewFunc("version", func(ctx context.Context, args []string) error {
+			fmt.Fprintln(cli.Stdout(ctx), version)
+			return nil
+		}, cli.WithHelp("print the version"))
+	})
+}
@@ -0,0 +1,40 @@
+package cli
+
+import "sync"
+
+var atExit struct {
+	mu    sync.Mutex
+	funcs []func()
+}
+
+// AtExit registers fn to be called before RunFatal exits the process (or
+// before Main returns), so that resources such as log buffers, profiles, and
+// pidfiles are cleaned up even though os.Exit skips deferred functions.
+// Functions are called in the reverse order they were registered, like
+// deferred functions, and each is called at most once.
+func AtExit(fn func()) {
+	atExit.mu.Lock()
+	defer atExit.mu.Unlock()
+	atExit.funcs = append(atExit.funcs, fn)
+}
+
+// runAtExit calls and unregisters all functions registered with AtExit. If a
+// function panics, the remaining functions are still called before the panic
+// continues.
+func runAtExit() {
+	atExit.mu.Lock()
+	funcs := atExit.funcs
+	atExit.funcs = nil
+	atExit.mu.Unlock()
+
+	callReversed(funcs)
+}
+
+// callReversed calls funcs in reverse order. Deferring the calls (which run
+// last in, first out) ensures that all functions are called even if one
+// panics.
+func callReversed(funcs []func()) {
+	for _, fn := range funcs {
+		defer fn()
+	}
+}
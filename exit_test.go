@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseResultMain(t *testing.T) {
+	calls := []string{}
+	AtExit(func() { calls = append(calls, "first") })
+	AtExit(func() { calls = append(calls, "second") })
+
+	b := &strings.Builder{}
+	cli := CLI{ErrWriter: b}
+	cmd := cli.NewFunc("test", func(ctx context.Context, args []string) error {
+		return exitCodeError{fmt.Errorf("oops"), 3}
+	})
+	code := cmd.ParseArgs([]string{}).Main()
+	assert.Equal(t, 3, code)
+	assert.Equal(t, "error: oops\n", b.String())
+	assert.Equal(t, []string{"second", "first"}, calls)
+
+	// Functions are only called once.
+	code = cli.NewFunc("test", func(ctx context.Context, args []string) error {
+		return nil
+	}).ParseArgs([]string{}).Main()
+	assert.Equal(t, 0, code)
+	assert.Equal(t, []string{"second", "first"}, calls)
+}
+
+func TestRunFatalExit(t *testing.T) {
+	codes := []int{}
+	cli := CLI{
+		ErrWriter: &strings.Builder{},
+		Exit:      func(code int) { codes = append(codes, code) },
+	}
+	cli.NewFunc("test", func(ctx context.Context, args []string) error {
+		return exitCodeError{fmt.Errorf("oops"), 3}
+	}).ParseArgs([]string{}).RunFatal()
+	cli.NewFunc("test", func(ctx context.Context, args []string) error {
+		return nil
+	}).ParseArgs([]string{}).RunFatal()
+	assert.Equal(t, []int{3, 0}, codes)
+}
+
+func TestCallReversedPanic(t *testing.T) {
+	calls := []string{}
+	assert.Panics(t, func() {
+		callReversed([]func(){
+			func() { calls = append(calls, "first") },
+			func() { panic("oops") },
+			func() { calls = append(calls, "third") },
+		})
+	})
+	assert.Equal(t, []string{"third", "first"}, calls)
+}
+
+type exitCodeError struct {
+	error
+	code int
+}
+
+func (e exitCodeError) ExitCode() int {
+	return e.code
+}
@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+)
+
+// expandEnv replaces "${VAR}" sequences in s with the value of VAR, as
+// returned by lookupEnv. Unset variables expand to an empty string. "$$" is
+// replaced by a literal "$", and a "$" which is not followed by "{" or "$" is
+// left as-is.
+func expandEnv(s string, lookupEnv LookupEnvFunc) (string, error) {
+	if !strings.Contains(s, "$") {
+		return s, nil
+	}
+
+	sb := strings.Builder{}
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' || i+1 >= len(s) {
+			sb.WriteByte(s[i])
+			continue
+		}
+		switch s[i+1] {
+		case '$':
+			sb.WriteByte('$')
+			i++
+		case '{':
+			end := strings.IndexByte(s[i+2:], '}')
+			if end < 0 {
+				return "", fmt.Errorf("unterminated variable reference in %q", s)
+			}
+			key := s[i+2 : i+2+end]
+			if key == "" {
+				return "", fmt.Errorf("empty variable reference in %q", s)
+			}
+			if lookupEnv != nil {
+				val, _, err := lookupEnv(key)
+				if err != nil {
+					return "", err
+				}
+				sb.WriteString(val)
+			}
+			i += 2 + end
+		default:
+			sb.WriteByte(s[i])
+		}
+	}
+	return sb.String(), nil
+}
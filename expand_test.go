@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandEnv(t *testing.T) {
+	lookupEnv := func(key string) (string, bool, error) {
+		switch key {
+		case "HOME":
+			return "/home/foo", true, nil
+		case "EMPTY":
+			return "", true, nil
+		default:
+			return "", false, nil
+		}
+	}
+	cases := []struct {
+		in  string
+		out string
+	}{
+		{"", ""},
+		{"plain", "plain"},
+		{"${HOME}/reports", "/home/foo/reports"},
+		{"a${EMPTY}b${UNSET}c", "abc"},
+		{"$$HOME", "$HOME"},
+		{"$${HOME}", "${HOME}"},
+		{"cost: $5", "cost: $5"},
+		{"trailing$", "trailing$"},
+	}
+	for _, c := range cases {
+		out, err := expandEnv(c.in, lookupEnv)
+		require.NoError(t, err)
+		assert.Equal(t, c.out, out, c.in)
+	}
+
+	_, err := expandEnv("${HOME", lookupEnv)
+	assert.Error(t, err)
+	_, err = expandEnv("${}", lookupEnv)
+	assert.Error(t, err)
+}
+
+func TestCLIExpandEnv(t *testing.T) {
+	type Cmd struct {
+		Out   string
+		Other string `cli:"env=OTHER"`
+	}
+	cli := NewCLI()
+	cli.ExpandEnv = true
+	cli.LookupEnv = func(key string) (string, bool, error) {
+		switch key {
+		case "HOME":
+			return "/home/foo", true, nil
+		case "OTHER":
+			return "${HOME}/other", true, nil
+		default:
+			return "", false, nil
+		}
+	}
+	cmd := &Cmd{}
+	r := cli.New("test", cmd).ParseArgs([]string{"--out", "${HOME}/reports"})
+	require.NoError(t, r.Err)
+	assert.Equal(t, &Cmd{Out: "/home/foo/reports", Other: "/home/foo/other"}, cmd)
+}
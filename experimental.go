@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ExperimentalOptions can be embedded in a command config to add a standard
+// --enable-experimental flag, which enables experimental commands and flags
+// for the command and its subcommands (see Command.SetExperimental and the
+// experimental tag).
+type ExperimentalOptions struct {
+	EnableExperimental bool `cli:"name=enable-experimental,help=enable experimental commands and flags"`
+}
+
+func (o *ExperimentalOptions) experimentalEnabled() bool {
+	return o.EnableExperimental
+}
+
+type experimentaler interface {
+	experimentalEnabled() bool
+}
+
+// experimentalEnabled returns true if experimental commands and flags are
+// enabled for this command, either by the CLI's ExperimentalEnv variable or by
+// --enable-experimental being passed to this command or any of its parents.
+func (cmd *Command) experimentalEnabled() bool {
+	if cmd.cli.ExperimentalEnv != "" && !cmd.envDisabled() {
+		val, ok, err := cmd.lookupEnv(cmd.cli.ExperimentalEnv)
+		if err == nil && ok {
+			if enabled, err := strconv.ParseBool(val); err == nil && enabled {
+				return true
+			}
+		}
+	}
+	for c := cmd; c != nil; c = c.parent {
+		if e, ok := c.config.(experimentaler); ok && e.experimentalEnabled() {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldAvailable returns false if the field is experimental, and neither
+// experimental features nor the field's feature (see CLI.FeatureEnabled) are
+// enabled.
+func (cmd *Command) fieldAvailable(f field) bool {
+	return !f.Experimental || cmd.experimentalEnabled() || cmd.cli.featureEnabled(f.Feature)
+}
+
+// commandAvailable returns false if the subcommand is experimental, and
+// neither experimental features nor the feature named after the subcommand
+// (see CLI.FeatureEnabled) are enabled.
+func (cmd *Command) commandAvailable(subCmd *Command) bool {
+	return !subCmd.experimental || cmd.experimentalEnabled() || cmd.cli.featureEnabled(subCmd.name)
+}
+
+// checkExperimentalFields returns an error if any experimental fields were
+// set by argument while experimental features are not enabled.
+func (cmd *Command) checkExperimentalFields() error {
+	for _, f := range cmd.fields {
+		if f.value.setCount > 0 && !cmd.fieldAvailable(f) {
+			return &FieldError{
+				Name: f.Name,
+				Err:  fmt.Errorf("flag %s is experimental%s", f.Name, cmd.experimentalHint()),
+			}
+		}
+	}
+	return nil
+}
+
+// experimentalHint returns a description of how experimental features can be
+// enabled, for use in error messages.
+func (cmd *Command) experimentalHint() string {
+	hints := []string{}
+	for c := cmd; c != nil; c = c.parent {
+		if _, ok := c.config.(experimentaler); ok {
+			hints = append(hints, fmt.Sprintf("pass --enable-experimental to %s", c.fullName()))
+			break
+		}
+	}
+	if cmd.cli.ExperimentalEnv != "" {
+		hints = append(hints, fmt.Sprintf("set %s=true", cmd.cli.ExperimentalEnv))
+	}
+	if len(hints) == 0 {
+		return ""
+	}
+	return " (to enable, " + strings.Join(hints, " or ") + ")"
+}
@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type experimentalTestRoot struct {
+	ExperimentalOptions
+	NewOutput bool `cli:"experimental,help=use the new output format"`
+}
+
+type experimentalTestSub struct{}
+
+func (*experimentalTestSub) Run() error {
+	return nil
+}
+
+func newExperimentalTestCommand(cli *CLI) (*Command, *experimentalTestRoot) {
+	root := &experimentalTestRoot{}
+	return cli.New("test", root,
+		cli.New("preview", &experimentalTestSub{}, WithExperimental(true)),
+		cli.New("stable", &experimentalTestSub{}),
+	), root
+}
+
+func TestExperimentalDisabled(t *testing.T) {
+	c, _ := newExperimentalTestCommand(NewCLI())
+	help := c.HelpString()
+	assert.NotContains(t, help, "preview")
+	assert.NotContains(t, help, "new-output")
+	assert.Contains(t, help, "--enable-experimental")
+
+	err := c.ParseArgs([]string{"preview"}).Err
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "command preview is experimental (to enable, pass --enable-experimental to test)")
+
+	c, _ = newExperimentalTestCommand(NewCLI())
+	err = c.ParseArgs([]string{"--new-output", "stable"}).Err
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "flag new-output is experimental")
+}
+
+func TestExperimentalEnabledByFlag(t *testing.T) {
+	c, root := newExperimentalTestCommand(NewCLI())
+	r := c.ParseArgs([]string{"--new-output", "--enable-experimental", "preview"})
+	require.NoError(t, r.Err)
+	assert.True(t, root.NewOutput)
+	assert.Equal(t, "test preview", r.Command.FullName())
+
+	c, _ = newExperimentalTestCommand(NewCLI())
+	c.ParseArgs([]string{"--enable-experimental"})
+	help := c.HelpString()
+	assert.Regexp(t, `preview +\(experimental\)`, help)
+	assert.Regexp(t, `--new-output +use the new output format  \(experimental\)`, help)
+}
+
+func TestExperimentalEnabledByEnv(t *testing.T) {
+	t.Setenv("TEST_EXPERIMENTAL", "true")
+	cli := NewCLI()
+	cli.ExperimentalEnv = "TEST_EXPERIMENTAL"
+	c, _ := newExperimentalTestCommand(cli)
+	require.NoError(t, c.ParseArgs([]string{"preview"}).Err)
+
+	os.Unsetenv("TEST_EXPERIMENTAL")
+	c, _ = newExperimentalTestCommand(cli)
+	err := c.ParseArgs([]string{"preview"}).Err
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "or set TEST_EXPERIMENTAL=true")
+}
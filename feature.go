@@ -0,0 +1,30 @@
+package cli
+
+import (
+	"context"
+)
+
+type featuresContextKey struct{}
+
+// WithFeatures returns a copy of ctx in which FeatureEnabled calls
+// featureEnabled. ParseResult.Run does this automatically using
+// CLI.FeatureEnabled, if it is set.
+func WithFeatures(ctx context.Context, featureEnabled func(name string) bool) context.Context {
+	return context.WithValue(ctx, featuresContextKey{}, featureEnabled)
+}
+
+// FeatureEnabled returns true if the named feature is enabled according to
+// the function passed to WithFeatures (usually CLI.FeatureEnabled), or false
+// if there is none.
+func FeatureEnabled(ctx context.Context, name string) bool {
+	featureEnabled, ok := ctx.Value(featuresContextKey{}).(func(string) bool)
+	if !ok || featureEnabled == nil {
+		return false
+	}
+	return featureEnabled(name)
+}
+
+// featureEnabled calls FeatureEnabled if it is set.
+func (cli *CLI) featureEnabled(name string) bool {
+	return cli.FeatureEnabled != nil && cli.FeatureEnabled(name)
+}
@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type featureTestCmd struct {
+	Fast  bool `cli:"experimental=turbo"`
+	Other bool `cli:"experimental"`
+	turbo bool
+}
+
+func (cmd *featureTestCmd) Run(ctx context.Context) error {
+	cmd.turbo = FeatureEnabled(ctx, "turbo")
+	return nil
+}
+
+func TestFeatureEnabled(t *testing.T) {
+	enabled := map[string]bool{"turbo": true, "preview": true}
+	cli := NewCLI()
+	cli.FeatureEnabled = func(name string) bool {
+		return enabled[name]
+	}
+
+	cmd := &featureTestCmd{}
+	c := cli.New("test", &struct{}{},
+		cli.New("preview", cmd, WithExperimental(true)),
+		cli.New("hidden", &featureTestCmd{}, WithExperimental(true)),
+	)
+	help := c.HelpString()
+	assert.Contains(t, help, "preview")
+	assert.NotContains(t, help, "hidden")
+
+	err := c.ParseArgs([]string{"preview", "--fast"}).Run()
+	require.NoError(t, err)
+	assert.True(t, cmd.Fast)
+	assert.True(t, cmd.turbo)
+
+	c = cli.New("test", &featureTestCmd{})
+	r := c.ParseArgs([]string{"--other"})
+	require.Error(t, r.Err)
+	assert.Contains(t, r.Err.Error(), "flag other is experimental")
+
+	assert.False(t, FeatureEnabled(context.Background(), "turbo"))
+}
@@ -1,58 +1,222 @@
 package cli
 
 import (
+	"encoding/json"
 	"fmt"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/huandu/xstrings"
 )
 
 type field struct {
-	Name        string
-	ShortName   string
-	Help        string
-	Placeholder string
-	Required    bool
-	EnvVarName  string
-	HasArg      bool
-	Hidden      bool
+	Name      string
+	ShortName string
+	Aliases   []string
+	// DeprecatedAliases are like Aliases, but are not shown in help.
+	DeprecatedAliases []string
+	Help              string
+	LongHelp          string
+	Placeholder       string
+	Required          bool
+	EnvVarName        string
+	EnvNonEmpty       bool
+	EnvFile           string
+	ConfigFile        bool
+	HasArg            bool
+	Negatable         bool
+	Hidden            bool
+	Secret            bool
+	Experimental      bool
+	Feature           string
+	Meta              map[string]string
+
+	// Order is the field's position in help relative to other fields (see
+	// sortFields). hasOrder is true if it was set by an order tag on the
+	// field or a struct it is embedded in.
+	Order    int
+	hasOrder bool
 
 	value *fieldValue
 }
 
+// allAliases returns the field's aliases, including deprecated aliases.
+func (f field) allAliases() []string {
+	aliases := make([]string, 0, len(f.Aliases)+len(f.DeprecatedAliases))
+	aliases = append(aliases, f.Aliases...)
+	return append(aliases, f.DeprecatedAliases...)
+}
+
+// hasAlias returns true if name is one of the field's aliases.
+func (f field) hasAlias(name string) bool {
+	for _, alias := range f.allAliases() {
+		if alias == name {
+			return true
+		}
+	}
+	return false
+}
+
+// negatedName returns the name of the flag which sets a negatable bool field
+// to false.
+func (f field) negatedName() string {
+	return "no-" + f.Name
+}
+
 func (f field) Default() string {
 	return f.value.String()
 }
 
+// Unset returns true if the field is a pointer which is currently nil, in
+// which case help text should show the field as unset rather than showing a
+// zero value default.
+func (f field) Unset() bool {
+	return f.value.isNilPointer()
+}
+
+// argsField handles a command's positional arguments. Arguments are bound by
+// index to the fields with an "arg=N" tag, and any remaining arguments are
+// passed to setter, which sets the field with the "args" tag (if any).
 type argsField struct {
-	setter func([]string)
+	positionals []positionalArg
+	setter      func([]string)
+}
+
+// positionalArg is a field bound to a positional argument by an "arg=N" tag.
+type positionalArg struct {
+	Index    int
+	Name     string
+	Help     string
+	Required bool
+
+	value *fieldValue
+}
+
+func (p positionalArg) Default() string {
+	return p.value.String()
+}
+
+func (p positionalArg) field() field {
+	return field{
+		Name:     p.Name,
+		Help:     p.Help,
+		Required: p.Required,
+		value:    p.value,
+	}
+}
+
+// set sets the positional argument fields using setValue, and the args field
+// from the remaining args, returning an error if a required positional
+// argument is missing or if there are extra arguments and no args field to
+// put them in.
+func (a *argsField) set(args []string, setValue func(f field, s string) error) error {
+	for i, p := range a.positionals {
+		if i >= len(args) {
+			if p.Required {
+				return fmt.Errorf("missing required argument: %s", p.Name)
+			}
+			continue
+		}
+		if err := setValue(p.field(), args[i]); err != nil {
+			return fmt.Errorf("invalid value%s for argument %s: %v", p.value.quotedValue(args[i]), p.Name, err)
+		}
+	}
+	if len(args) <= len(a.positionals) {
+		return nil
+	}
+	rest := args[len(a.positionals):]
+	if a.setter == nil {
+		return fmt.Errorf("too many arguments (expected at most %d)", len(a.positionals))
+	}
+	a.setter(rest)
+	return nil
 }
 
 func (cli *CLI) getFieldsFromConfig(config interface{}) ([]field, *argsField, error) {
+	configElemVal, err := configStructValue(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	fields, argsField, err := cli.getFields(configElemVal)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := checkPositionals(argsField); err != nil {
+		return nil, nil, fmt.Errorf("problem with %s: %w", configElemVal.Type(), err)
+	}
+	cli.sortFields(fields)
+	return fields, argsField, nil
+}
+
+// sortFields sorts fields by their order tags (lowest first), and then by
+// name if SortFields is set. Otherwise, fields with the same order keep
+// their declaration order, with the fields of embedded structs in place of
+// the embedded struct.
+func (cli *CLI) sortFields(fields []field) {
+	sort.SliceStable(fields, func(i, j int) bool {
+		if fields[i].Order != fields[j].Order {
+			return fields[i].Order < fields[j].Order
+		}
+		if cli.SortFields {
+			return fields[i].Name < fields[j].Name
+		}
+		return false
+	})
+}
+
+// checkPositionals sorts the positional argument fields by index, and checks
+// that their indexes start at 0 without gaps and that required arguments
+// don't follow optional ones.
+func checkPositionals(a *argsField) error {
+	if a == nil {
+		return nil
+	}
+	sort.SliceStable(a.positionals, func(i, j int) bool {
+		return a.positionals[i].Index < a.positionals[j].Index
+	})
+	for i, p := range a.positionals {
+		if p.Index != i {
+			if p.Index < i {
+				return fmt.Errorf("multiple fields defined for arg=%d", p.Index)
+			}
+			return fmt.Errorf("no field defined for arg=%d", i)
+		}
+		if i > 0 && p.Required && !a.positionals[i-1].Required {
+			return fmt.Errorf("required argument %s cannot follow optional argument %s", p.Name, a.positionals[i-1].Name)
+		}
+	}
+	return nil
+}
+
+// configStructValue returns the reflected struct value pointed to by config,
+// or an error if config is not a struct pointer.
+func configStructValue(config interface{}) (reflect.Value, error) {
 	configVal := reflect.ValueOf(config)
 	if !configVal.IsValid() {
-		return nil, nil, fmt.Errorf("invalid config value")
+		return reflect.Value{}, fmt.Errorf("invalid config value")
 	}
 	if configVal.Kind() != reflect.Ptr {
-		return nil, nil, fmt.Errorf("config must be a struct pointer (got %s)", configVal.Type())
+		return reflect.Value{}, fmt.Errorf("config must be a struct pointer (got %s)", configVal.Type())
 	}
 
 	configElemVal := configVal.Elem()
 	if !configElemVal.IsValid() {
-		return nil, nil, fmt.Errorf("invalid config element value")
+		return reflect.Value{}, fmt.Errorf("invalid config element value")
 	}
 	if configElemVal.Kind() != reflect.Struct {
-		return nil, nil, fmt.Errorf("config must be a struct pointer (got %s)", configVal.Type())
+		return reflect.Value{}, fmt.Errorf("config must be a struct pointer (got %s)", configVal.Type())
 	}
 
-	return cli.getFields(configElemVal)
+	return configElemVal, nil
 }
 
 // sv must be a reflected struct pointer element
 func (cli *CLI) getFields(sv reflect.Value) ([]field, *argsField, error) {
 	fields := []field{}
-	var argsField *argsField
+	args := &argsField{}
+	hasArgs := false
 	for i := 0; i < sv.NumField(); i++ {
 		sf := sv.Type().Field(i)
 		val := sv.Field(i)
@@ -62,7 +226,7 @@ func (cli *CLI) getFields(sv reflect.Value) ([]field, *argsField, error) {
 			continue
 		}
 
-		meta, err := newFieldValueMeta(sf, val)
+		meta, err := newFieldValueMeta(sf, val, cli.ExtensionTagPrefixes)
 		if err != nil {
 			return nil, nil, fmt.Errorf("problem with field %s.%s: %w", sv.Type(), sf.Name, err)
 		}
@@ -78,16 +242,36 @@ func (cli *CLI) getFields(sv reflect.Value) ([]field, *argsField, error) {
 			if err != nil {
 				return nil, nil, err
 			}
+			if meta.tags.hasOrder {
+				for i := range embeddedFields {
+					if !embeddedFields[i].hasOrder {
+						embeddedFields[i].Order = meta.tags.order
+						embeddedFields[i].hasOrder = true
+					}
+				}
+			}
 			fields = append(fields, embeddedFields...)
-			if argsField == nil {
-				argsField = embeddedArgsField
+			if embeddedArgsField != nil {
+				hasArgs = true
+				args.positionals = append(args.positionals, embeddedArgsField.positionals...)
+				if args.setter == nil {
+					args.setter = embeddedArgsField.setter
+				}
 			}
 		} else if meta.tags.args {
-			field, err := cli.getArgsField(meta)
+			setter, err := cli.getArgsSetter(meta)
+			if err != nil {
+				return nil, nil, fmt.Errorf("problem with field %s.%s: %w", sv.Type(), sf.Name, err)
+			}
+			hasArgs = true
+			args.setter = setter
+		} else if meta.tags.arg >= 0 {
+			p, err := cli.getPositionalArg(meta)
 			if err != nil {
 				return nil, nil, fmt.Errorf("problem with field %s.%s: %w", sv.Type(), sf.Name, err)
 			}
-			argsField = &field
+			hasArgs = true
+			args.positionals = append(args.positionals, p)
 		} else {
 			field, err := cli.getField(meta)
 			if err != nil {
@@ -96,46 +280,142 @@ func (cli *CLI) getFields(sv reflect.Value) ([]field, *argsField, error) {
 			fields = append(fields, field)
 		}
 	}
-	return fields, argsField, nil
+	if !hasArgs {
+		return fields, nil, nil
+	}
+	return fields, args, nil
 }
 
 func (cli *CLI) getField(meta fieldValueMeta) (field, error) {
 	name := meta.tags.name
+	if name == "" {
+		name = cli.fallbackTagName(meta.structField)
+	}
+	if name == "" && cli.FlagNameFunc != nil {
+		name = cli.FlagNameFunc(meta.structField.Name)
+	}
 	if name == "" {
 		name = xstrings.ToKebabCase(meta.structField.Name)
 	}
 
+	// An empty env tag only derives a name from the flag name if the CLI
+	// has opted in to derived names; otherwise it has no effect.
+	deriveEnv := cli.AutoEnv || (meta.tags.envFromName && (cli.EnvPrefix != "" || len(cli.FallbackNameTags) > 0))
+	envVarName := meta.tags.env
+	if envVarName == "" && deriveEnv {
+		envVarName = cli.EnvPrefix + strings.ToUpper(xstrings.ToSnakeCase(name))
+	}
+
+	placeholder := meta.tags.placeholder
+	if placeholder == "" && meta.tags.kv {
+		placeholder = "KEY" + meta.tags.kvSeparator + "VALUE"
+	} else if placeholder == "" && meta.tags.json {
+		placeholder = "JSON"
+	} else if placeholder == "" && meta.tags.yaml {
+		placeholder = "YAML"
+	} else if placeholder == "" {
+		placeholder = typePlaceholder(meta.value.Type(), meta.tags.append)
+	}
+
+	feature := meta.tags.feature
+	if meta.tags.experimental && feature == "" {
+		feature = name
+	}
+
 	fieldValue, err := cli.getFieldValue(name, meta)
 	if err != nil {
 		return field{}, fmt.Errorf("not supported: %w", err)
 	}
 
+	if meta.tags.negatable && !fieldValue.isBoolFlag {
+		return field{}, fmt.Errorf("field has negatable tag but is not a bool")
+	}
+
+	if meta.tags.configFile {
+		t := meta.value.Type()
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.String {
+			return field{}, fmt.Errorf("field has config tag but is not a string")
+		}
+	}
+
 	return field{
-		Name:        name,
-		ShortName:   meta.tags.short,
-		Help:        meta.tags.help,
-		Placeholder: meta.tags.placeholder,
-		Required:    meta.tags.required,
-		EnvVarName:  meta.tags.env,
-		HasArg:      !fieldValue.isBoolFlag,
-		Hidden:      meta.tags.hidden,
-		value:       fieldValue,
+		Name:              name,
+		ShortName:         meta.tags.short,
+		Aliases:           meta.tags.aliases,
+		DeprecatedAliases: meta.tags.deprecatedAliases,
+		Help:              meta.tags.help,
+		LongHelp:          meta.tags.longHelp,
+		Placeholder:       placeholder,
+		Required:          meta.tags.required,
+		EnvVarName:        envVarName,
+		EnvNonEmpty:       meta.tags.envNonEmpty,
+		EnvFile:           meta.tags.envFile,
+		ConfigFile:        meta.tags.configFile,
+		HasArg:            !fieldValue.isBoolFlag,
+		Negatable:         fieldValue.isBoolFlag && (meta.tags.negatable || cli.NegatableFlags),
+		Hidden:            meta.tags.hidden,
+		Secret:            meta.tags.secret,
+		Experimental:      meta.tags.experimental,
+		Feature:           feature,
+		Meta:              meta.tags.extensions,
+		Order:             meta.tags.order,
+		hasOrder:          meta.tags.hasOrder,
+		value:             fieldValue,
 	}, nil
 }
 
-func (cli *CLI) getArgsField(meta fieldValueMeta) (argsField, error) {
+// fallbackTagName returns the name from the first of the CLI's
+// FallbackNameTags which is present on the struct field, or an empty string if
+// there is none.
+func (cli *CLI) fallbackTagName(sf reflect.StructField) string {
+	for _, key := range cli.FallbackNameTags {
+		name := strings.SplitN(sf.Tag.Get(key), ",", 2)[0]
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+	return ""
+}
+
+func (cli *CLI) getArgsSetter(meta fieldValueMeta) (func([]string), error) {
 	val := meta.value
 	if !val.CanAddr() {
-		return argsField{}, fmt.Errorf("field has an args tag but type is not a slice of strings")
+		return nil, fmt.Errorf("field has an args tag but type is not a slice of strings")
 	}
 	slicePointer, ok := val.Addr().Interface().(*[]string)
 	if !ok {
-		return argsField{}, fmt.Errorf("field has an args tag but type is not a slice of strings")
+		return nil, fmt.Errorf("field has an args tag but type is not a slice of strings")
 	}
-	return argsField{
-		setter: func(args []string) {
-			*slicePointer = args
-		},
+	return func(args []string) {
+		*slicePointer = args
+	}, nil
+}
+
+// getPositionalArg returns a positionalArg for a field with an "arg=N" tag.
+// Its name, as shown in usage, is the upper snake case flag name (e.g.
+// "SRC") unless set using the placeholder tag.
+func (cli *CLI) getPositionalArg(meta fieldValueMeta) (positionalArg, error) {
+	name := meta.tags.name
+	if name == "" {
+		name = xstrings.ToKebabCase(meta.structField.Name)
+	}
+	displayName := meta.tags.placeholder
+	if displayName == "" {
+		displayName = strings.ToUpper(xstrings.ToSnakeCase(name))
+	}
+	fieldValue, err := cli.getFieldValue(name, meta)
+	if err != nil {
+		return positionalArg{}, fmt.Errorf("not supported: %w", err)
+	}
+	return positionalArg{
+		Index:    meta.tags.arg,
+		Name:     displayName,
+		Help:     meta.tags.help,
+		Required: meta.tags.required,
+		value:    fieldValue,
 	}, nil
 }
 
@@ -146,8 +426,8 @@ type fieldValueMeta struct {
 	tags        fieldTags
 }
 
-func newFieldValueMeta(structField reflect.StructField, value reflect.Value) (fieldValueMeta, error) {
-	tags, err := parseFieldTags(structField.Tag)
+func newFieldValueMeta(structField reflect.StructField, value reflect.Value, extensionTagPrefixes []string) (fieldValueMeta, error) {
+	tags, err := parseFieldTags(structField.Tag, extensionTagPrefixes)
 	if err != nil {
 		return fieldValueMeta{}, err
 	}
@@ -162,23 +442,53 @@ func newFieldValueMeta(structField reflect.StructField, value reflect.Value) (fi
 }
 
 type fieldTags struct {
-	exclude       bool
-	required      bool
-	name          string
-	short         string
-	placeholder   string
-	env           string
-	help          string
-	defaultString string
-	hideDefault   bool
-	hidden        bool
-	append        bool
-	args          bool
-}
-
-func parseFieldTags(tag reflect.StructTag) (fieldTags, error) {
-	t := fieldTags{}
+	exclude           bool
+	required          bool
+	name              string
+	short             string
+	aliases           []string
+	deprecatedAliases []string
+	placeholder       string
+	env               string
+	envFromName       bool
+	envNonEmpty       bool
+	envFile           string
+	configFile        bool
+	negatable         bool
+	help              string
+	longHelp          string
+	defaultString     string
+	hideDefault       bool
+	hidden            bool
+	secret            bool
+	experimental      bool
+	feature           string
+	append            bool
+	kv                bool
+	kvSeparator       string
+	json              bool
+	yaml              bool
+	args              bool
+	arg               int
+	order             int
+	hasOrder          bool
+
+	// raw contains all of the key-value pairs in the cli tag, as parsed by
+	// parseStructTagInner.
+	raw map[string]string
+
+	// extensions contains any unknown key-value pairs which matched one of
+	// the allowed extension tag prefixes.
+	extensions map[string]string
+}
+
+func parseFieldTags(tag reflect.StructTag, extensionTagPrefixes []string) (fieldTags, error) {
+	t := fieldTags{arg: -1}
 	m := parseStructTagInner(tag.Get("cli"))
+	t.raw = make(map[string]string, len(m))
+	for k, v := range m {
+		t.raw[k] = v
+	}
 	pop := func(key string) (string, bool) {
 		val, ok := m[key]
 		if ok {
@@ -206,18 +516,57 @@ func parseFieldTags(tag reflect.StructTag) (fieldTags, error) {
 		t.short = short
 	}
 
+	if s, ok := pop("alias"); ok {
+		aliases, err := parseAliases(s)
+		if err != nil {
+			return t, err
+		}
+		t.aliases = aliases
+	}
+
+	if s, ok := pop("deprecated-alias"); ok {
+		aliases, err := parseAliases(s)
+		if err != nil {
+			return t, err
+		}
+		t.deprecatedAliases = aliases
+	}
+
 	if placeholder, ok := pop("placeholder"); ok {
 		t.placeholder = placeholder
 	}
 
 	if env, ok := pop("env"); ok {
 		t.env = env
+		if env == "" {
+			t.envFromName = true
+		}
+	}
+
+	if _, ok := pop("env-nonempty"); ok {
+		t.envNonEmpty = true
+	}
+
+	if envFile, ok := pop("envfile"); ok {
+		t.envFile = envFile
+	}
+
+	if _, ok := pop("config"); ok {
+		t.configFile = true
+	}
+
+	if _, ok := pop("negatable"); ok {
+		t.negatable = true
 	}
 
 	if help, ok := pop("help"); ok {
 		t.help = help
 	}
 
+	if longHelp, ok := pop("longhelp"); ok {
+		t.longHelp = longHelp
+	}
+
 	if defaultString, ok := pop("default"); ok {
 		t.defaultString = defaultString
 		if defaultString == "" {
@@ -232,14 +581,70 @@ func parseFieldTags(tag reflect.StructTag) (fieldTags, error) {
 		t.append = true
 	}
 
+	if _, ok := pop("json"); ok {
+		t.json = true
+	}
+
+	if _, ok := pop("yaml"); ok {
+		t.yaml = true
+	}
+
+	if sep, ok := pop("kv"); ok {
+		t.kv = true
+		t.kvSeparator = sep
+		if sep == "" {
+			t.kvSeparator = "="
+		}
+	}
+
 	if _, ok := pop("hidden"); ok {
 		t.hidden = true
 	}
 
+	if _, ok := pop("secret"); ok {
+		t.secret = true
+		t.hideDefault = true
+	}
+
+	if feature, ok := pop("experimental"); ok {
+		t.experimental = true
+		t.feature = feature
+	}
+
 	if _, ok := pop("args"); ok {
 		t.args = true
 	}
 
+	if order, ok := pop("order"); ok {
+		i, err := strconv.Atoi(order)
+		if err != nil {
+			return t, fmt.Errorf("order must be an integer")
+		}
+		t.order = i
+		t.hasOrder = true
+	}
+
+	if arg, ok := pop("arg"); ok {
+		i, err := strconv.Atoi(arg)
+		if err != nil || i < 0 {
+			return t, fmt.Errorf("arg must be a non-negative integer index")
+		}
+		t.arg = i
+	}
+
+	for k, v := range m {
+		for _, prefix := range extensionTagPrefixes {
+			if strings.HasPrefix(k, prefix) {
+				if t.extensions == nil {
+					t.extensions = map[string]string{}
+				}
+				t.extensions[k] = v
+				delete(m, k)
+				break
+			}
+		}
+	}
+
 	if len(m) > 0 {
 		i := 0
 		keys := make([]string, len(m))
@@ -253,6 +658,18 @@ func parseFieldTags(tag reflect.StructTag) (fieldTags, error) {
 	return t, nil
 }
 
+// parseAliases parses the value of an alias or deprecated-alias tag, which
+// is one or more aliases separated by "|".
+func parseAliases(s string) ([]string, error) {
+	aliases := strings.Split(s, "|")
+	for _, alias := range aliases {
+		if len(alias) < 2 {
+			return nil, fmt.Errorf("alias must be more than 1 letter (use short instead)")
+		}
+	}
+	return aliases, nil
+}
+
 func (cli *CLI) getFieldValue(name string, meta fieldValueMeta) (*fieldValue, error) {
 	val := meta.value
 
@@ -291,6 +708,29 @@ func (cli *CLI) getFieldValue(name string, meta fieldValueMeta) (*fieldValue, er
 	var set Setter
 	var str stringer
 
+	// Fields with the kv tag are structs with Key and Value fields, which
+	// are set by splitting values on the separator.
+	if meta.tags.kv {
+		kv, err := newKVSetter(val, meta.tags.kvSeparator)
+		if err != nil {
+			return nil, err
+		}
+		set = kv
+	}
+
+	// Fields with the json tag are decoded from inline JSON or a file.
+	if meta.tags.json {
+		set = newDecodeSetter(val, decodeJSON)
+		str = encodeStringer{meta.value.Interface(), json.Marshal}
+	}
+
+	// Fields with the yaml tag are decoded from inline YAML or a file. Since
+	// JSON is valid YAML, defaults are shown as (single line) JSON.
+	if meta.tags.yaml {
+		set = newDecodeSetter(val, decodeYAML)
+		str = encodeStringer{meta.value.Interface(), json.Marshal}
+	}
+
 	// Interfaces might be implemented using value or pointer receivers, so
 	// we'll try both if we can take an address.
 	interfaceables := []interface{}{val.Interface()}
@@ -316,6 +756,8 @@ func (cli *CLI) getFieldValue(name string, meta fieldValueMeta) (*fieldValue, er
 		str = staticStringer(meta.tags.defaultString)
 	} else if meta.tags.hideDefault {
 		str = staticStringer("")
+	} else if str == nil && meta.tags.kv {
+		str = kvStringer{reflect.ValueOf(meta.value.Interface()), meta.tags.kvSeparator}
 	} else if str == nil {
 		str = sprintfStringer{meta.value.Interface()}
 	}
@@ -348,17 +790,111 @@ func (cli *CLI) getFieldValue(name string, meta fieldValueMeta) (*fieldValue, er
 		}
 	}
 
+	// Help should render nil pointers as unset, unless the default has been
+	// overridden by tags.
+	showUnset := meta.tags.defaultString == "" && !meta.tags.hideDefault
+
 	return &fieldValue{
 		Setter:     set,
 		stringer:   str,
-		isBoolFlag: meta.value.Kind() == reflect.Bool,
+		isBoolFlag: isBoolType(meta.value.Type()),
+		target:     meta.value,
+		showUnset:  showUnset,
+		name:       name,
+		tags:       meta.tags.raw,
+		redact:     typeRedacter(meta.value.Type(), meta.tags.append),
 	}, nil
 }
 
+// typeRedacter returns the RedactValue method of t (or the element type of t,
+// for fields with the append tag) if it implements ValueRedacter.
+func typeRedacter(t reflect.Type, isAppend bool) func(string) string {
+	if isAppend && t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if r, ok := reflect.New(t).Interface().(ValueRedacter); ok {
+		return r.RedactValue
+	}
+	return nil
+}
+
+// typePlaceholder returns the placeholder provided by t (or the element type
+// of t, for fields with the append tag) if it implements Placeholderer.
+func typePlaceholder(t reflect.Type, isAppend bool) string {
+	if isAppend && t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if p, ok := reflect.New(t).Interface().(Placeholderer); ok {
+		return p.Placeholder()
+	}
+	return ""
+}
+
+// isBoolType returns true if t is a bool or a pointer to a bool. Pointers to
+// bools are treated as bool flags which leave the pointer nil unless the flag
+// is passed, giving tri-state (unset, true, false) semantics.
+func isBoolType(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Bool
+}
+
 type Setter interface {
 	Set(s string) error
 }
 
+// ValueRedacter can be implemented by value types whose values may contain
+// credentials (e.g. URLs with passwords) to provide the form of a value which
+// is shown in parse errors. It may be implemented with a value or pointer
+// receiver. Values of fields with the secret tag are never shown.
+type ValueRedacter interface {
+	RedactValue(s string) string
+}
+
+// Placeholderer can be implemented by value types to provide the default
+// placeholder shown for their flags in help text, e.g. "MIN-MAX". It may be
+// implemented with a value or pointer receiver, and is overridden by the
+// placeholder tag.
+type Placeholderer interface {
+	Placeholder() string
+}
+
+// ContextSetter can be implemented by values (or by Setters returned from a
+// SetterFunc) which need information about the field being set. If a value
+// implements ContextSetter, SetWithContext will be called instead of Set.
+type ContextSetter interface {
+	SetWithContext(ctx SetterContext, s string) error
+}
+
+// SetterContext contains information about the field being set which is
+// passed to ContextSetters.
+type SetterContext struct {
+	// FieldName is the flag name of the field being set.
+	FieldName string
+
+	// Tags contains all of the key-value pairs in the field's cli struct tag.
+	Tags map[string]string
+
+	// SetCount is the number of times the field has previously been set.
+	SetCount uint
+}
+
+// setWithContext calls SetWithContext if the setter implements ContextSetter,
+// otherwise it falls back on calling Set.
+func setWithContext(set Setter, ctx SetterContext, s string) error {
+	if cs, ok := set.(ContextSetter); ok {
+		return cs.SetWithContext(ctx, s)
+	}
+	return set.Set(s)
+}
+
 type pointerSetter struct {
 	setter           Setter
 	targetValue      reflect.Value
@@ -366,8 +902,12 @@ type pointerSetter struct {
 }
 
 func (ps pointerSetter) Set(s string) error {
+	return ps.SetWithContext(SetterContext{}, s)
+}
+
+func (ps pointerSetter) SetWithContext(ctx SetterContext, s string) error {
 	// Try to set the placeholder.
-	if err := ps.setter.Set(s); err != nil {
+	if err := setWithContext(ps.setter, ctx, s); err != nil {
 		return err
 	}
 
@@ -385,8 +925,12 @@ type appendSliceSetter struct {
 }
 
 func (rss appendSliceSetter) Set(s string) error {
+	return rss.SetWithContext(SetterContext{}, s)
+}
+
+func (rss appendSliceSetter) SetWithContext(ctx SetterContext, s string) error {
 	// Try to set the placeholder.
-	if err := rss.setter.Set(s); err != nil {
+	if err := setWithContext(rss.setter, ctx, s); err != nil {
 		return err
 	}
 
@@ -411,15 +955,55 @@ type fieldValue struct {
 	stringer
 	isBoolFlag bool
 	setCount   uint
+	target     reflect.Value
+	showUnset  bool
+	name       string
+	tags       map[string]string
+
+	// redact, if set, returns the form of a value to show in errors.
+	redact func(string) string
+
+	// onSet, if set, is called whenever the value is set, e.g. to invalidate
+	// cached help text which shows it as the default.
+	onSet func()
+}
+
+// quotedValue returns s quoted for use in parse errors, redacted using the
+// type's ValueRedacter if it has one, or an empty string if the field is
+// secret.
+func (f *fieldValue) quotedValue(s string) string {
+	if _, ok := f.tags["secret"]; ok {
+		return ""
+	}
+	if f.redact != nil {
+		s = f.redact(s)
+	}
+	return fmt.Sprintf(" %q", s)
+}
+
+func (f *fieldValue) isNilPointer() bool {
+	return f.showUnset && f.target.Kind() == reflect.Ptr && f.target.IsNil()
 }
 
 func (f *fieldValue) Set(s string) error {
 	if f.Setter == nil {
 		panic("cli: fieldValue has no setter, this should not happen")
 	}
+	ctx := f.setterContext()
 	f.setCount += 1
-	if err := f.Setter.Set(s); err != nil {
+	if f.onSet != nil {
+		f.onSet()
+	}
+	if err := setWithContext(f.Setter, ctx, s); err != nil {
 		return err
 	}
 	return nil
 }
+
+func (f *fieldValue) setterContext() SetterContext {
+	return SetterContext{
+		FieldName: f.name,
+		Tags:      f.tags,
+		SetCount:  f.setCount,
+	}
+}
@@ -1,8 +1,11 @@
 package cli
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/huandu/xstrings"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -93,3 +96,224 @@ func TestFieldAppend(t *testing.T) {
 		assert.EqualValues(t, []*int{i(1), i(2), i(3)}, cfg.Vars)
 	})
 }
+
+type recordingSetter struct {
+	calls []SetterContext
+	vals  []string
+}
+
+func (rs *recordingSetter) SetWithContext(ctx SetterContext, s string) error {
+	rs.calls = append(rs.calls, ctx)
+	rs.vals = append(rs.vals, s)
+	return nil
+}
+
+func TestFieldContextSetter(t *testing.T) {
+	cfg := struct {
+		Rec recordingSetter `cli:"short=r,help=foo"`
+	}{}
+	c := New("test", &cfg)
+	r := c.ParseArgs([]string{"-r", "a", "--rec", "b"})
+	require.NoError(t, r.Err)
+
+	assert.Equal(t, []string{"a", "b"}, cfg.Rec.vals)
+	require.Len(t, cfg.Rec.calls, 2)
+	for i, ctx := range cfg.Rec.calls {
+		assert.Equal(t, "rec", ctx.FieldName)
+		assert.Equal(t, "foo", ctx.Tags["help"])
+		assert.Equal(t, uint(i), ctx.SetCount)
+	}
+}
+
+func TestFieldExtensionTags(t *testing.T) {
+	type Cfg struct {
+		Foo string `cli:"short=f,x-group=net,x-secret"`
+	}
+
+	_, err := Build("test", &Cfg{})
+	assert.Error(t, err)
+
+	cli := NewCLI().AllowExtensionTags("x-")
+	cmd, err := cli.Build("test", &Cfg{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"x-group": "net", "x-secret": ""}, cmd.FieldMeta("foo"))
+	assert.Equal(t, cmd.FieldMeta("foo"), cmd.FieldMeta("f"))
+	assert.Nil(t, cmd.FieldMeta("help"))
+
+	type OtherCfg struct {
+		Foo string `cli:"y-group=net"`
+	}
+	_, err = cli.Build("test", &OtherCfg{})
+	assert.Error(t, err)
+}
+
+func TestFieldFallbackNameTags(t *testing.T) {
+	type Cfg struct {
+		ListenAddr string `json:"listen_addr,omitempty" yaml:"listenAddr" cli:"env"`
+		LogLevel   string `yaml:"log_level"`
+		Override   string `json:"override" cli:"name=explicit"`
+		Ignored    string `json:"-"`
+		Plain      string
+	}
+
+	cli := NewCLI()
+	cli.FallbackNameTags = []string{"json", "yaml"}
+	fields, _, err := cli.getFieldsFromConfig(&Cfg{})
+	require.NoError(t, err)
+	require.Len(t, fields, 5)
+	assert.Equal(t, "listen_addr", fields[0].Name)
+	assert.Equal(t, "LISTEN_ADDR", fields[0].EnvVarName)
+	assert.Equal(t, "log_level", fields[1].Name)
+	assert.Equal(t, "explicit", fields[2].Name)
+	assert.Equal(t, "ignored", fields[3].Name)
+	assert.Equal(t, "plain", fields[4].Name)
+}
+
+func TestFieldEmptyEnvTag(t *testing.T) {
+	type Cfg struct {
+		ListenAddr string `cli:"env"`
+	}
+
+	fields, _, err := NewCLI().getFieldsFromConfig(&Cfg{})
+	require.NoError(t, err)
+	assert.Equal(t, "", fields[0].EnvVarName)
+
+	cli := NewCLI()
+	cli.EnvPrefix = "APP_"
+	fields, _, err = cli.getFieldsFromConfig(&Cfg{})
+	require.NoError(t, err)
+	assert.Equal(t, "APP_LISTEN_ADDR", fields[0].EnvVarName)
+}
+
+func TestFieldFlagNameFunc(t *testing.T) {
+	type Cfg struct {
+		FooBar   string
+		Explicit string `cli:"name=explicit-name"`
+		Legacy   string
+	}
+
+	cli := NewCLI()
+	cli.FlagNameFunc = func(fieldName string) string {
+		if fieldName == "Legacy" {
+			return ""
+		}
+		return xstrings.ToSnakeCase(fieldName)
+	}
+	fields, _, err := cli.getFieldsFromConfig(&Cfg{})
+	require.NoError(t, err)
+	require.Len(t, fields, 3)
+	assert.Equal(t, "foo_bar", fields[0].Name)
+	assert.Equal(t, "explicit-name", fields[1].Name)
+	assert.Equal(t, "legacy", fields[2].Name)
+}
+
+func TestKVTag(t *testing.T) {
+	type Header struct {
+		Key   string
+		Value string
+	}
+	type Label struct {
+		Key, Value string
+	}
+	config := &struct {
+		Header []Header `cli:"append,kv=:"`
+		Label  []*Label `cli:"append,kv"`
+		Owner  Label    `cli:"kv"`
+	}{
+		Label: []*Label{{"default", "label"}},
+	}
+	cmd := New("test", config)
+	r := cmd.ParseArgs([]string{
+		"--header", "X-Foo: bar",
+		"--label", "app=web",
+		"--header", "Authorization: Basic a:b",
+		"--label", "tier=",
+		"--owner", "team=infra",
+	})
+	require.NoError(t, r.Err)
+	assert.Equal(t, []Header{{"X-Foo", "bar"}, {"Authorization", "Basic a:b"}}, config.Header)
+	assert.Equal(t, []*Label{{"default", "label"}, {"app", "web"}, {"tier", ""}}, config.Label)
+	assert.Regexp(t, `--label <KEY=VALUE> +\(default: default=label\)\n`, cmd.HelpString())
+	assert.Equal(t, Label{"team", "infra"}, config.Owner)
+	assert.Regexp(t, `--header <KEY:VALUE>\n`, cmd.HelpString())
+
+	r = New("test", config).ParseArgs([]string{"--label", "novalue"})
+	assert.EqualError(t, r.Err, "failed to parse args: invalid value \"novalue\" for flag label: expected KEY=VALUE")
+
+	_, err := Build("test", &struct {
+		Bad []string `cli:"append,kv"`
+	}{})
+	assert.Error(t, err)
+}
+
+func TestJSONTag(t *testing.T) {
+	type Filter struct {
+		State  string   `json:"state"`
+		Labels []string `json:"labels"`
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "filter.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"state": "closed", "labels": ["bug"]}`), 0o644))
+
+	type Config struct {
+		Filter  Filter                 `cli:"json"`
+		Extra   map[string]interface{} `cli:"json"`
+		Limit   *Filter                `cli:"json"`
+		Filters []Filter               `cli:"json,append"`
+	}
+
+	config := &Config{Filter: Filter{State: "open"}}
+	cmd := New("test", config)
+	assert.Regexp(t, `--filter <JSON> +\(default: {"state":"open","labels":null}\)\n`, cmd.HelpString())
+	assert.Regexp(t, `--extra <JSON> *\n`, cmd.HelpString())
+
+	r := cmd.ParseArgs([]string{
+		"--filter", `{"state":"all"}`,
+		"--extra", `{"a": 1}`,
+		"--limit=@" + path,
+		"--filters", `{"state":"open"}`,
+		"--filters", "@" + path,
+	})
+	require.NoError(t, r.Err)
+	assert.Equal(t, Filter{State: "all"}, config.Filter)
+	assert.Equal(t, map[string]interface{}{"a": 1.0}, config.Extra)
+	assert.Equal(t, &Filter{State: "closed", Labels: []string{"bug"}}, config.Limit)
+	assert.Equal(t, []Filter{{State: "open"}, {State: "closed", Labels: []string{"bug"}}}, config.Filters)
+
+	for _, arg := range []string{`{"stat":"open"}`, `{"state":1}`, `{} {}`, "@" + filepath.Join(dir, "missing.json")} {
+		config := &Config{Filter: Filter{State: "open"}}
+		r := New("test", config).ParseArgs([]string{"--filter", arg})
+		assert.Error(t, r.Err, arg)
+		assert.Equal(t, "open", config.Filter.State, arg)
+	}
+}
+
+func TestYAMLTag(t *testing.T) {
+	type Rule struct {
+		Name  string   `yaml:"name"`
+		Ports []int    `yaml:"ports"`
+		Hosts []string `yaml:"hosts"`
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "rules.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("- name: web\n  ports: [80, 443]\n- name: ssh\n  ports: [22]\n  hosts:\n    - bastion\n"), 0o644))
+
+	type Config struct {
+		Rule  Rule   `cli:"yaml"`
+		Rules []Rule `cli:"yaml"`
+	}
+	config := &Config{}
+	cmd := New("test", config)
+	assert.Regexp(t, `--rule <YAML> *\n`, cmd.HelpString())
+
+	r := cmd.ParseArgs([]string{"--rule", "{name: db, ports: [5432]}", "--rules", "@" + path})
+	require.NoError(t, r.Err)
+	assert.Equal(t, Rule{Name: "db", Ports: []int{5432}}, config.Rule)
+	assert.Equal(t, []Rule{
+		{Name: "web", Ports: []int{80, 443}},
+		{Name: "ssh", Ports: []int{22}, Hosts: []string{"bastion"}},
+	}, config.Rules)
+
+	r = New("test", &Config{}).ParseArgs([]string{"--rule", "{nme: db}"})
+	assert.Error(t, r.Err)
+}
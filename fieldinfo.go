@@ -0,0 +1,119 @@
+package cli
+
+// FieldInfo describes a field (i.e. a flag) of a command. It is used as the
+// data for help templates, and returned by Command.Fields for generating
+// documentation, completions, and other introspection.
+type FieldInfo struct {
+	// Name is the flag name, without dashes.
+	Name string
+
+	// Short is the single letter short name, if any.
+	Short string
+
+	// Aliases are any multi-letter aliases which can be passed with a single
+	// dash.
+	Aliases []string
+
+	// DeprecatedAliases are aliases which can still be used to set the
+	// field (e.g. after it was renamed), but are not shown in help.
+	DeprecatedAliases []string
+
+	// Env is the name of the environment variable which can be used to set
+	// the field, if any. It is empty if environment variables are disabled
+	// for the command.
+	Env string
+
+	// Default is the default value as shown in help text.
+	Default string
+
+	// Unset is true if the field is a nil pointer, in which case help text
+	// shows it as unset rather than showing Default.
+	Unset bool
+
+	Help        string
+	LongHelp    string
+	Placeholder string
+
+	// TypeName is the Go type of the field, e.g. "string" or
+	// "time.Duration". For fields with the append tag, it is the type of the
+	// slice elements. It is empty for built-in flags such as --help.
+	TypeName string
+
+	// HasArg is false for boolean flags, which don't take a value.
+	HasArg bool
+
+	// Repeatable is true if the field has the append tag.
+	Repeatable bool
+
+	// Negatable is true if the field is a bool which can also be set to
+	// false using a "--no-" prefixed flag, e.g. "--no-color".
+	Negatable bool
+
+	Required     bool
+	Hidden       bool
+	Secret       bool
+	Experimental bool
+
+	// Meta contains the field's extension tags (see
+	// CLI.ExtensionTagPrefixes).
+	Meta map[string]string
+}
+
+// Fields returns information about the command's fields, in the order they
+// are shown in help text. Fields which are only available when experimental
+// features are enabled are omitted unless they are.
+//
+// The order is deterministic: the help flag comes first, followed by the
+// config's fields sorted by their order tags (0 by default), and then any
+// fields added by the CLI (e.g. profiling flags). Fields with the same order
+// are in declaration order, with the fields of embedded structs in place of
+// the embedded struct, or sorted by name if CLI.SortFields is set. An order
+// tag on an embedded struct applies to each of its fields which don't have
+// their own.
+func (cmd *Command) Fields() []FieldInfo {
+	fields := []FieldInfo{}
+	for _, f := range cmd.fields {
+		if !cmd.fieldAvailable(f) {
+			continue
+		}
+		fields = append(fields, cmd.fieldInfo(f))
+	}
+	return fields
+}
+
+func (cmd *Command) fieldInfo(f field) FieldInfo {
+	_, repeatable := f.value.tags["append"]
+	typeName := ""
+	if f.value.target.IsValid() {
+		typ := f.value.target.Type()
+		if repeatable {
+			typ = typ.Elem()
+		}
+		typeName = typ.String()
+	}
+	info := FieldInfo{
+		Name:              f.Name,
+		Short:             f.ShortName,
+		Aliases:           f.Aliases,
+		DeprecatedAliases: f.DeprecatedAliases,
+		Env:               f.EnvVarName,
+		Default:           f.Default(),
+		Unset:             f.Unset(),
+		Help:              f.Help,
+		LongHelp:          f.LongHelp,
+		Placeholder:       f.Placeholder,
+		TypeName:          typeName,
+		HasArg:            f.HasArg,
+		Repeatable:        repeatable,
+		Negatable:         f.Negatable,
+		Required:          f.Required,
+		Hidden:            f.Hidden,
+		Secret:            f.Secret,
+		Experimental:      f.Experimental,
+		Meta:              f.Meta,
+	}
+	if cmd.envDisabled() {
+		info.Env = ""
+	}
+	return info
+}
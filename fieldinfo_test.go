@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFields(t *testing.T) {
+	config := &struct {
+		Timeout time.Duration `cli:"short=t,env=TIMEOUT,required,help=request timeout"`
+		Tags    []string      `cli:"append,hidden"`
+		Limit   *int
+		New     bool `cli:"experimental"`
+	}{
+		Timeout: time.Second,
+	}
+	cmd := New("test", config)
+	fields := cmd.Fields()
+	assert.Len(t, fields, 4)
+	assert.Equal(t, "help", fields[0].Name)
+	assert.Equal(t, FieldInfo{
+		Name:     "timeout",
+		Short:    "t",
+		Env:      "TIMEOUT",
+		Default:  "1s",
+		Help:     "request timeout",
+		TypeName: "time.Duration",
+		HasArg:   true,
+		Required: true,
+	}, fields[1])
+	assert.Equal(t, "string", fields[2].TypeName)
+	assert.True(t, fields[2].Repeatable)
+	assert.True(t, fields[2].Hidden)
+	assert.Equal(t, "*int", fields[3].TypeName)
+	assert.True(t, fields[3].Unset)
+
+	cmd.DisableEnv()
+	assert.Equal(t, "", cmd.Fields()[1].Env)
+}
@@ -0,0 +1,28 @@
+package cli
+
+import (
+	"context"
+)
+
+// NewFunc creates a new Command which calls fn with the remaining non-flag
+// args when run, for leaf commands which don't need any options of their own.
+// This avoids needing to define a config struct for every such command.
+func NewFunc(name string, fn func(ctx context.Context, args []string) error, opts ...CommandOption) *Command {
+	return defaultCLI.NewFunc(name, fn, opts...)
+}
+
+// NewFunc is like the package-level NewFunc function, but it uses the CLI's
+// settings.
+func (cli *CLI) NewFunc(name string, fn func(ctx context.Context, args []string) error, opts ...CommandOption) *Command {
+	return cli.New(name, &funcCommand{fn: fn}, opts...)
+}
+
+type funcCommand struct {
+	Args []string `cli:"args"`
+
+	fn func(ctx context.Context, args []string) error
+}
+
+func (cmd *funcCommand) Run(ctx context.Context) error {
+	return cmd.fn(ctx, cmd.Args)
+}
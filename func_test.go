@@ -0,0 +1,24 @@
+package cli
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewFunc(t *testing.T) {
+	var gotArgs []string
+	c := New("test", &struct{}{},
+		NewFunc("echo", func(ctx context.Context, args []string) error {
+			gotArgs = args
+			return nil
+		}, WithHelp("print args")),
+	)
+	assert.Regexp(t, `echo +print args`, c.HelpString())
+
+	err := c.ParseArgs([]string{"echo", "a", "b"}).Run()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, gotArgs)
+}
@@ -0,0 +1,109 @@
+// Package gcpopts provides standard Google Cloud flags which build
+// credentials and client options for use with the Google Cloud client
+// libraries:
+//
+//	type Sync struct {
+//		gcpopts.Options
+//	}
+//
+//	func (s *Sync) Run(ctx context.Context) error {
+//		cfg, err := s.Build(ctx)
+//		if err != nil {
+//			return err
+//		}
+//		client, err := storage.NewClient(ctx, cfg.ClientOptions...)
+//		...
+//	}
+//
+// The flags' environment variables are the ones used by the client libraries
+// and gcloud (e.g. GOOGLE_CLOUD_PROJECT and GOOGLE_APPLICATION_CREDENTIALS),
+// so they behave the same as in other Google Cloud tools.
+//
+// This package is a separate module so that the cli module does not depend on
+// the Google Cloud libraries.
+package gcpopts
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/option"
+)
+
+// DefaultScopes are the OAuth scopes used by Build if none are given.
+var DefaultScopes = []string{"https://www.googleapis.com/auth/cloud-platform"}
+
+// Options can be embedded in a command config to add Google Cloud flags.
+type Options struct {
+	Project         string `cli:"name=gcp-project,env=GOOGLE_CLOUD_PROJECT,placeholder=ID,help=Google Cloud project ID (from the credentials if not set)"`
+	Region          string `cli:"name=gcp-region,env=CLOUDSDK_COMPUTE_REGION,placeholder=REGION,help=Google Cloud region"`
+	CredentialsFile string `cli:"name=gcp-credentials-file,env=GOOGLE_APPLICATION_CREDENTIALS,placeholder=PATH,help=service account or user credentials JSON file (application default credentials if not set)"`
+}
+
+// Config is the configuration built from Options.
+type Config struct {
+	// ProjectID is the project given by the options, or by the credentials
+	// if none was given.
+	ProjectID string
+
+	// Region is the region given by the options, which may be empty.
+	Region string
+
+	// Credentials are the credentials loaded from the credentials file, or
+	// the application default credentials.
+	Credentials *google.Credentials
+
+	// ClientOptions configure Google Cloud clients to use Credentials and
+	// ProjectID.
+	ClientOptions []option.ClientOption
+}
+
+// Build loads credentials (for the given scopes, or DefaultScopes if none are
+// given) and returns the resulting Config. It is an error if no project ID
+// can be determined.
+func (opts *Options) Build(ctx context.Context, scopes ...string) (Config, error) {
+	if len(scopes) == 0 {
+		scopes = DefaultScopes
+	}
+	creds, err := opts.credentials(ctx, scopes)
+	if err != nil {
+		return Config{}, err
+	}
+	project := opts.Project
+	if project == "" {
+		project = creds.ProjectID
+	}
+	if project == "" {
+		return Config{}, fmt.Errorf("no Google Cloud project specified (use --gcp-project)")
+	}
+	return Config{
+		ProjectID:   project,
+		Region:      opts.Region,
+		Credentials: creds,
+		ClientOptions: []option.ClientOption{
+			option.WithCredentials(creds),
+			option.WithQuotaProject(project),
+		},
+	}, nil
+}
+
+func (opts *Options) credentials(ctx context.Context, scopes []string) (*google.Credentials, error) {
+	if opts.CredentialsFile == "" {
+		creds, err := google.FindDefaultCredentials(ctx, scopes...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find Google Cloud credentials: %w", err)
+		}
+		return creds, nil
+	}
+	b, err := os.ReadFile(opts.CredentialsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Google Cloud credentials: %w", err)
+	}
+	creds, err := google.CredentialsFromJSON(ctx, b, scopes...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Google Cloud credentials file %s: %w", opts.CredentialsFile, err)
+	}
+	return creds, nil
+}
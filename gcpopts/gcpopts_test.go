@@ -0,0 +1,76 @@
+package gcpopts
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/isobit/cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeServiceAccount(t *testing.T, project string) string {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	b, err := json.Marshal(map[string]string{
+		"type":           "service_account",
+		"project_id":     project,
+		"private_key_id": "abc123",
+		"private_key":    string(keyPEM),
+		"client_email":   "test@" + project + ".iam.gserviceaccount.com",
+		"client_id":      "123",
+		"token_uri":      "https://oauth2.googleapis.com/token",
+	})
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "credentials.json")
+	require.NoError(t, os.WriteFile(path, b, 0o600))
+	return path
+}
+
+func TestBuild(t *testing.T) {
+	path := writeServiceAccount(t, "from-creds")
+
+	config := &struct {
+		Options
+	}{}
+	cmd := cli.New("test", config)
+	assert.Contains(t, cmd.HelpString(), "--gcp-project <ID>")
+	require.NoError(t, cmd.ParseArgs([]string{
+		"--gcp-credentials-file", path,
+		"--gcp-region", "us-central1",
+	}).Err)
+
+	cfg, err := config.Build(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "from-creds", cfg.ProjectID)
+	assert.Equal(t, "us-central1", cfg.Region)
+	assert.Len(t, cfg.ClientOptions, 2)
+
+	config.Project = "override"
+	cfg, err = config.Build(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "override", cfg.ProjectID)
+}
+
+func TestBuildErrors(t *testing.T) {
+	_, err := (&Options{CredentialsFile: writeServiceAccount(t, "")}).Build(context.Background())
+	assert.EqualError(t, err, "no Google Cloud project specified (use --gcp-project)")
+
+	_, err = (&Options{CredentialsFile: filepath.Join(t.TempDir(), "missing.json")}).Build(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to read Google Cloud credentials")
+
+	bad := filepath.Join(t.TempDir(), "bad.json")
+	require.NoError(t, os.WriteFile(bad, []byte("{"), 0o600))
+	_, err = (&Options{CredentialsFile: bad}).Build(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to parse Google Cloud credentials file")
+}
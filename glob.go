@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Glob is a field type for file glob patterns, as understood by
+// filepath.Match. The pattern syntax is validated when the flag is set, but
+// it isn't expanded until Expand is called.
+type Glob struct {
+	pattern string
+}
+
+// MustGlob returns a Glob for pattern, which is useful for default values. It
+// panics if pattern is malformed.
+func MustGlob(pattern string) Glob {
+	g := Glob{}
+	if err := g.Set(pattern); err != nil {
+		panic(err)
+	}
+	return g
+}
+
+func (g *Glob) Set(s string) error {
+	if _, err := filepath.Match(s, ""); err != nil {
+		return fmt.Errorf("invalid glob pattern %q: %w", s, err)
+	}
+	g.pattern = s
+	return nil
+}
+
+func (g Glob) String() string {
+	return g.pattern
+}
+
+// Pattern returns the glob pattern.
+func (g Glob) Pattern() string {
+	return g.pattern
+}
+
+// Match reports whether name matches the pattern.
+func (g Glob) Match(name string) bool {
+	ok, _ := filepath.Match(g.pattern, name)
+	return ok
+}
+
+// Expand returns the names of all files matching the pattern, or nil if there
+// are none (or no pattern has been set).
+func (g Glob) Expand() ([]string, error) {
+	if g.pattern == "" {
+		return nil, nil
+	}
+	return filepath.Glob(g.pattern)
+}
+
+// FileSet is a field type which expands each value as a glob pattern and
+// accumulates the matching paths, so that a flag can be repeated (e.g.
+// "--file '*.go' --file 'cmd/*.go'") without needing the append tag. Paths are
+// only added once, and it is an error for a pattern to match no files.
+type FileSet []string
+
+func (fs *FileSet) Set(s string) error {
+	g := Glob{}
+	if err := g.Set(s); err != nil {
+		return err
+	}
+	matches, err := g.Expand()
+	if err != nil {
+		return err
+	}
+	if len(matches) == 0 {
+		return fmt.Errorf("no files match %q", s)
+	}
+	seen := make(map[string]bool, len(*fs))
+	for _, path := range *fs {
+		seen[path] = true
+	}
+	for _, path := range matches {
+		if !seen[path] {
+			seen[path] = true
+			*fs = append(*fs, path)
+		}
+	}
+	return nil
+}
+
+func (fs FileSet) String() string {
+	return strings.Join(fs, ",")
+}
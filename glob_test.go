@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGlob(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.go", "b.go", "c.txt"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0o644))
+	}
+
+	config := &struct {
+		Pattern Glob
+		Exclude []Glob `cli:"append"`
+	}{
+		Pattern: MustGlob("*.go"),
+	}
+	cmd := New("test", config)
+	assert.Contains(t, cmd.HelpString(), "(default: *.go)")
+
+	require.NoError(t, cmd.ParseArgs([]string{
+		"--pattern", filepath.Join(dir, "*.go"),
+		"--exclude", "a*", "--exclude", "c*",
+	}).Err)
+	matches, err := config.Pattern.Expand()
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "a.go"), filepath.Join(dir, "b.go")}, matches)
+	require.Len(t, config.Exclude, 2)
+	assert.True(t, config.Exclude[0].Match("a.go"))
+	assert.False(t, config.Exclude[1].Match("a.go"))
+
+	r := New("test", config).ParseArgs([]string{"--pattern", "[a-"})
+	require.Error(t, r.Err)
+	assert.Contains(t, r.Err.Error(), `invalid glob pattern "[a-"`)
+
+	var empty Glob
+	matches, err = empty.Expand()
+	require.NoError(t, err)
+	assert.Nil(t, matches)
+}
+
+func TestFileSet(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.go", "b.go", "c.txt"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0o644))
+	}
+
+	config := &struct {
+		Files FileSet
+	}{}
+	require.NoError(t, New("test", config).ParseArgs([]string{
+		"--files", filepath.Join(dir, "*.go"),
+		"--files", filepath.Join(dir, "[ac].*"),
+	}).Err)
+	assert.Equal(t, FileSet{
+		filepath.Join(dir, "a.go"),
+		filepath.Join(dir, "b.go"),
+		filepath.Join(dir, "c.txt"),
+	}, config.Files)
+
+	r := New("test", config).ParseArgs([]string{"--files", filepath.Join(dir, "*.md")})
+	require.Error(t, r.Err)
+	assert.Contains(t, r.Err.Error(), "no files match")
+}
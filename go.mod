@@ -1,14 +1,14 @@
 module github.com/isobit/cli
 
-go 1.17
+go 1.18
 
 require (
 	github.com/huandu/xstrings v1.4.0
 	github.com/stretchr/testify v1.7.0
+	gopkg.in/yaml.v3 v3.0.0
 )
 
 require (
 	github.com/davecgh/go-spew v1.1.0 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	gopkg.in/yaml.v3 v3.0.0 // indirect
 )
@@ -0,0 +1,89 @@
+// Package healthopts provides an optional HTTP listener serving liveness and
+// readiness checks for commands run as containerized services:
+//
+//	type App struct {
+//		healthopts.Options
+//	}
+//
+//	app := &App{}
+//	cli.NewCLI().
+//		Use(healthopts.Middleware(&app.Options)).
+//		New("myservice", app).
+//		Parse().
+//		RunFatalWithSigCancel()
+//
+// The listener serves the following endpoints:
+//
+//	/livez   200 while the command is running, or 503 once its context has
+//	         been cancelled (e.g. by SIGTERM)
+//	/readyz  200 once all setup steps (see cli.SetupStepper) have completed
+//	         and until the context is cancelled, otherwise 503
+package healthopts
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/isobit/cli"
+)
+
+// Options can be embedded in a command config to add a flag for configuring
+// the health check listener. If HealthAddr is empty, no listener is started.
+type Options struct {
+	HealthAddr string `cli:"name=health-addr,env=HEALTH_ADDR,help=address to serve /livez and /readyz health checks on (e.g. :8081)"`
+}
+
+// Middleware returns cli.Middleware which serves health checks on the
+// address configured by opts (which will usually be embedded in the root
+// command's config) for as long as the command's Run method is running. If
+// the address can't be listened on, the command is not run and an error is
+// returned.
+func Middleware(opts *Options) cli.Middleware {
+	return func(ctx context.Context, cmd *cli.Command, next func(context.Context) error) error {
+		if opts.HealthAddr == "" {
+			return next(ctx)
+		}
+		ln, err := net.Listen("tcp", opts.HealthAddr)
+		if err != nil {
+			return fmt.Errorf("failed to listen for health checks: %w", err)
+		}
+
+		h := &handler{ctx: ctx}
+		srv := &http.Server{Handler: h}
+		go srv.Serve(ln)
+		defer srv.Close()
+
+		ctx = cli.OnSetupDone(ctx, h.setReady)
+		return next(ctx)
+	}
+}
+
+type handler struct {
+	ctx   context.Context
+	ready int32
+}
+
+func (h *handler) setReady() {
+	atomic.StoreInt32(&h.ready, 1)
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var ok bool
+	switch r.URL.Path {
+	case "/livez":
+		ok = h.ctx.Err() == nil
+	case "/readyz":
+		ok = h.ctx.Err() == nil && atomic.LoadInt32(&h.ready) == 1
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	if !ok {
+		http.Error(w, "not ok", http.StatusServiceUnavailable)
+		return
+	}
+	fmt.Fprintln(w, "ok")
+}
@@ -0,0 +1,67 @@
+package healthopts
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/isobit/cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func check(h http.Handler, path string) int {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+	return rec.Code
+}
+
+func TestHandler(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	h := &handler{ctx: ctx}
+	assert.Equal(t, http.StatusOK, check(h, "/livez"))
+	assert.Equal(t, http.StatusServiceUnavailable, check(h, "/readyz"))
+	assert.Equal(t, http.StatusNotFound, check(h, "/other"))
+
+	h.setReady()
+	assert.Equal(t, http.StatusOK, check(h, "/readyz"))
+
+	cancel()
+	assert.Equal(t, http.StatusServiceUnavailable, check(h, "/livez"))
+	assert.Equal(t, http.StatusServiceUnavailable, check(h, "/readyz"))
+}
+
+type testCommand struct {
+	Options
+	readyCode int
+}
+
+func (c *testCommand) Run() error {
+	resp, err := http.Get("http://" + c.HealthAddr + "/readyz")
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	c.readyCode = resp.StatusCode
+	return nil
+}
+
+func TestMiddleware(t *testing.T) {
+	// Find a free port to listen on.
+	srv := httptest.NewServer(http.NotFoundHandler())
+	addr := srv.Listener.Addr().String()
+	srv.Close()
+
+	cmd := &testCommand{}
+	err := cli.NewCLI().
+		Use(Middleware(&cmd.Options)).
+		New("test", cmd).
+		ParseArgs([]string{"--health-addr", addr}).
+		Run()
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, cmd.readyCode)
+
+	_, err = http.Get("http://" + addr + "/livez")
+	assert.Error(t, err)
+}
@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"strings"
@@ -10,33 +11,79 @@ import (
 
 var ErrHelp = fmt.Errorf("cli: help requested")
 
-var helpTemplateString = `
-{{- if 0}}{{end -}}
+var usageTemplateString = `
+{{- define "usage" -}}
 USAGE:
-    {{.FullName}}{{if .Fields}} [OPTIONS]{{end}}{{if .Commands}} <COMMAND>{{end}}{{if .Args}} [ARGS]{{end}}
+    {{.FullName}}{{if .Fields}} [OPTIONS]{{end}}{{if .Commands}} <COMMAND>{{end}}
+{{- range .Positionals}} {{if .Required}}<{{.Name}}>{{else}}[{{.Name}}]{{end}}{{end}}{{if .Args}} [ARGS]{{end}}
 {{- if .SupportsHelpCommand}}
-    {{.FullName}} help{{if .Commands}} [COMMAND...]{{end}}
+    {{.FullName}} help{{if .Commands}} [COMMAND...]{{end}}{{if .Topics}} [TOPIC]{{end}}
 {{- end}}
+{{- end -}}
+`
 
+var optionsTemplateString = `
+{{- define "options" -}}
 {{- if .Fields}}
 
 OPTIONS:
 {{- range .Fields}}{{if not .Hidden}}
 \t    \t
-{{- if .ShortName}}-{{.ShortName}}, {{end}}--{{.Name}}
+{{- if .Short}}-{{.Short}}, {{end}}{{range .Aliases}}-{{.}}, {{end}}--{{if .Negatable}}[no-]{{end}}{{.Name}}
 {{- if .HasArg}} <{{if .Placeholder}}{{.Placeholder}}{{else}}VALUE{{end}}>{{end}}\t
-{{- if .EnvVarName}}  {{.EnvVarName}}{{end}}\t
+{{- if .Env}}  {{.Env}}{{end}}\t
 {{- if .Help}}  {{.Help}}{{end}}
-{{- if and .HasArg }}{{if and .Default (not .Required)}}  (default: {{.Default}}){{else if .Required}}  (required){{end}}{{end}}
+{{- if .Experimental}}  (experimental){{end}}
+{{- if .HasArg}}{{if .Required}}  (required){{else if .Unset}}  (unset){{else if .Default}}  (default: {{.Default}}){{end}}{{end}}
 {{- end}}
 
 {{- end}}{{end}}
+{{- end -}}
+`
+
+var helpTemplateString = `
+{{- if 0}}{{end -}}
+{{template "usage" .}}
+
+{{- if .Positionals}}
+
+ARGUMENTS:
+{{- range .Positionals}}
+\t    \t{{if .Required}}<{{.Name}}>{{else}}[{{.Name}}]{{end}}\t
+{{- if .Help}}  {{.Help}}{{end}}
+{{- if and (not .Required) .Default}}  (default: {{.Default}}){{end}}
+{{- end}}
+
+{{- end}}
+{{- template "options" .}}
 
-{{- if .Commands}}
+{{- if .Filter}}
+
+COMMANDS MATCHING "{{.Filter}}":
+{{- range .Commands}}
+\t    \t{{.Name}}\t{{ if .Help}}  {{.Help}}{{end}}{{if .Experimental}}  (experimental){{end}}
+{{- else}}
+    none
+{{- end}}
+
+{{- else if .Commands}}
 
 COMMANDS:
 {{- range .Commands}}
-\t    \t{{.Name}}\t{{ if .Help}}  {{.Help}}{{end}}
+\t    \t{{.Name}}\t{{ if .Help}}  {{.Help}}{{end}}{{if .Experimental}}  (experimental){{end}}
+{{- end}}
+{{- if .CommandsOmitted}}
+    ... and {{.CommandsOmitted}} more
+{{- if .FilterCommand}} (use "{{.FilterCommand}}" to search){{end}}
+{{- end}}
+
+{{- end}}
+
+{{- if .Topics}}
+
+HELP TOPICS:
+{{- range .Topics}}
+\t    \t{{.Name}}\t{{ if .Title}}  {{.Title}}{{end}}
 {{- end}}
 
 {{- end}}
@@ -49,22 +96,59 @@ DESCRIPTION:
 
 `
 
+var topicHelpTemplateString = `
+{{- define "topic" -}}
+{{.Title}}
+
+    {{.Text}}
+
+{{end -}}
+`
+
+// fieldHelpTemplateString is used to show help for a single field, e.g. when
+// the value for that field could not be parsed.
+var fieldHelpTemplateString = `
+{{- define "field" -}}
+{{template "usage" .}}
+{{- template "options" .}}
+{{- if .LongHelp}}
+
+    {{.LongHelp}}
+{{- end}}
+
+{{end -}}
+`
+
 var helpTemplate *template.Template
 
 func init() {
 	helpTemplate = template.Must(
 		template.New("help").Parse(helpTemplateString),
 	)
+	template.Must(helpTemplate.Parse(usageTemplateString))
+	template.Must(helpTemplate.Parse(optionsTemplateString))
+	template.Must(helpTemplate.Parse(fieldHelpTemplateString))
+	template.Must(helpTemplate.Parse(topicHelpTemplateString))
 }
 
 func (cmd *Command) fullName() string {
-	sb := strings.Builder{}
-	if cmd.parent != nil {
-		sb.WriteString(cmd.parent.fullName())
-		sb.WriteString(" ")
+	return strings.Join(cmd.Path(), " ")
+}
+
+// FullName returns the name of the command prefixed by the names of its
+// parents, separated by spaces (e.g. "mycli sub"), as shown in help text.
+func (cmd *Command) FullName() string {
+	return cmd.fullName()
+}
+
+// Path returns the names of the command's parents, starting with the root
+// command, followed by the name of the command itself (e.g. ["mycli",
+// "sub"]).
+func (cmd *Command) Path() []string {
+	if cmd.parent == nil {
+		return []string{cmd.name}
 	}
-	sb.WriteString(cmd.name)
-	return sb.String()
+	return append(cmd.parent.Path(), cmd.name)
 }
 
 func (cmd *Command) HelpString() string {
@@ -73,37 +157,265 @@ func (cmd *Command) HelpString() string {
 	return sb.String()
 }
 
-func (cmd *Command) WriteHelp(w io.Writer) {
-	type subcommandData struct {
-		Name string
-		Help string
-	}
-	data := struct {
-		FullName    string
-		Description string
-		Fields      []field
-		Commands    []subcommandData
-		Args        bool
-
-		SupportsHelpCommand bool
-	}{
+// HelpData is the data used to render help text for a command, either by the
+// built-in help templates or by a custom HelpRenderer.
+type HelpData struct {
+	// FullName is the name of the command prefixed by the names of its
+	// parents (see Command.FullName).
+	FullName string
+
+	// Description is the command's description, indented for display.
+	Description string
+
+	// LongHelp is the long help text of a single field, used when showing
+	// help for a specific field.
+	LongHelp string
+
+	Fields   []FieldInfo
+	Commands []HelpCommand
+	Topics   []HelpTopic
+
+	// Positionals are the positional arguments bound to fields with the
+	// "arg=N" tag, in order.
+	Positionals []HelpArg
+
+	// Args is true if the command accepts additional positional arguments
+	// using a field with the "args" tag.
+	Args bool
+
+	// Filter is set if the subcommands were filtered using
+	// "help --filter".
+	Filter string
+
+	// CommandsOmitted is the number of subcommands which were omitted due
+	// to CLI.HelpCommandLimit, and FilterCommand is an example command for
+	// filtering the subcommands instead.
+	CommandsOmitted int
+	FilterCommand   string
+
+	// SupportsHelpCommand is true if "help [COMMAND...]" can be used, which
+	// is the case for root commands without positional arguments.
+	SupportsHelpCommand bool
+}
+
+// HelpTopic is a help topic added with Command.AddHelpTopic.
+type HelpTopic struct {
+	Name  string
+	Title string
+	Text  string
+}
+
+// HelpArg describes a positional argument listed in help text.
+type HelpArg struct {
+	Name     string
+	Help     string
+	Required bool
+	Default  string
+}
+
+// HelpCommand describes a visible subcommand listed in help text.
+type HelpCommand struct {
+	Name         string
+	Help         string
+	Experimental bool
+}
+
+// HelpRenderer can be set as CLI.HelpRenderer to customize the layout of
+// help text (e.g. compact, man-like, or markdown) without string templates.
+type HelpRenderer interface {
+	Render(w io.Writer, data HelpData) error
+}
+
+// DefaultHelpRenderer renders help text using the built-in templates. It can
+// be used by custom HelpRenderers which only add to the default help text.
+var DefaultHelpRenderer HelpRenderer = templateHelpRenderer{}
+
+type templateHelpRenderer struct{}
+
+func (templateHelpRenderer) Render(w io.Writer, data HelpData) error {
+	tw := newEscapedTabWriter(w)
+	if err := helpTemplate.ExecuteTemplate(tw, "help", data); err != nil {
+		return err
+	}
+	return tw.Flush()
+}
+
+func (cmd *Command) helpData() HelpData {
+	data := HelpData{
 		FullName:    cmd.fullName(),
-		Description: strings.ReplaceAll(strings.TrimSpace(cmd.description), "\n", "\n    "),
-		Fields:      cmd.fields,
-		Commands:    []subcommandData{},
-		Args:        cmd.argsField != nil,
+		Description: indentHelpText(cmd.description),
+		Fields:      cmd.Fields(),
+		Commands:    []HelpCommand{},
+		Topics:      cmd.helpTopics,
 
 		SupportsHelpCommand: cmd.parent == nil && cmd.argsField == nil,
 	}
+	if cmd.argsField != nil {
+		data.Args = cmd.argsField.setter != nil
+		for _, p := range cmd.argsField.positionals {
+			data.Positionals = append(data.Positionals, HelpArg{
+				Name:     p.Name,
+				Help:     p.Help,
+				Required: p.Required,
+				Default:  p.Default(),
+			})
+		}
+	}
 	for _, cmd := range cmd.commands {
-		data.Commands = append(data.Commands, subcommandData{
-			Name: cmd.name,
-			Help: cmd.help,
+		if cmd.hidden || !cmd.parent.commandAvailable(cmd) {
+			continue
+		}
+		data.Commands = append(data.Commands, HelpCommand{
+			Name:         cmd.name,
+			Help:         cmd.help,
+			Experimental: cmd.experimental,
 		})
 	}
+	cmd.filterHelpCommands(&data)
+	return data
+}
+
+// filterHelpCommands filters the subcommands in data using the filter passed
+// to "help --filter", or if there is none, limits the number of subcommands
+// shown according to CLI.HelpCommandLimit.
+func (cmd *Command) filterHelpCommands(data *HelpData) {
+	if cmd.helpFilter != "" {
+		data.Filter = cmd.helpFilter
+		filter := strings.ToLower(cmd.helpFilter)
+		filtered := []HelpCommand{}
+		for _, c := range data.Commands {
+			if strings.Contains(strings.ToLower(c.Name), filter) || strings.Contains(strings.ToLower(c.Help), filter) {
+				filtered = append(filtered, c)
+			}
+		}
+		data.Commands = filtered
+		return
+	}
+
+	limit := cmd.cli.HelpCommandLimit
+	if limit <= 0 || len(data.Commands) <= limit {
+		return
+	}
+	data.CommandsOmitted = len(data.Commands) - limit
+	data.Commands = data.Commands[:limit]
+
+	root := cmd
+	for root.parent != nil {
+		root = root.parent
+	}
+	if root.argsField == nil {
+		path := cmd.Path()
+		args := append([]string{path[0], "help"}, path[1:]...)
+		data.FilterCommand = strings.Join(args, " ") + " --filter <TEXT>"
+	}
+}
+
+// parseHelpCommandArgs separates the --filter flag (if any) from the other
+// arguments to the help command.
+func parseHelpCommandArgs(args []string) ([]string, string, error) {
+	rest := []string{}
+	filter := ""
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--filter":
+			if i+1 >= len(args) {
+				return nil, "", UsageErrorf("flag needs an argument: filter")
+			}
+			filter = args[i+1]
+			i++
+		case strings.HasPrefix(arg, "--filter="):
+			filter = strings.TrimPrefix(arg, "--filter=")
+		default:
+			rest = append(rest, arg)
+		}
+	}
+	return rest, filter, nil
+}
+
+func (cmd *Command) WriteHelp(w io.Writer) {
+	cmd.cli.styledWriter(w).Write(cmd.renderHelp("help"))
+}
+
+// WriteUsage writes only the USAGE section of the help text, which is useful
+// for terse error output.
+func (cmd *Command) WriteUsage(w io.Writer) {
+	cmd.cli.styledWriter(w).Write(cmd.renderHelp("usage"))
+	fmt.Fprintln(w)
+}
+
+// writeFieldHelp writes the USAGE section of the help text, followed by only
+// the OPTIONS entry for the field with the given name.
+func (cmd *Command) writeFieldHelp(w io.Writer, name string) {
+	data := cmd.helpData()
+	f, ok := cmd.fieldMap[name]
+	if !ok {
+		data.Fields = nil
+	} else {
+		data.Fields = []FieldInfo{cmd.fieldInfo(f)}
+	}
+	executeHelpTemplate(cmd.cli.styledWriter(w), "field", data)
+}
+
+// writeTopicHelp writes detailed help for the given help topic, which is
+// either the name of a help topic added with AddHelpTopic, or the name of a
+// field (optionally prefixed with dashes).
+func (cmd *Command) writeTopicHelp(w io.Writer, topic string) {
+	for _, t := range cmd.helpTopics {
+		if t.Name == topic {
+			t.Text = indentHelpText(t.Text)
+			executeHelpTemplate(cmd.cli.styledWriter(w), "topic", t)
+			return
+		}
+	}
+
+	name := strings.TrimLeft(topic, "-")
+	data := cmd.helpData()
+	if f, ok := cmd.fieldMap[name]; ok {
+		data.Fields = []FieldInfo{cmd.fieldInfo(f)}
+		data.LongHelp = indentHelpText(f.LongHelp)
+	}
+	executeHelpTemplate(cmd.cli.styledWriter(w), "field", data)
+}
+
+// isHelpTopic returns true if topic can be passed to writeTopicHelp.
+func (cmd *Command) isHelpTopic(topic string) bool {
+	for _, t := range cmd.helpTopics {
+		if t.Name == topic {
+			return true
+		}
+	}
+	_, ok := cmd.fieldMap[strings.TrimLeft(topic, "-")]
+	return ok
+}
+
+func indentHelpText(s string) string {
+	return strings.ReplaceAll(strings.TrimSpace(s), "\n", "\n    ")
+}
+
+// executeHelp executes the named help template for the command, or the CLI's
+// HelpRenderer if it is set and the full help text is requested. If the
+// HelpRenderer returns an error, it is printed to ErrWriter and the help text
+// is rendered by DefaultHelpRenderer instead.
+func (cmd *Command) executeHelp(w io.Writer, name string) {
+	if name != "help" || cmd.cli.HelpRenderer == nil {
+		executeHelpTemplate(w, name, cmd.helpData())
+		return
+	}
+	b := bytes.Buffer{}
+	if err := cmd.cli.HelpRenderer.Render(&b, cmd.helpData()); err != nil {
+		if cmd.cli.ErrWriter != nil {
+			fmt.Fprintf(cmd.cli.ErrWriter, "cli: error rendering help: %s\n", err)
+		}
+		executeHelpTemplate(w, name, cmd.helpData())
+		return
+	}
+	w.Write(b.Bytes())
+}
 
+func executeHelpTemplate(w io.Writer, name string, data interface{}) {
 	tw := newEscapedTabWriter(w)
-	err := helpTemplate.Execute(tw, data)
+	err := helpTemplate.ExecuteTemplate(tw, name, data)
 	if err != nil {
 		panic(fmt.Sprintf("cli: error executing help template: %s", err))
 	}
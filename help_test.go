@@ -2,10 +2,12 @@ package cli
 
 import (
 	"fmt"
+	"io"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCLIWritesHelp(t *testing.T) {
@@ -107,3 +109,387 @@ func TestCLIUsageErrors(t *testing.T) {
 		})
 	}
 }
+
+func TestHelpPointerUnset(t *testing.T) {
+	s := "hello"
+	cmd := &struct {
+		Unset      *string
+		Set        *string
+		WithTag    *string `cli:"default=foo"`
+		NoDefault  *string `cli:"nodefault"`
+		NotPointer string
+	}{
+		Set: &s,
+	}
+	help := New("test", cmd).HelpString()
+	assert.Regexp(t, `--unset <VALUE> +\(unset\)\n`, help)
+	assert.Regexp(t, `--set <VALUE> +\(default: hello\)\n`, help)
+	assert.Regexp(t, `--with-tag <VALUE> +\(default: foo\)\n`, help)
+	assert.Regexp(t, `--no-default <VALUE> *\n`, help)
+	assert.Regexp(t, `--not-pointer <VALUE> *\n`, help)
+}
+
+func TestHelpFlagCustomization(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		cli := NewCLI()
+		cli.DisableHelpFlag = true
+		r := cli.New("test", nil).ParseArgs([]string{"--help"})
+		assert.Error(t, r.Err)
+		assert.NotEqual(t, ErrHelp, r.Err)
+	})
+	t.Run("renamed", func(t *testing.T) {
+		cli := NewCLI()
+		cli.HelpFlagName = "usage"
+		cli.HelpFlagShortName = "?"
+		r := cli.New("test", nil).ParseArgs([]string{"-?"})
+		assert.Equal(t, ErrHelp, r.Err)
+		r = cli.New("test", nil).ParseArgs([]string{"--usage"})
+		assert.Equal(t, ErrHelp, r.Err)
+	})
+	t.Run("no short name", func(t *testing.T) {
+		cli := NewCLI()
+		cli.DisableHelpFlagShortName = true
+		r := cli.New("test", nil).ParseArgs([]string{"-h"})
+		assert.Error(t, r.Err)
+		assert.NotEqual(t, ErrHelp, r.Err)
+	})
+	t.Run("option", func(t *testing.T) {
+		cmd := &struct {
+			Host string `cli:"short=h"`
+		}{}
+		c := New(
+			"test", nil,
+			New("sub", cmd, WithHelpFlag("help", "")),
+		)
+		r := c.ParseArgs([]string{"sub", "-h", "example.com"})
+		assert.NoError(t, r.Err)
+		assert.Equal(t, "example.com", cmd.Host)
+		r = c.ParseArgs([]string{"-h"})
+		assert.Equal(t, ErrHelp, r.Err)
+
+		r = New("test", nil, WithHelpFlag("", "")).ParseArgs([]string{"--help"})
+		assert.NotEqual(t, ErrHelp, r.Err)
+	})
+}
+
+type HelpOrderTestOptions struct {
+	Zeta  string
+	Alpha string `cli:"order=-1"`
+}
+
+func helpOrderTestNames(cmd *Command) []string {
+	names := []string{}
+	for _, f := range cmd.Fields() {
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+func TestHelpFieldOrder(t *testing.T) {
+	type Cmd struct {
+		Verbose bool
+		HelpOrderTestOptions
+		Output string
+	}
+	assert.Equal(t,
+		[]string{"help", "alpha", "verbose", "zeta", "output"},
+		helpOrderTestNames(New("test", &Cmd{})),
+	)
+
+	type CmdEmbedOrder struct {
+		HelpOrderTestOptions `cli:"order=10"`
+		Verbose              bool
+		Output               string
+	}
+	assert.Equal(t,
+		[]string{"help", "alpha", "verbose", "output", "zeta"},
+		helpOrderTestNames(New("test", &CmdEmbedOrder{})),
+	)
+
+	cli := NewCLI()
+	cli.SortFields = true
+	assert.Equal(t,
+		[]string{"help", "alpha", "output", "verbose", "zeta"},
+		helpOrderTestNames(cli.New("test", &Cmd{})),
+	)
+
+	_, err := NewCLI().Build("test", &struct {
+		Foo string `cli:"order=first"`
+	}{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "order must be an integer")
+}
+
+func TestWriteUsage(t *testing.T) {
+	c := New(
+		"test", &struct{ Foo string }{},
+		New("sub", nil),
+	)
+	b := &strings.Builder{}
+	c.WriteUsage(b)
+	expected := "USAGE:\n" +
+		"    test [OPTIONS] <COMMAND>\n" +
+		"    test help [COMMAND...]\n"
+	assert.Equal(t, expected, b.String())
+	assert.True(t, strings.HasPrefix(c.HelpString(), expected))
+}
+
+func TestUsageErrorHelp(t *testing.T) {
+	testCases := []struct {
+		verbosity HelpVerbosity
+		expected  func(c *Command) string
+	}{
+		{HelpFull, (*Command).HelpString},
+		{HelpUsage, func(c *Command) string {
+			b := &strings.Builder{}
+			c.WriteUsage(b)
+			return b.String()
+		}},
+		{HelpNone, func(c *Command) string { return "" }},
+	}
+	for _, testCase := range testCases {
+		b := &strings.Builder{}
+		cli := CLI{
+			HelpWriter:     b,
+			UsageErrorHelp: testCase.verbosity,
+		}
+		c := cli.New("test", nil)
+		err := c.ParseArgs([]string{"--undefined"}).Run()
+		assert.Error(t, err)
+		assert.Equal(t, testCase.expected(c), b.String())
+
+		// Explicitly requested help is always printed in full.
+		b.Reset()
+		err = c.ParseArgs([]string{"--help"}).Run()
+		assert.Equal(t, ErrHelp, err)
+		assert.Equal(t, c.HelpString(), b.String())
+	}
+}
+
+func TestFieldErrorHelp(t *testing.T) {
+	b := &strings.Builder{}
+	cli := CLI{
+		HelpWriter: b,
+		LookupEnv: func(key string) (string, bool, error) {
+			return "not-a-number", key == "COUNT", nil
+		},
+	}
+	cmd := &struct {
+		Level int `cli:"short=l,help=the level"`
+		Count int `cli:"env=COUNT"`
+		Other string
+	}{Level: 1}
+
+	err := cli.New("test", cmd).ParseArgs([]string{"-l", "high"}).Run()
+	require.Error(t, err)
+	var fieldErr *FieldError
+	require.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, "level", fieldErr.Name)
+	assert.Contains(t, err.Error(), `invalid value "high" for flag l`)
+	expected := "USAGE:\n" +
+		"    test [OPTIONS]\n" +
+		"    test help\n" +
+		"\n" +
+		"OPTIONS:\n" +
+		"    -l, --level <VALUE>  the level  (default: 1)\n" +
+		"\n"
+	assert.Equal(t, expected, b.String())
+
+	b.Reset()
+	err = cli.New("test", cmd).ParseArgs([]string{"--level", "2"}).Run()
+	require.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, "count", fieldErr.Name)
+	assert.Contains(t, b.String(), "--count <VALUE>")
+	assert.NotContains(t, b.String(), "--level")
+}
+
+func TestHelpTopicFlag(t *testing.T) {
+	cmd := &struct {
+		Timeout int `cli:"help=request timeout,longhelp='The request timeout, in seconds.\nZero disables the timeout.'"`
+		Other   string
+	}{Timeout: 5}
+	expected := "USAGE:\n" +
+		"    test [OPTIONS]\n" +
+		"    test help\n" +
+		"\n" +
+		"OPTIONS:\n" +
+		"    --timeout <VALUE>  request timeout  (default: 5)\n" +
+		"\n" +
+		"    The request timeout, in seconds.\n" +
+		"    Zero disables the timeout.\n" +
+		"\n"
+
+	for _, args := range [][]string{
+		{"--help=timeout"},
+		{"--help=--timeout"},
+		{"-h", "timeout"},
+	} {
+		b := &strings.Builder{}
+		cli := CLI{HelpWriter: b}
+		err := cli.New("test", cmd).ParseArgs(args).Run()
+		assert.Equal(t, ErrHelp, err)
+		assert.Equal(t, expected, b.String(), args)
+	}
+
+	r := New("test", cmd).ParseArgs([]string{"--help=unknown"})
+	assert.Error(t, r.Err)
+	assert.NotEqual(t, ErrHelp, r.Err)
+
+	r = New("test", cmd).ParseArgs([]string{"--help=false"})
+	assert.NoError(t, r.Err)
+}
+
+func TestHelpTopics(t *testing.T) {
+	b := &strings.Builder{}
+	newCommand := func() *Command {
+		cli := CLI{HelpWriter: b}
+		return cli.New(
+			"test", nil,
+			cli.New("sub", nil).
+				AddHelpTopic("formats", "Output formats", "Supported formats are json and text."),
+			WithHelpTopic("environment", "Environment variables", "FOO sets foo.\nBAR sets bar."),
+		)
+	}
+
+	help := newCommand().HelpString()
+	assert.Contains(t, help, "    test help [COMMAND...] [TOPIC]\n")
+	assert.Regexp(t, `HELP TOPICS:\n +environment +Environment variables\n`, help)
+
+	expected := "Environment variables\n" +
+		"\n" +
+		"    FOO sets foo.\n" +
+		"    BAR sets bar.\n" +
+		"\n"
+	for _, args := range [][]string{
+		{"help", "environment"},
+		{"--help=environment"},
+		{"--help", "environment"},
+	} {
+		b.Reset()
+		err := newCommand().ParseArgs(args).Run()
+		assert.Equal(t, ErrHelp, err, args)
+		assert.Equal(t, expected, b.String(), args)
+	}
+
+	b.Reset()
+	err := newCommand().ParseArgs([]string{"help", "sub", "formats"}).Run()
+	assert.Equal(t, ErrHelp, err)
+	assert.Equal(t, "Output formats\n\n    Supported formats are json and text.\n\n", b.String())
+
+	r := newCommand().ParseArgs([]string{"help", "formats"})
+	assert.Error(t, r.Err)
+	assert.NotEqual(t, ErrHelp, r.Err)
+}
+
+func TestHelpCommandFilter(t *testing.T) {
+	b := &strings.Builder{}
+	newCommand := func() *Command {
+		cli := CLI{HelpWriter: b, HelpCommandLimit: 2}
+		return cli.New(
+			"test", nil,
+			cli.New("net-list", nil, WithHelp("list networks")),
+			cli.New("vm-list", nil, WithHelp("list virtual machines")),
+			cli.New("volume", nil, WithHelp("manage volumes attached to a network")),
+			cli.New("user", nil),
+		)
+	}
+
+	help := newCommand().HelpString()
+	assert.Regexp(t, `COMMANDS:\n +net-list +list networks\n +vm-list +list virtual machines\n`, help)
+	assert.Contains(t, help, "    ... and 2 more (use \"test help --filter <TEXT>\" to search)\n")
+	assert.NotContains(t, help, "user")
+
+	for _, args := range [][]string{
+		{"help", "--filter", "NET"},
+		{"help", "--filter=net"},
+	} {
+		b.Reset()
+		err := newCommand().ParseArgs(args).Run()
+		assert.Equal(t, ErrHelp, err, args)
+		assert.Regexp(t, `COMMANDS MATCHING "(?i:net)":\n +net-list +list networks\n +volume +manage volumes attached to a network\n\n$`, b.String(), args)
+	}
+
+	b.Reset()
+	err := newCommand().ParseArgs([]string{"help", "--filter", "nothing"}).Run()
+	assert.Equal(t, ErrHelp, err)
+	assert.Contains(t, b.String(), "COMMANDS MATCHING \"nothing\":\n    none\n")
+
+	r := newCommand().ParseArgs([]string{"help", "--filter"})
+	assert.Error(t, r.Err)
+	assert.NotEqual(t, ErrHelp, r.Err)
+}
+
+func TestCommandPath(t *testing.T) {
+	root := New(
+		"test", &struct{}{},
+		New("sub", &struct{}{}, New("child", &struct{}{})),
+	)
+	assert.Equal(t, []string{"test"}, root.Path())
+
+	r := root.ParseArgs([]string{"sub", "child"})
+	require.NoError(t, r.Err)
+	assert.Equal(t, []string{"test", "sub", "child"}, r.CommandPath())
+	assert.Equal(t, "test sub child", r.Command.FullName())
+
+	assert.Nil(t, ParseResult{}.CommandPath())
+}
+
+type markdownHelpRenderer struct{}
+
+func (markdownHelpRenderer) Render(w io.Writer, data HelpData) error {
+	fmt.Fprintf(w, "# %s\n", data.FullName)
+	for _, f := range data.Fields {
+		fmt.Fprintf(w, "- `--%s`: %s\n", f.Name, f.Help)
+	}
+	for _, c := range data.Commands {
+		fmt.Fprintf(w, "- %s\n", c.Name)
+	}
+	return nil
+}
+
+func TestHelpRenderer(t *testing.T) {
+	cli := CLI{HelpRenderer: markdownHelpRenderer{}}
+	cmd := cli.New(
+		"test",
+		&struct {
+			Verbose bool `cli:"help=verbose output"`
+		}{},
+		cli.New("sub", &struct{}{}),
+	)
+	assert.Equal(
+		t,
+		"# test\n- `--help`: show usage help\n- `--verbose`: verbose output\n- sub\n",
+		cmd.HelpString(),
+	)
+	assert.Equal(t, "USAGE:\n    test [OPTIONS] <COMMAND>\n    test help [COMMAND...]\n", usageString(cmd))
+
+	b := &strings.Builder{}
+	require.NoError(t, DefaultHelpRenderer.Render(b, cmd.helpData()))
+	assert.Equal(t, New("test", &struct {
+		Verbose bool `cli:"help=verbose output"`
+	}{}, New("sub", &struct{}{})).HelpString(), b.String())
+}
+
+type failingHelpRenderer struct{}
+
+func (failingHelpRenderer) Render(w io.Writer, data HelpData) error {
+	fmt.Fprintf(w, "partial")
+	return fmt.Errorf("boom")
+}
+
+func TestHelpRendererError(t *testing.T) {
+	errBuf := &strings.Builder{}
+	cli := CLI{HelpRenderer: failingHelpRenderer{}, ErrWriter: errBuf}
+	type Cmd struct {
+		Verbose bool `cli:"help=verbose output"`
+	}
+	cmd := cli.New("test", &Cmd{})
+	assert.Equal(t, New("test", &Cmd{}).HelpString(), cmd.HelpString())
+	assert.Equal(t, "cli: error rendering help: boom\n", errBuf.String())
+}
+
+func usageString(cmd *Command) string {
+	b := &strings.Builder{}
+	cmd.WriteUsage(b)
+	return b.String()
+}
@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"bytes"
+	"sync"
+)
+
+type helpCacheEntry struct {
+	generation uint64
+	available  string
+	text       []byte
+}
+
+// helpCache stores rendered help text for a Command, by template name.
+// Entries are invalidated by incrementing generation whenever anything the
+// Command's help text depends on is modified.
+type helpCache struct {
+	mu         sync.Mutex
+	generation uint64
+	entries    map[string]helpCacheEntry
+}
+
+// invalidateHelpCache invalidates the command's cached help text.
+func (cmd *Command) invalidateHelpCache() {
+	cmd.helpCache.mu.Lock()
+	defer cmd.helpCache.mu.Unlock()
+	cmd.helpCache.generation += 1
+}
+
+// invalidateParentHelpCache invalidates the cached help text of the
+// command's parent, which lists the command as a subcommand.
+func (cmd *Command) invalidateParentHelpCache() {
+	if cmd.parent != nil {
+		cmd.parent.invalidateHelpCache()
+	}
+}
+
+// invalidateHelpCacheTree invalidates the cached help text of the command
+// and all of its subcommands, e.g. when their full names change.
+func (cmd *Command) invalidateHelpCacheTree() {
+	cmd.invalidateHelpCache()
+	for _, subCmd := range cmd.commands {
+		subCmd.invalidateHelpCacheTree()
+	}
+}
+
+// helpAvailability returns a string describing which of the command's
+// experimental fields and subcommands are available. Since availability
+// depends on the environment, parent commands, and CLI.FeatureEnabled, it is
+// checked each time help is rendered rather than being tracked by
+// invalidation.
+func (cmd *Command) helpAvailability() string {
+	b := []byte{}
+	for _, f := range cmd.fields {
+		if f.Experimental {
+			b = appendAvailable(b, cmd.fieldAvailable(f))
+		}
+	}
+	for _, subCmd := range cmd.commands {
+		if subCmd.experimental {
+			b = appendAvailable(b, cmd.commandAvailable(subCmd))
+		}
+	}
+	return string(b)
+}
+
+func appendAvailable(b []byte, available bool) []byte {
+	if available {
+		return append(b, '1')
+	}
+	return append(b, '0')
+}
+
+// renderHelp returns the result of executing the named help template for the
+// command, using the cached result if CLI.CacheHelp is enabled and nothing
+// has changed since it was rendered.
+func (cmd *Command) renderHelp(name string) []byte {
+	if !cmd.cli.CacheHelp {
+		b := bytes.Buffer{}
+		cmd.executeHelp(&b, name)
+		return b.Bytes()
+	}
+
+	available := cmd.helpAvailability()
+	cmd.helpCache.mu.Lock()
+	defer cmd.helpCache.mu.Unlock()
+	generation := cmd.helpCache.generation
+	if entry, ok := cmd.helpCache.entries[name]; ok && entry.generation == generation && entry.available == available {
+		return entry.text
+	}
+
+	b := bytes.Buffer{}
+	cmd.executeHelp(&b, name)
+	if cmd.helpCache.entries == nil {
+		cmd.helpCache.entries = map[string]helpCacheEntry{}
+	}
+	cmd.helpCache.entries[name] = helpCacheEntry{
+		generation: generation,
+		available:  available,
+		text:       b.Bytes(),
+	}
+	return b.Bytes()
+}
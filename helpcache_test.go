@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type helpCacheTestCmd struct {
+	Timeout time.Duration
+}
+
+func TestHelpCache(t *testing.T) {
+	cli := NewCLI()
+	cli.CacheHelp = true
+	sub := cli.New("sub", &struct{}{})
+	c := cli.New("test", &helpCacheTestCmd{}, sub)
+
+	first := c.renderHelp("help")
+	assert.Equal(t, c.HelpString(), string(first))
+	second := c.renderHelp("help")
+	assert.Same(t, &first[0], &second[0], "help should be cached")
+
+	sub.SetHelp("a subcommand")
+	assert.Regexp(t, `sub +a subcommand`, c.HelpString())
+
+	require.NoError(t, c.ParseArgs([]string{"--timeout", "5s", "sub"}).Err)
+	cached := c.HelpString()
+	cli.CacheHelp = false
+	assert.Equal(t, c.HelpString(), cached)
+}
+
+func TestHelpCachePerCommand(t *testing.T) {
+	cli := NewCLI()
+	cli.CacheHelp = true
+	a := cli.New("a", &helpCacheTestCmd{})
+	b := cli.New("b", &helpCacheTestCmd{})
+
+	first := b.renderHelp("help")
+	require.NoError(t, a.ParseArgs([]string{"--timeout", "5s"}).Err)
+	second := b.renderHelp("help")
+	assert.Same(t, &first[0], &second[0], "help of other commands should stay cached")
+}
+
+func TestHelpCacheAvailability(t *testing.T) {
+	features := map[string]bool{}
+	cli := NewCLI()
+	cli.CacheHelp = true
+	cli.FeatureEnabled = func(name string) bool {
+		return features[name]
+	}
+	root := &struct {
+		ExperimentalOptions
+	}{}
+	c := cli.New(
+		"test", root,
+		cli.New("stable", &struct{}{}),
+		cli.New("beta", &struct{}{}).SetExperimental(true),
+	)
+	assert.NotRegexp(t, `(?m)^\s+beta`, c.HelpString())
+
+	features["beta"] = true
+	assert.Regexp(t, `(?m)^\s+beta`, c.HelpString())
+
+	features["beta"] = false
+	assert.NotRegexp(t, `(?m)^\s+beta`, c.HelpString())
+
+	root.EnableExperimental = true
+	assert.Regexp(t, `(?m)^\s+beta`, c.HelpString())
+}
+
+func TestHelpCacheDisabled(t *testing.T) {
+	c := New("test", &helpCacheTestCmd{})
+	first := c.renderHelp("help")
+	second := c.renderHelp("help")
+	assert.Equal(t, first, second)
+	assert.NotSame(t, &first[0], &second[0])
+}
@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"context"
+	"os"
+)
+
+// NoInputOptions can be embedded in a command config to add a standard
+// --no-input flag (also settable with the NO_INPUT environment variable) which
+// disables interactive prompts. Commands should check Interactive(ctx) rather
+// than reading the flag directly, since non-interactive mode is also enabled
+// automatically when stdin is not a terminal or CI=true is set.
+type NoInputOptions struct {
+	NoInput bool `cli:"name=no-input,env=NO_INPUT,help=disable interactive prompts"`
+}
+
+func (o *NoInputOptions) noInput() bool {
+	return o.NoInput
+}
+
+type noInputer interface {
+	noInput() bool
+}
+
+type interactiveContextKey struct{}
+
+// WithInteractive returns a copy of ctx in which Interactive returns the given
+// value.
+func WithInteractive(ctx context.Context, interactive bool) context.Context {
+	return context.WithValue(ctx, interactiveContextKey{}, interactive)
+}
+
+// Interactive returns false if the command is running in non-interactive
+// mode, in which case prompts, confirmations, and spinners should be skipped
+// (using defaults or failing instead of asking for input). ParseResult.Run
+// passes a context to commands with this set according to any embedded
+// NoInputOptions; otherwise (or if that flag isn't passed) it is determined by
+// whether stdin is a terminal and the CI environment variable.
+func Interactive(ctx context.Context) bool {
+	if interactive, ok := ctx.Value(interactiveContextKey{}).(bool); ok {
+		return interactive
+	}
+	return defaultInteractive()
+}
+
+func defaultInteractive() bool {
+	if isCIEnvSet() {
+		return false
+	}
+	return IsTerminal(os.Stdin)
+}
+
+// noInput returns true if --no-input was passed to this command or any of
+// its parents.
+func (cmd *Command) noInput() bool {
+	for c := cmd; c != nil; c = c.parent {
+		if ni, ok := c.config.(noInputer); ok && ni.noInput() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type interactiveTestCmd struct {
+	NoInputOptions
+	interactive bool
+}
+
+func (c *interactiveTestCmd) Run(ctx context.Context) error {
+	c.interactive = Interactive(ctx)
+	return nil
+}
+
+func TestNoInput(t *testing.T) {
+	t.Setenv("NO_INPUT", "")
+	os.Unsetenv("NO_INPUT")
+	ctx := WithInteractive(context.Background(), true)
+
+	c := &interactiveTestCmd{}
+	err := New("test", c).ParseArgs([]string{}).RunWithContext(ctx)
+	require.NoError(t, err)
+	assert.True(t, c.interactive)
+
+	c = &interactiveTestCmd{}
+	err = New("test", c).ParseArgs([]string{"--no-input"}).RunWithContext(ctx)
+	require.NoError(t, err)
+	assert.False(t, c.interactive)
+
+	c = &interactiveTestCmd{}
+	err = New("test", &struct{ NoInputOptions }{NoInputOptions{NoInput: true}},
+		New("sub", c),
+	).ParseArgs([]string{"sub"}).RunWithContext(ctx)
+	require.NoError(t, err)
+	assert.False(t, c.interactive)
+}
+
+func TestInteractiveCI(t *testing.T) {
+	t.Setenv("CI", "true")
+	assert.False(t, Interactive(context.Background()))
+	assert.True(t, Interactive(WithInteractive(context.Background(), true)))
+}
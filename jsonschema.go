@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"time"
+)
+
+// JSONSchema returns a JSON Schema (draft 2020-12) describing a config file
+// (see CLI.ConfigFile) for the given config struct, using the default CLI.
+func JSONSchema(config interface{}) ([]byte, error) {
+	return defaultCLI.JSONSchema(config)
+}
+
+// JSONSchema returns a JSON Schema (draft 2020-12) describing a config file
+// (see CLI.ConfigFile) for the given config struct, so that users can
+// validate config files and get autocompletion in editors. Each field is a
+// property named after its flag, with its help text as the description.
+// Required fields are listed as required, so schemas for configs with
+// required fields are only suitable for config files which set every
+// required field.
+func (cli *CLI) JSONSchema(config interface{}) ([]byte, error) {
+	fields, _, err := cli.getFieldsFromConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	properties := map[string]interface{}{}
+	required := []string{}
+	for _, f := range fields {
+		properties[f.Name] = fieldJSONSchema(f)
+		if f.Required {
+			required = append(required, f.Name)
+		}
+	}
+	schema := map[string]interface{}{
+		"$schema":    "https://json-schema.org/draft/2020-12/schema",
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+func fieldJSONSchema(f field) map[string]interface{} {
+	t := f.value.target.Type()
+	var schema map[string]interface{}
+	if _, ok := f.value.tags["append"]; ok && t.Kind() == reflect.Slice {
+		schema = map[string]interface{}{
+			"type":  "array",
+			"items": typeJSONSchema(t.Elem()),
+		}
+	} else {
+		schema = typeJSONSchema(t)
+	}
+
+	if f.Help != "" {
+		schema["description"] = f.Help
+	}
+	if f.Secret {
+		schema["writeOnly"] = true
+	} else if !f.Unset() && !f.value.target.IsZero() {
+		switch schema["type"] {
+		case "boolean", "integer", "number":
+			if b, err := json.Marshal(f.value.target.Interface()); err == nil {
+				schema["default"] = json.RawMessage(b)
+			}
+		case "string":
+			schema["default"] = f.Default()
+		}
+	}
+	return schema
+}
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// typeJSONSchema returns a JSON Schema for values of type t, as they would
+// be written in a config file.
+func typeJSONSchema(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == durationType || reflect.PtrTo(t).Implements(textUnmarshalerType) {
+		return map[string]interface{}{"type": "string"}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Map, reflect.Struct:
+		// Objects are passed to the field's setter as JSON strings.
+		return map[string]interface{}{"type": []string{"object", "string"}}
+	default:
+		return map[string]interface{}{"type": "string"}
+	}
+}
@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONSchema(t *testing.T) {
+	config := &struct {
+		Host    string        `cli:"required,help=host to connect to"`
+		Port    uint16        `cli:"help=port to connect to"`
+		Timeout time.Duration `cli:"help=connection timeout"`
+		Verbose bool
+		Ratio   float64
+		Token   string   `cli:"secret"`
+		Tags    []string `cli:"append"`
+		Limit   *int
+	}{
+		Port:    5432,
+		Timeout: 5 * time.Second,
+		Token:   "hunter2",
+	}
+	b, err := JSONSchema(config)
+	require.NoError(t, err)
+
+	expected := `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "object",
+		"properties": {
+			"host": {"type": "string", "description": "host to connect to"},
+			"port": {"type": "integer", "minimum": 0, "description": "port to connect to", "default": 5432},
+			"timeout": {"type": "string", "description": "connection timeout", "default": "5s"},
+			"verbose": {"type": "boolean"},
+			"ratio": {"type": "number"},
+			"token": {"type": "string", "writeOnly": true},
+			"tags": {"type": "array", "items": {"type": "string"}},
+			"limit": {"type": "integer"}
+		},
+		"required": ["host"]
+	}`
+	assert.JSONEq(t, expected, string(b))
+}
@@ -0,0 +1,22 @@
+module github.com/isobit/cli/kafkaopts
+
+go 1.22
+
+require (
+	github.com/isobit/cli v0.0.0
+	github.com/stretchr/testify v1.9.0
+	github.com/twmb/franz-go v1.17.1
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/huandu/xstrings v1.4.0 // indirect
+	github.com/klauspost/compress v1.17.8 // indirect
+	github.com/pierrec/lz4/v4 v4.1.21 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/twmb/franz-go/pkg/kmsg v1.8.0 // indirect
+	golang.org/x/crypto v0.23.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/isobit/cli => ../
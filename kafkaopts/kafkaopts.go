@@ -0,0 +1,133 @@
+// Package kafkaopts provides standard Kafka connection flags which build
+// client options for github.com/twmb/franz-go:
+//
+//	type Consume struct {
+//		kafkaopts.Options
+//	}
+//
+//	func (c *Consume) Run(ctx context.Context) error {
+//		client, err := c.NewClient(kgo.ConsumeTopics("events"))
+//		if err != nil {
+//			return err
+//		}
+//		defer client.Close()
+//		...
+//	}
+//
+// This package is a separate module so that the cli module does not depend on
+// franz-go.
+package kafkaopts
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/isobit/cli/tlsopts"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/sasl"
+	"github.com/twmb/franz-go/pkg/sasl/plain"
+	"github.com/twmb/franz-go/pkg/sasl/scram"
+)
+
+// Options can be embedded in a command config to add Kafka connection flags.
+type Options struct {
+	Brokers               string `cli:"name=kafka-brokers,env=KAFKA_BROKERS,placeholder=HOST:PORT[...],help=comma-separated list of Kafka brokers to bootstrap from"`
+	ClientID              string `cli:"name=kafka-client-id,env=KAFKA_CLIENT_ID,placeholder=ID,help=client ID to identify as"`
+	GroupID               string `cli:"name=kafka-group-id,env=KAFKA_GROUP_ID,placeholder=ID,help=consumer group to join"`
+	TLS                   bool   `cli:"name=kafka-tls,env=KAFKA_TLS,help=connect to brokers using TLS"`
+	TLSCAFile             string `cli:"name=kafka-tls-ca,env=KAFKA_TLS_CA,placeholder=PATH,help=PEM file of CA certificates to trust"`
+	TLSCertFile           string `cli:"name=kafka-tls-cert,env=KAFKA_TLS_CERT,placeholder=PATH,help=PEM client certificate file"`
+	TLSKeyFile            string `cli:"name=kafka-tls-key,env=KAFKA_TLS_KEY,placeholder=PATH,help=PEM client key file"`
+	TLSInsecureSkipVerify bool   `cli:"name=kafka-tls-insecure-skip-verify,help=skip broker certificate verification (insecure)"`
+	SASLMechanism         string `cli:"name=kafka-sasl-mechanism,env=KAFKA_SASL_MECHANISM,placeholder=MECHANISM,help=SASL mechanism (PLAIN/SCRAM-SHA-256/SCRAM-SHA-512)"`
+	SASLUsername          string `cli:"name=kafka-sasl-username,env=KAFKA_SASL_USERNAME,placeholder=USER,help=SASL username"`
+	SASLPassword          string `cli:"name=kafka-sasl-password,env=KAFKA_SASL_PASSWORD,secret,placeholder=PASSWORD,help=SASL password"`
+}
+
+// BrokerList returns the brokers given by the comma-separated Brokers option.
+func (opts *Options) BrokerList() []string {
+	var brokers []string
+	for _, b := range strings.Split(opts.Brokers, ",") {
+		if b = strings.TrimSpace(b); b != "" {
+			brokers = append(brokers, b)
+		}
+	}
+	return brokers
+}
+
+// Build validates the options and returns the corresponding client options.
+func (opts *Options) Build() ([]kgo.Opt, error) {
+	brokers := opts.BrokerList()
+	if len(brokers) == 0 {
+		return nil, fmt.Errorf("no Kafka brokers specified (use --kafka-brokers)")
+	}
+	kopts := []kgo.Opt{kgo.SeedBrokers(brokers...)}
+	if opts.ClientID != "" {
+		kopts = append(kopts, kgo.ClientID(opts.ClientID))
+	}
+	if opts.GroupID != "" {
+		kopts = append(kopts, kgo.ConsumerGroup(opts.GroupID))
+	}
+
+	usesTLS := opts.TLSCAFile != "" || opts.TLSCertFile != "" || opts.TLSInsecureSkipVerify
+	if opts.TLS || usesTLS {
+		tlsConfig, err := tlsopts.Load(opts.TLSCAFile, opts.TLSCertFile, opts.TLSKeyFile, opts.TLSInsecureSkipVerify)
+		if err != nil {
+			return nil, err
+		}
+		kopts = append(kopts, kgo.DialTLSConfig(tlsConfig))
+	}
+
+	mechanism, err := opts.saslMechanism()
+	if err != nil {
+		return nil, err
+	}
+	if mechanism != nil {
+		kopts = append(kopts, kgo.SASL(mechanism))
+	}
+	return kopts, nil
+}
+
+func (opts *Options) saslMechanism() (sasl.Mechanism, error) {
+	if opts.SASLMechanism == "" {
+		if opts.SASLUsername != "" || opts.SASLPassword != "" {
+			return nil, fmt.Errorf("--kafka-sasl-mechanism is required when a SASL username or password is given")
+		}
+		return nil, nil
+	}
+	if opts.SASLUsername == "" || opts.SASLPassword == "" {
+		return nil, fmt.Errorf("SASL mechanism %s requires a username and password", opts.SASLMechanism)
+	}
+	user, pass := opts.SASLUsername, opts.SASLPassword
+	switch strings.ToUpper(opts.SASLMechanism) {
+	case "PLAIN":
+		return plain.Auth{User: user, Pass: pass}.AsMechanism(), nil
+	case "SCRAM-SHA-256":
+		return scram.Auth{User: user, Pass: pass}.AsSha256Mechanism(), nil
+	case "SCRAM-SHA-512":
+		return scram.Auth{User: user, Pass: pass}.AsSha512Mechanism(), nil
+	default:
+		return nil, fmt.Errorf("unsupported SASL mechanism %q: must be one of: PLAIN, SCRAM-SHA-256, SCRAM-SHA-512", opts.SASLMechanism)
+	}
+}
+
+// NewClient creates a client with the options built by Build, followed by
+// extraOpts.
+func (opts *Options) NewClient(extraOpts ...kgo.Opt) (*kgo.Client, error) {
+	kopts, err := opts.Build()
+	if err != nil {
+		return nil, err
+	}
+	return kgo.NewClient(append(kopts, extraOpts...)...)
+}
+
+// Ping creates a client and checks that the brokers are reachable.
+func (opts *Options) Ping(ctx context.Context) error {
+	client, err := opts.NewClient()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	return client.Ping(ctx)
+}
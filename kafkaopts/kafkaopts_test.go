@@ -0,0 +1,72 @@
+package kafkaopts
+
+import (
+	"testing"
+
+	"github.com/isobit/cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+func TestNewClient(t *testing.T) {
+	config := &struct {
+		Options
+	}{}
+	cmd := cli.New("test", config)
+	help := cmd.HelpString()
+	assert.Contains(t, help, "--kafka-brokers <HOST:PORT[...]>")
+	assert.Contains(t, help, "--kafka-sasl-password <PASSWORD>")
+
+	require.NoError(t, cmd.ParseArgs([]string{
+		"--kafka-brokers", "kafka-1:9092, kafka-2:9092",
+		"--kafka-client-id", "etl",
+		"--kafka-group-id", "etl-group",
+		"--kafka-tls",
+		"--kafka-sasl-mechanism", "scram-sha-512",
+		"--kafka-sasl-username", "etl",
+		"--kafka-sasl-password", "hunter2",
+	}).Err)
+	assert.Equal(t, []string{"kafka-1:9092", "kafka-2:9092"}, config.BrokerList())
+
+	client, err := config.NewClient()
+	require.NoError(t, err)
+	defer client.Close()
+	assert.Equal(t, []string{"kafka-1:9092", "kafka-2:9092"}, client.OptValue(kgo.SeedBrokers))
+	assert.Equal(t, "etl", client.OptValue(kgo.ClientID))
+	assert.Equal(t, "etl-group", client.OptValue(kgo.ConsumerGroup))
+	assert.NotNil(t, client.OptValue(kgo.DialTLSConfig))
+}
+
+func TestBuildErrors(t *testing.T) {
+	for _, tc := range []struct {
+		opts Options
+		err  string
+	}{
+		{Options{}, "no Kafka brokers specified (use --kafka-brokers)"},
+		{Options{Brokers: " , "}, "no Kafka brokers specified (use --kafka-brokers)"},
+		{
+			Options{Brokers: "kafka:9092", SASLUsername: "etl"},
+			"--kafka-sasl-mechanism is required when a SASL username or password is given",
+		},
+		{
+			Options{Brokers: "kafka:9092", SASLMechanism: "PLAIN", SASLUsername: "etl"},
+			"SASL mechanism PLAIN requires a username and password",
+		},
+		{
+			Options{Brokers: "kafka:9092", SASLMechanism: "GSSAPI", SASLUsername: "etl", SASLPassword: "x"},
+			`unsupported SASL mechanism "GSSAPI": must be one of: PLAIN, SCRAM-SHA-256, SCRAM-SHA-512`,
+		},
+		{
+			Options{Brokers: "kafka:9092", TLSCertFile: "cert.pem"},
+			"client certificate and key files must be given together",
+		},
+	} {
+		_, err := tc.opts.Build()
+		assert.EqualError(t, err, tc.err)
+	}
+
+	kopts, err := (&Options{Brokers: "kafka:9092", SASLMechanism: "PLAIN", SASLUsername: "etl", SASLPassword: "x"}).Build()
+	require.NoError(t, err)
+	assert.Len(t, kopts, 2)
+}
@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+)
+
+// WithLicenses returns a CommandOption which adds a hidden "licenses"
+// subcommand that prints the contents of every file in fsys, which will
+// usually be license and third-party notices embedded using go:embed:
+//
+//	//go:embed licenses
+//	var licenses embed.FS
+//
+//	cli.New("mycli", &MyCLI{}, cli.WithLicenses(licenses))
+//
+// If fsys contains more than one file, each file's contents are preceded by a
+// header with its path.
+func WithLicenses(fsys fs.FS) CommandOption {
+	return commandOptionFunc(func(cmd *Command) {
+		licensesCmd := cmd.cli.New(
+			"licenses",
+			&licensesCommand{fsys: fsys},
+			WithHelp("print license notices"),
+			WithHidden(true),
+		)
+		cmd.AddCommand(licensesCmd)
+	})
+}
+
+type licensesCommand struct {
+	fsys fs.FS
+}
+
+func (cmd *licensesCommand) Run(ctx context.Context) error {
+	w := Stdout(ctx)
+
+	paths := []string{}
+	err := fs.WalkDir(cmd.fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for i, path := range paths {
+		b, err := fs.ReadFile(cmd.fsys, path)
+		if err != nil {
+			return err
+		}
+		if len(paths) > 1 {
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			fmt.Fprintf(w, "==> %s <==\n", path)
+		}
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
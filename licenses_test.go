@@ -0,0 +1,37 @@
+package cli
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLicenses(t *testing.T) {
+	fsys := fstest.MapFS{
+		"LICENSE":                 {Data: []byte("MIT License\n")},
+		"third_party/foo/LICENSE": {Data: []byte("Apache License\n")},
+	}
+	b := &strings.Builder{}
+	cli := NewCLI()
+	cli.Stdout = b
+	c := cli.New("test", &struct{}{}, WithLicenses(fsys))
+	assert.NotContains(t, c.HelpString(), "licenses")
+
+	r := c.ParseArgs([]string{"licenses"})
+	require.NoError(t, r.Err)
+	require.NoError(t, r.Run())
+	assert.Equal(t, "==> LICENSE <==\nMIT License\n\n==> third_party/foo/LICENSE <==\nApache License\n", b.String())
+}
+
+func TestLicensesSingleFile(t *testing.T) {
+	b := &strings.Builder{}
+	cmd := &licensesCommand{
+		fsys: fstest.MapFS{"NOTICE": {Data: []byte("notice\n")}},
+	}
+	require.NoError(t, cmd.Run(WithStdio(context.Background(), nil, b, nil)))
+	assert.Equal(t, "notice\n", b.String())
+}
@@ -0,0 +1,155 @@
+// Package localeopts provides standard --timezone and --locale flags for
+// commands which report or parse times and other locale-sensitive values
+// across regions:
+//
+//	type Report struct {
+//		localeopts.Options
+//		Period cli.TimeRange
+//	}
+//
+//	func (r *Report) Run() error {
+//		period := r.TimeRange(r.Period) // dates are in --timezone
+//		...
+//	}
+//
+// Time zone names are validated against the IANA time zone database, which is
+// embedded (see time/tzdata) so that validation works on systems without it.
+package localeopts
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/isobit/cli"
+)
+
+// Timezone is a field type for IANA time zone names (e.g. "America/New_York"),
+// "UTC", or "Local". The zero value is time.Local, which is determined by the
+// TZ environment variable or the system's configuration.
+type Timezone struct {
+	loc *time.Location
+}
+
+func (tz *Timezone) Set(s string) error {
+	loc, err := time.LoadLocation(s)
+	if err != nil || s == "" {
+		return fmt.Errorf("unknown time zone %q", s)
+	}
+	tz.loc = loc
+	return nil
+}
+
+func (tz Timezone) String() string {
+	return tz.Location().String()
+}
+
+func (Timezone) Placeholder() string {
+	return "ZONE"
+}
+
+// Location returns the time zone's location.
+func (tz Timezone) Location() *time.Location {
+	if tz.loc == nil {
+		return time.Local
+	}
+	return tz.loc
+}
+
+// Locale is a field type for locales, given either as BCP 47 language tags
+// (e.g. "en-US" or "zh-Hant-TW") or in the POSIX format used by LANG (e.g.
+// "en_US.UTF-8"). Values are normalized to BCP 47 tags; the POSIX "C" locale
+// is normalized to "und" (undetermined).
+type Locale struct {
+	tag string
+}
+
+var localeRegexp = regexp.MustCompile(`^([a-zA-Z]{2,3})(?:-([a-zA-Z]{4}))?(?:-([a-zA-Z]{2}|[0-9]{3}))?$`)
+
+func (l *Locale) Set(s string) error {
+	tag, ok := parseLocale(s)
+	if !ok {
+		return fmt.Errorf("invalid locale %q: expected a language tag like en-US", s)
+	}
+	l.tag = tag
+	return nil
+}
+
+func parseLocale(s string) (string, bool) {
+	// Strip the POSIX charset and modifier, e.g. "en_US.UTF-8@euro".
+	if i := strings.IndexAny(s, ".@"); i >= 0 {
+		s = s[:i]
+	}
+	if s == "C" || s == "POSIX" {
+		return "und", true
+	}
+	m := localeRegexp.FindStringSubmatch(strings.ReplaceAll(s, "_", "-"))
+	if m == nil {
+		return "", false
+	}
+	tag := strings.ToLower(m[1])
+	if m[2] != "" {
+		tag += "-" + strings.ToUpper(m[2][:1]) + strings.ToLower(m[2][1:])
+	}
+	if m[3] != "" {
+		tag += "-" + strings.ToUpper(m[3])
+	}
+	return tag, true
+}
+
+func (l Locale) String() string {
+	return l.tag
+}
+
+func (Locale) Placeholder() string {
+	return "LOCALE"
+}
+
+// Tag returns the locale's BCP 47 language tag, or an empty string if it has
+// not been set.
+func (l Locale) Tag() string {
+	return l.tag
+}
+
+// Options can be embedded in a command config to add --timezone and --locale
+// flags.
+type Options struct {
+	Timezone Timezone `cli:"name=timezone,help=time zone for dates and times as an IANA name (e.g. America/New_York)"`
+	Locale   Locale   `cli:"name=locale,help=locale for formatting as a language tag like en-US (defaults to $LANG)"`
+}
+
+// Location returns the location of the --timezone flag, which defaults to
+// time.Local.
+func (opts *Options) Location() *time.Location {
+	return opts.Timezone.Location()
+}
+
+// LocaleTag returns the BCP 47 tag of the --locale flag, falling back to the
+// locale given by the LC_ALL, LC_MESSAGES, or LANG environment variables (in
+// that order), or "und" if none of them are set to a valid locale.
+func (opts *Options) LocaleTag() string {
+	if tag := opts.Locale.Tag(); tag != "" {
+		return tag
+	}
+	for _, key := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if tag, ok := parseLocale(os.Getenv(key)); ok {
+			return tag
+		}
+	}
+	return "und"
+}
+
+// ParseTime parses a time with time.ParseInLocation, interpreting times
+// without an offset in the --timezone location.
+func (opts *Options) ParseTime(layout, value string) (time.Time, error) {
+	return time.ParseInLocation(layout, value, opts.Location())
+}
+
+// TimeRange returns r with any dates and times written without an offset
+// interpreted in the --timezone location (see cli.TimeRange.In).
+func (opts *Options) TimeRange(r cli.TimeRange) cli.TimeRange {
+	return r.In(opts.Location())
+}
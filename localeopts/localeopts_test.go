@@ -0,0 +1,101 @@
+package localeopts
+
+import (
+	"testing"
+	"time"
+
+	"github.com/isobit/cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimezone(t *testing.T) {
+	var tz Timezone
+	assert.Equal(t, time.Local, tz.Location())
+	assert.Equal(t, "Local", tz.String())
+
+	require.NoError(t, tz.Set("America/New_York"))
+	assert.Equal(t, "America/New_York", tz.Location().String())
+
+	require.NoError(t, tz.Set("UTC"))
+	assert.Equal(t, time.UTC, tz.Location())
+
+	for _, s := range []string{"Mars/Olympus_Mons", ""} {
+		err := tz.Set(s)
+		require.Error(t, err, s)
+		assert.Contains(t, err.Error(), "unknown time zone")
+	}
+}
+
+func TestLocale(t *testing.T) {
+	for in, want := range map[string]string{
+		"en-US":       "en-US",
+		"en_us":       "en-US",
+		"en_US.UTF-8": "en-US",
+		"de_DE@euro":  "de-DE",
+		"zh-hant-tw":  "zh-Hant-TW",
+		"es-419":      "es-419",
+		"fr":          "fr",
+		"C.UTF-8":     "und",
+		"POSIX":       "und",
+	} {
+		var l Locale
+		require.NoError(t, l.Set(in), in)
+		assert.Equal(t, want, l.Tag(), in)
+	}
+
+	for _, in := range []string{"", "english", "en-USA-1", "e"} {
+		var l Locale
+		err := l.Set(in)
+		require.Error(t, err, in)
+		assert.Contains(t, err.Error(), "invalid locale")
+	}
+}
+
+func TestLocaleTagFallback(t *testing.T) {
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "")
+	t.Setenv("LANG", "pt_BR.UTF-8")
+	opts := &Options{}
+	assert.Equal(t, "pt-BR", opts.LocaleTag())
+
+	t.Setenv("LC_ALL", "fr_FR")
+	assert.Equal(t, "fr-FR", opts.LocaleTag())
+
+	require.NoError(t, opts.Locale.Set("ja-JP"))
+	assert.Equal(t, "ja-JP", opts.LocaleTag())
+
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LANG", "")
+	assert.Equal(t, "ja-JP", opts.LocaleTag())
+	assert.Equal(t, "und", (&Options{}).LocaleTag())
+}
+
+func TestOptions(t *testing.T) {
+	config := &struct {
+		Options
+		Period cli.TimeRange
+	}{}
+	cmd := cli.New("test", config)
+	help := cmd.HelpString()
+	assert.Regexp(t, `--timezone <ZONE> +time zone .*\(default: Local\)`, help)
+	assert.Contains(t, help, "--locale <LOCALE>")
+
+	require.NoError(t, cmd.ParseArgs([]string{
+		"--period", "2024-03-01..2024-04-01",
+		"--timezone", "Asia/Tokyo",
+	}).Err)
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+	period := config.TimeRange(config.Period)
+	assert.True(t, period.Start.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, tokyo)))
+	assert.True(t, period.End.Equal(time.Date(2024, 4, 1, 0, 0, 0, 0, tokyo)))
+
+	tm, err := config.ParseTime("2006-01-02 15:04", "2024-03-01 09:00")
+	require.NoError(t, err)
+	assert.Equal(t, "2024-03-01T00:00:00Z", tm.UTC().Format(time.RFC3339))
+
+	r := cli.New("test", config).ParseArgs([]string{"--timezone", "Nowhere"})
+	require.Error(t, r.Err)
+	assert.Contains(t, r.Err.Error(), `unknown time zone "Nowhere"`)
+}
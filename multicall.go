@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ParseMulticall is a convenience function for calling
+// ParseMulticallArgs(os.Args[0], os.Args[1:], cmds...).
+func ParseMulticall(cmds ...*Command) ParseResult {
+	return ParseMulticallArgs(os.Args[0], os.Args[1:], cmds...)
+}
+
+// ParseMulticallArgs allows one binary to provide several tools with distinct
+// command trees (similar to busybox). The root command is chosen from cmds by
+// matching its name against the base name of argv0 (i.e. the name of the
+// binary or symlink the program was invoked as, ignoring any extension such
+// as ".exe"), and args are parsed by that command.
+//
+// If argv0 doesn't match any command, the first arg is used as the command
+// name instead, so that tools can also be invoked as e.g. "mybinary tool
+// args...".
+func ParseMulticallArgs(argv0 string, args []string, cmds ...*Command) ParseResult {
+	name := filepath.Base(argv0)
+	if cmd := findMulticallCommand(name, cmds); cmd != nil {
+		return cmd.ParseArgs(args)
+	}
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+	if cmd := findMulticallCommand(name, cmds); cmd != nil {
+		return cmd.ParseArgs(args)
+	}
+	if len(args) > 0 {
+		if cmd := findMulticallCommand(args[0], cmds); cmd != nil {
+			return cmd.ParseArgs(args[1:])
+		}
+	}
+
+	names := make([]string, len(cmds))
+	for i, cmd := range cmds {
+		names[i] = cmd.name
+	}
+	return ParseResult{
+		Err: UsageErrorf("unknown command %q (available commands: %s)", name, strings.Join(names, ", ")),
+	}
+}
+
+func findMulticallCommand(name string, cmds []*Command) *Command {
+	for _, cmd := range cmds {
+		if cmd.name == name {
+			return cmd
+		}
+	}
+	return nil
+}
+
+// printMulticallError prints an error which occurred before a command could
+// be chosen, in which case there is no CLI ErrWriter to use.
+func printMulticallError(err error) {
+	fmt.Fprintf(os.Stderr, "error: %s\n", err)
+}
@@ -0,0 +1,38 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type multicallTestCmd struct {
+	Verbose bool
+}
+
+func TestParseMulticallArgs(t *testing.T) {
+	a := &multicallTestCmd{}
+	b := &multicallTestCmd{}
+	cmds := []*Command{New("foo", a), New("bar", b)}
+
+	r := ParseMulticallArgs("/usr/local/bin/bar", []string{"--verbose"}, cmds...)
+	require.NoError(t, r.Err)
+	assert.Equal(t, "bar", r.Command.FullName())
+	assert.True(t, b.Verbose)
+	assert.False(t, a.Verbose)
+
+	r = ParseMulticallArgs("/opt/tools/foo.exe", []string{}, cmds...)
+	require.NoError(t, r.Err)
+	assert.Equal(t, "foo", r.Command.FullName())
+
+	r = ParseMulticallArgs("toolbox", []string{"foo", "--verbose"}, cmds...)
+	require.NoError(t, r.Err)
+	assert.Equal(t, "foo", r.Command.FullName())
+	assert.True(t, a.Verbose)
+
+	r = ParseMulticallArgs("toolbox", []string{"baz"}, cmds...)
+	require.Error(t, r.Err)
+	assert.Nil(t, r.Command)
+	assert.Equal(t, `unknown command "toolbox" (available commands: foo, bar)`, r.Err.Error())
+}
@@ -0,0 +1,116 @@
+// Package otelcli provides cli middleware which traces command execution using
+// OpenTelemetry.
+//
+//	cli.NewCLI().
+//		Use(otelcli.Middleware(otelcli.WithTracerProvider(tp))).
+//		New("mycli", &MyCLI{}).
+//		Parse().
+//		RunFatal()
+package otelcli
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/isobit/cli"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/isobit/cli/otelcli"
+
+type options struct {
+	tracerProvider trace.TracerProvider
+	attributes     []attribute.KeyValue
+}
+
+// Option configures the middleware returned by Middleware.
+type Option func(o *options)
+
+// WithTracerProvider sets the TracerProvider used to create spans. If not
+// set, the global TracerProvider is used.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(o *options) {
+		o.tracerProvider = tp
+	}
+}
+
+// WithAttributes adds attributes to every span.
+func WithAttributes(attrs ...attribute.KeyValue) Option {
+	return func(o *options) {
+		o.attributes = append(o.attributes, attrs...)
+	}
+}
+
+type flusher interface {
+	ForceFlush(ctx context.Context) error
+}
+
+// Middleware returns cli.Middleware which starts a span for the command being
+// run, named after the command's full name (e.g. "mycli sub"). The span has a
+// "cli.command" attribute with the full name, and a "cli.flag.<name>"
+// attribute for every flag which was set, except for fields with the secret
+// tag. If the command returns an error, it is recorded on the span.
+//
+// Since commands are usually run just before the process exits, the
+// TracerProvider is flushed once the span ends if it supports ForceFlush
+// (like the SDK's TracerProvider does).
+func Middleware(opts ...Option) cli.Middleware {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return func(ctx context.Context, cmd *cli.Command, next func(context.Context) error) error {
+		tp := o.tracerProvider
+		if tp == nil {
+			tp = otel.GetTracerProvider()
+		}
+
+		attrs := append([]attribute.KeyValue{
+			attribute.String("cli.command", cmd.FullName()),
+		}, o.attributes...)
+		attrs = append(attrs, flagAttributes(cmd)...)
+
+		ctx, span := tp.Tracer(tracerName).Start(
+			ctx, cmd.FullName(),
+			trace.WithAttributes(attrs...),
+		)
+		err := next(ctx)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+		if f, ok := tp.(flusher); ok {
+			// Use a context which isn't cancelled if the command's
+			// context was, so that the span is still exported.
+			if flushErr := f.ForceFlush(context.Background()); flushErr != nil {
+				otel.Handle(flushErr)
+			}
+		}
+		return err
+	}
+}
+
+// flagAttributes returns attributes for the non-secret flags of cmd which
+// were set, sorted by name.
+func flagAttributes(cmd *cli.Command) []attribute.KeyValue {
+	values := cmd.Values()
+	names := make([]string, 0, len(values))
+	for name := range values {
+		if cmd.IsSet(name) && !cmd.IsSecret(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	attrs := make([]attribute.KeyValue, 0, len(names))
+	for _, name := range names {
+		attrs = append(attrs, attribute.String("cli.flag."+name, fmt.Sprint(values[name])))
+	}
+	return attrs
+}
@@ -0,0 +1,85 @@
+package otelcli
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/isobit/cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type testRoot struct {
+	Verbose bool
+}
+
+type testSub struct {
+	Name     string
+	Password string `cli:"secret"`
+	Fail     bool
+	ran      bool
+}
+
+func (c *testSub) Run() error {
+	c.ran = true
+	if c.Fail {
+		return fmt.Errorf("failed")
+	}
+	return nil
+}
+
+func TestMiddleware(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter))
+
+	c := cli.NewCLI()
+	c.Use(Middleware(
+		WithTracerProvider(tp),
+		WithAttributes(attribute.String("service", "test")),
+	))
+
+	sub := &testSub{}
+	err := c.New("test", &testRoot{}, c.New("sub", sub)).
+		ParseArgs([]string{"--verbose", "sub", "--name", "foo", "--password", "hunter2"}).
+		RunWithContext(context.Background())
+	require.NoError(t, err)
+	assert.True(t, sub.ran)
+
+	// The batcher is flushed by the middleware, so the span should already
+	// have been exported.
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	span := spans[0]
+	assert.Equal(t, "test sub", span.Name)
+	assert.Equal(t, []attribute.KeyValue{
+		attribute.String("cli.command", "test sub"),
+		attribute.String("service", "test"),
+		attribute.String("cli.flag.name", "foo"),
+	}, span.Attributes)
+	assert.Equal(t, codes.Unset, span.Status.Code)
+}
+
+func TestMiddlewareError(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	c := cli.NewCLI()
+	c.ErrWriter = nil
+	c.HelpWriter = nil
+	c.Use(Middleware(WithTracerProvider(tp)))
+
+	err := c.New("test", &testSub{}).
+		ParseArgs([]string{"--fail"}).
+		Run()
+	require.Error(t, err)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, codes.Error, spans[0].Status.Code)
+	assert.Equal(t, "failed", spans[0].Status.Description)
+}
@@ -35,12 +35,25 @@ package cli
 
 import (
 	"fmt"
+	"strings"
 )
 
 type parser struct {
-	fields map[string]field
-	parsed bool
-	args   []string
+	fields     map[string]field
+	parsed     bool
+	args       []string
+	slashFlags bool
+
+	// setValue is called to set field values; if nil, the field's value is
+	// set directly.
+	setValue func(f field, s string) error
+}
+
+func (p *parser) set(f field, s string) error {
+	if p.setValue != nil {
+		return p.setValue(f, s)
+	}
+	return f.value.Set(s)
 }
 
 func (p *parser) parse(arguments []string) error {
@@ -63,6 +76,9 @@ func (p *parser) parseOne() (bool, error) {
 		return false, nil
 	}
 	s := p.args[0]
+	if p.slashFlags && len(s) >= 2 && s[0] == '/' {
+		return p.parseOneSlash(s)
+	}
 	if len(s) < 2 || s[0] != '-' {
 		return false, nil
 	}
@@ -81,16 +97,23 @@ func (p *parser) parseOne() (bool, error) {
 
 	// If single dash, handle each rune in the name as a separate flag, except
 	// for the last one which can be handled normally since it make have a
-	// following argument.
+	// following argument. Names which exactly match a multi-rune alias are
+	// handled normally instead of being split.
 	if numMinuses == 1 {
-		i := 0
-		for ; i < len(name)-1; i++ {
-			shortName := name[i]
-			if err := p.parseOneFlag(string(shortName), false, "", false); err != nil {
-				return false, err
+		end := len(name)
+		if eq := strings.IndexByte(name, '='); eq > 0 {
+			end = eq
+		}
+		if f, ok := p.fields[name[:end]]; !ok || !f.hasAlias(name[:end]) {
+			i := 0
+			for ; i < end-1; i++ {
+				shortName := name[i]
+				if err := p.parseOneFlag(string(shortName), false, "", false); err != nil {
+					return false, err
+				}
 			}
+			name = name[i:]
 		}
-		name = name[i:]
 	}
 
 	// it's a flag. does it have an argument?
@@ -113,21 +136,61 @@ func (p *parser) parseOne() (bool, error) {
 	return true, nil
 }
 
+// parseOneSlash parses a Windows-style "/flag", "/flag value", or
+// "/flag:value" argument. Arguments which do not match a known field name are
+// treated as non-flag arguments, so that absolute paths can still be passed.
+func (p *parser) parseOneSlash(s string) (bool, error) {
+	name := s[1:]
+	hasValue := false
+	value := ""
+	if i := strings.IndexByte(name, ':'); i >= 0 {
+		value = name[i+1:]
+		hasValue = true
+		name = name[:i]
+	}
+	if _, ok := p.fields[name]; !ok {
+		return false, nil
+	}
+
+	p.args = p.args[1:]
+	if err := p.parseOneFlag(name, hasValue, value, true); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
 func (p *parser) parseOneFlag(name string, hasValue bool, value string, canLookNext bool) error {
 	field, ok := p.fields[name]
 	if !ok {
 		return fmt.Errorf("flag provided but not defined: %s", name)
 	}
+	if err := p.parseOneFieldFlag(field, name, hasValue, value, canLookNext); err != nil {
+		return &FieldError{Name: field.Name, Err: err}
+	}
+	return nil
+}
 
+func (p *parser) parseOneFieldFlag(field field, name string, hasValue bool, value string, canLookNext bool) error {
 	fv := field.value
 
+	if field.Negatable && name == field.negatedName() {
+		if hasValue {
+			return fmt.Errorf("flag %s does not take a value", name)
+		}
+		if err := p.set(field, "false"); err != nil {
+			return fmt.Errorf("invalid boolean flag %s: %v", name, err)
+		}
+		return nil
+	}
+
 	if fv.isBoolFlag { // special case: doesn't need an arg
 		if hasValue {
-			if err := fv.Set(value); err != nil {
-				return fmt.Errorf("invalid boolean value %q for flag %s: %v", value, name, err)
+			if err := p.set(field, value); err != nil {
+				return fmt.Errorf("invalid boolean value%s for flag %s: %v", fv.quotedValue(value), name, err)
 			}
 		} else {
-			if err := fv.Set("true"); err != nil {
+			if err := p.set(field, "true"); err != nil {
 				return fmt.Errorf("invalid boolean flag %s: %v", name, err)
 			}
 		}
@@ -141,8 +204,8 @@ func (p *parser) parseOneFlag(name string, hasValue bool, value string, canLookN
 		if !hasValue {
 			return fmt.Errorf("flag needs an argument: %s", name)
 		}
-		if err := fv.Set(value); err != nil {
-			return fmt.Errorf("invalid value %q for flag %s: %v", value, name, err)
+		if err := p.set(field, value); err != nil {
+			return fmt.Errorf("invalid value%s for flag %s: %v", fv.quotedValue(value), name, err)
 		}
 	}
 	return nil
@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+)
+
+// profilingOptions holds the values of the hidden profiling flags which are
+// added to every command when CLI.ProfilingFlags is enabled.
+type profilingOptions struct {
+	cpuProfile string
+	memProfile string
+	trace      string
+}
+
+// addProfilingFields adds hidden --cpuprofile, --memprofile, and --trace
+// flags, unless fields with the same names already exist.
+func (cmd *Command) addProfilingFields() error {
+	if !cmd.cli.ProfilingFlags {
+		return nil
+	}
+	flags := []struct {
+		name string
+		help string
+		v    *string
+	}{
+		{"cpuprofile", "write a CPU profile to file", &cmd.profiling.cpuProfile},
+		{"memprofile", "write a memory profile to file", &cmd.profiling.memProfile},
+		{"trace", "write an execution trace to file", &cmd.profiling.trace},
+	}
+	for _, flag := range flags {
+		if _, ok := cmd.fieldMap[flag.name]; ok {
+			continue
+		}
+		f := field{
+			Name:        flag.name,
+			Help:        flag.help,
+			Placeholder: "FILE",
+			HasArg:      true,
+			Hidden:      true,
+			value: &fieldValue{
+				Setter:   stringSetter{flag.v},
+				stringer: staticStringer(""),
+				name:     flag.name,
+			},
+		}
+		if err := cmd.addField(f, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// profilingOptions returns the profiling options passed to the command or any
+// of its parents, preferring those passed to subcommands.
+func (cmd *Command) profilingOptions() profilingOptions {
+	opts := profilingOptions{}
+	for c := cmd; c != nil; c = c.parent {
+		if opts.cpuProfile == "" {
+			opts.cpuProfile = c.profiling.cpuProfile
+		}
+		if opts.memProfile == "" {
+			opts.memProfile = c.profiling.memProfile
+		}
+		if opts.trace == "" {
+			opts.trace = c.profiling.trace
+		}
+	}
+	return opts
+}
+
+// withProfiling wraps run so that the profiles requested by the profiling
+// flags are written around it.
+func (cmd *Command) withProfiling(run func(context.Context) error) func(context.Context) error {
+	if !cmd.cli.ProfilingFlags {
+		return run
+	}
+	return func(ctx context.Context) (err error) {
+		opts := cmd.profilingOptions()
+
+		if opts.cpuProfile != "" {
+			f, err := os.Create(opts.cpuProfile)
+			if err != nil {
+				return fmt.Errorf("failed to create CPU profile: %w", err)
+			}
+			defer f.Close()
+			if err := pprof.StartCPUProfile(f); err != nil {
+				return fmt.Errorf("failed to start CPU profile: %w", err)
+			}
+			defer pprof.StopCPUProfile()
+		}
+
+		if opts.trace != "" {
+			f, err := os.Create(opts.trace)
+			if err != nil {
+				return fmt.Errorf("failed to create trace: %w", err)
+			}
+			defer f.Close()
+			if err := trace.Start(f); err != nil {
+				return fmt.Errorf("failed to start trace: %w", err)
+			}
+			defer trace.Stop()
+		}
+
+		if opts.memProfile != "" {
+			defer func() {
+				if memErr := writeMemProfile(opts.memProfile); memErr != nil && err == nil {
+					err = memErr
+				}
+			}()
+		}
+
+		return run(ctx)
+	}
+}
+
+func writeMemProfile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create memory profile: %w", err)
+	}
+	defer f.Close()
+	// Get up-to-date statistics.
+	runtime.GC()
+	if err := pprof.WriteHeapProfile(f); err != nil {
+		return fmt.Errorf("failed to write memory profile: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type profilingTestCmd struct {
+	ran bool
+}
+
+func (cmd *profilingTestCmd) Run() error {
+	cmd.ran = true
+	return nil
+}
+
+func TestProfilingFlags(t *testing.T) {
+	dir := t.TempDir()
+	cli := NewCLI()
+	cli.ProfilingFlags = true
+
+	sub := &profilingTestCmd{}
+	c := cli.New("test", &struct{}{}, cli.New("sub", sub))
+	assert.NotContains(t, c.HelpString(), "cpuprofile")
+
+	cpu := filepath.Join(dir, "cpu.pprof")
+	mem := filepath.Join(dir, "mem.pprof")
+	tr := filepath.Join(dir, "trace.out")
+	err := c.ParseArgs([]string{"--cpuprofile", cpu, "sub", "--memprofile", mem, "--trace", tr}).Run()
+	require.NoError(t, err)
+	assert.True(t, sub.ran)
+
+	for _, path := range []string{cpu, mem, tr} {
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.NotZero(t, info.Size(), path)
+	}
+}
+
+func TestProfilingFlagsDisabled(t *testing.T) {
+	r := New("test", &profilingTestCmd{}).ParseArgs([]string{"--cpuprofile", "cpu.pprof"})
+	require.Error(t, r.Err)
+}
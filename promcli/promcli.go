@@ -0,0 +1,197 @@
+// Package promcli provides helpers for pushing metrics about command runs to a
+// Prometheus Pushgateway, which is useful for batch (e.g. cron) CLIs that
+// aren't running long enough to be scraped.
+//
+//	type App struct {
+//		promcli.Options
+//	}
+//
+//	app := &App{}
+//	cli.NewCLI().
+//		Use(promcli.Middleware(&app.Options)).
+//		New("mycli", app).
+//		Parse().
+//		RunFatal()
+package promcli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/isobit/cli"
+)
+
+// Options can be embedded in a command config to add flags for configuring
+// metrics pushing. If PushgatewayURL is empty, metrics are not pushed.
+type Options struct {
+	PushgatewayURL string `cli:"name=pushgateway-url,env=PUSHGATEWAY_URL,help=Prometheus Pushgateway URL to push run metrics to"`
+	PushgatewayJob string `cli:"name=pushgateway-job,env=PUSHGATEWAY_JOB,help=job name for pushed metrics (defaults to the CLI name)"`
+}
+
+// Metric is a single gauge value to push.
+type Metric struct {
+	Name   string
+	Help   string
+	Labels map[string]string
+	Value  float64
+}
+
+// PushTimeout is the timeout for pushing metrics in Middleware.
+var PushTimeout = 5 * time.Second
+
+// Middleware returns cli.Middleware which records the duration and exit
+// status of each command run, and pushes them to the Pushgateway configured
+// by opts (which will usually be embedded in the root command's config).
+// Metrics are grouped by job and command name, so each command's metrics
+// replace those from the last run of the same command.
+//
+// The pushed metrics are:
+//
+//	cli_run_duration_seconds        duration of the command's Run method
+//	cli_run_exit_code               exit code (0 if successful)
+//	cli_run_success                 1 if the command succeeded, otherwise 0
+//	cli_run_last_timestamp_seconds  Unix time at which the command finished
+//
+// Metrics are pushed using a new context with a timeout of PushTimeout, since
+// the command's context may already be cancelled (e.g. by SIGTERM) and the
+// metrics of interrupted runs should still be pushed. If the command succeeds
+// but pushing fails, the push error is returned.
+func Middleware(opts *Options) cli.Middleware {
+	return func(ctx context.Context, cmd *cli.Command, next func(context.Context) error) error {
+		start := time.Now()
+		err := next(ctx)
+		end := time.Now()
+
+		if opts.PushgatewayURL == "" {
+			return err
+		}
+
+		job := opts.PushgatewayJob
+		if job == "" {
+			job = cmd.Path()[0]
+		}
+
+		metrics := RunMetrics(end.Sub(start), err, end)
+		grouping := map[string]string{"command": cmd.FullName()}
+		pushCtx, cancel := context.WithTimeout(context.Background(), PushTimeout)
+		defer cancel()
+		pushErr := Push(pushCtx, opts.PushgatewayURL, job, grouping, metrics...)
+		if err == nil && pushErr != nil {
+			return fmt.Errorf("failed to push metrics: %w", pushErr)
+		}
+		return err
+	}
+}
+
+// RunMetrics returns the metrics pushed by Middleware for a command run
+// which took duration, returned err, and finished at end.
+func RunMetrics(duration time.Duration, err error, end time.Time) []Metric {
+	exitCode := 0
+	success := 1.0
+	if err != nil {
+		exitCode = 1
+		success = 0
+		var ec cli.ExitCoder
+		if errors.As(err, &ec) {
+			exitCode = ec.ExitCode()
+		}
+	}
+	return []Metric{
+		{
+			Name:  "cli_run_duration_seconds",
+			Help:  "Duration of the command run in seconds.",
+			Value: duration.Seconds(),
+		},
+		{
+			Name:  "cli_run_exit_code",
+			Help:  "Exit code of the command run.",
+			Value: float64(exitCode),
+		},
+		{
+			Name:  "cli_run_success",
+			Help:  "Whether the command run succeeded.",
+			Value: success,
+		},
+		{
+			Name:  "cli_run_last_timestamp_seconds",
+			Help:  "Unix time at which the command run finished.",
+			Value: float64(end.UnixNano()) / 1e9,
+		},
+	}
+}
+
+// Push replaces the metrics in the Pushgateway group identified by job and
+// the grouping labels with the given metrics.
+func Push(ctx context.Context, pushgatewayURL string, job string, grouping map[string]string, metrics ...Metric) error {
+	u := strings.TrimSuffix(pushgatewayURL, "/") + "/metrics/job/" + url.PathEscape(job)
+	for _, k := range sortedKeys(grouping) {
+		u += "/" + url.PathEscape(k) + "/" + url.PathEscape(grouping[k])
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, u, bytes.NewReader(formatMetrics(metrics)))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected response status from %s: %s", u, resp.Status)
+	}
+	return nil
+}
+
+// formatMetrics formats metrics as gauges using the Prometheus text
+// exposition format.
+func formatMetrics(metrics []Metric) []byte {
+	b := bytes.Buffer{}
+	for _, m := range metrics {
+		if m.Help != "" {
+			fmt.Fprintf(&b, "# HELP %s %s\n", m.Name, escapeHelp(m.Help))
+		}
+		fmt.Fprintf(&b, "# TYPE %s gauge\n", m.Name)
+		b.WriteString(m.Name)
+		if len(m.Labels) > 0 {
+			pairs := []string{}
+			for _, k := range sortedKeys(m.Labels) {
+				pairs = append(pairs, fmt.Sprintf("%s=\"%s\"", k, escapeLabelValue(m.Labels[k])))
+			}
+			b.WriteString("{" + strings.Join(pairs, ",") + "}")
+		}
+		b.WriteString(" " + strconv.FormatFloat(m.Value, 'g', -1, 64) + "\n")
+	}
+	return b.Bytes()
+}
+
+var helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+func escapeHelp(s string) string {
+	return helpEscaper.Replace(s)
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+func escapeLabelValue(s string) string {
+	return labelValueEscaper.Replace(s)
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
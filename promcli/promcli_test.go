@@ -0,0 +1,128 @@
+package promcli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/isobit/cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testRoot struct {
+	Options
+}
+
+type testSub struct {
+	Fail bool
+}
+
+func (c *testSub) Run() error {
+	if c.Fail {
+		return fmt.Errorf("failed")
+	}
+	return nil
+}
+
+func TestMiddleware(t *testing.T) {
+	var method, path, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method
+		path = r.URL.EscapedPath()
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+	}))
+	defer server.Close()
+
+	root := &testRoot{}
+	c := cli.NewCLI()
+	c.HelpWriter = nil
+	c.Use(Middleware(&root.Options))
+	err := c.New("test", root, c.New("sub", &testSub{})).
+		ParseArgs([]string{"--pushgateway-url", server.URL, "sub", "--fail"}).
+		Run()
+	require.EqualError(t, err, "failed")
+
+	assert.Equal(t, http.MethodPut, method)
+	assert.Equal(t, "/metrics/job/test/command/test%20sub", path)
+	assert.Contains(t, body, "# TYPE cli_run_duration_seconds gauge\n")
+	assert.Contains(t, body, "cli_run_exit_code 1\n")
+	assert.Contains(t, body, "cli_run_success 0\n")
+}
+
+func TestMiddlewareCancelled(t *testing.T) {
+	pushed := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pushed = true
+	}))
+	defer server.Close()
+
+	root := &testRoot{Options{PushgatewayURL: server.URL}}
+	c := cli.NewCLI()
+	c.Use(Middleware(&root.Options))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := c.New("test", root, c.New("sub", &testSub{})).
+		ParseArgs([]string{"sub"}).
+		RunWithContext(ctx)
+	require.NoError(t, err)
+	assert.True(t, pushed)
+}
+
+func TestMiddlewareDisabled(t *testing.T) {
+	root := &testRoot{}
+	c := cli.NewCLI()
+	c.Use(Middleware(&root.Options))
+	err := c.New("test", root, c.New("sub", &testSub{})).
+		ParseArgs([]string{"sub"}).
+		Run()
+	require.NoError(t, err)
+}
+
+func TestMiddlewarePushError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	root := &testRoot{Options{PushgatewayURL: server.URL, PushgatewayJob: "batch"}}
+	c := cli.NewCLI()
+	c.Use(Middleware(&root.Options))
+	err := c.New("test", root, c.New("sub", &testSub{})).
+		ParseArgs([]string{"sub"}).
+		Run()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to push metrics")
+}
+
+func TestFormatMetrics(t *testing.T) {
+	b := formatMetrics([]Metric{
+		{
+			Name:   "foo",
+			Help:   "Foo\nbar.",
+			Labels: map[string]string{"b": `x"y`, "a": "z"},
+			Value:  1.5,
+		},
+	})
+	assert.Equal(t, "# HELP foo Foo\\nbar.\n# TYPE foo gauge\nfoo{a=\"z\",b=\"x\\\"y\"} 1.5\n", string(b))
+}
+
+func TestRunMetrics(t *testing.T) {
+	end := time.Unix(100, 0)
+	metrics := RunMetrics(2*time.Second, nil, end)
+	values := map[string]float64{}
+	for _, m := range metrics {
+		values[m.Name] = m.Value
+	}
+	assert.Equal(t, map[string]float64{
+		"cli_run_duration_seconds":       2,
+		"cli_run_exit_code":              0,
+		"cli_run_success":                1,
+		"cli_run_last_timestamp_seconds": 100,
+	}, values)
+}
@@ -0,0 +1,196 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// IntRange is a field type for inclusive integer ranges, written as "MIN-MAX"
+// (or "MIN..MAX", which is clearer when negative numbers are involved). A
+// single number is a range containing only that number.
+type IntRange struct {
+	Min int
+	Max int
+}
+
+func (r *IntRange) Set(s string) error {
+	minStr, maxStr, ok := strings.Cut(s, "..")
+	if !ok {
+		// Skip the first character so that a negative MIN isn't mistaken
+		// for the separator.
+		offset := 0
+		if strings.HasPrefix(s, "-") {
+			offset = 1
+		}
+		if i := strings.Index(s[offset:], "-"); i >= 0 {
+			i += offset
+			minStr, maxStr, ok = s[:i], s[i+1:], true
+		}
+	}
+	if !ok {
+		maxStr = minStr
+	}
+	lo, err := strconv.Atoi(strings.TrimSpace(minStr))
+	if err != nil {
+		return fmt.Errorf("invalid range %q: expected MIN-MAX", s)
+	}
+	hi, err := strconv.Atoi(strings.TrimSpace(maxStr))
+	if err != nil {
+		return fmt.Errorf("invalid range %q: expected MIN-MAX", s)
+	}
+	if hi < lo {
+		return fmt.Errorf("invalid range %q: max %d is less than min %d", s, hi, lo)
+	}
+	r.Min = lo
+	r.Max = hi
+	return nil
+}
+
+// String returns the range as "MIN-MAX", or an empty string for the zero
+// range so that it isn't shown as a default in help text.
+func (r IntRange) String() string {
+	if r == (IntRange{}) {
+		return ""
+	}
+	if r.Min < 0 || r.Max < 0 {
+		return fmt.Sprintf("%d..%d", r.Min, r.Max)
+	}
+	return fmt.Sprintf("%d-%d", r.Min, r.Max)
+}
+
+func (IntRange) Placeholder() string {
+	return "MIN-MAX"
+}
+
+// Contains reports whether n is within the range.
+func (r IntRange) Contains(n int) bool {
+	return n >= r.Min && n <= r.Max
+}
+
+// TimeRange is a field type for time ranges, written as "START..END", where
+// START and END are either dates ("2006-01-02"), times without an offset
+// ("2006-01-02T15:04:05"), or RFC 3339 timestamps. Either end may be omitted
+// (e.g. "2024-01-01..") to leave the range unbounded on that side, in which
+// case it is the zero time.
+//
+// Dates and times without an offset are parsed as UTC; use In to interpret
+// them in another location (e.g. one chosen by a --timezone flag).
+type TimeRange struct {
+	Start time.Time
+	End   time.Time
+
+	// startFloating and endFloating are true if the start and end were
+	// written without an offset, so their wall clock times can be
+	// reinterpreted by In.
+	startFloating bool
+	endFloating   bool
+}
+
+var (
+	timeRangeFloatingLayouts = []string{"2006-01-02T15:04:05", "2006-01-02"}
+	timeRangeLayouts         = append([]string{time.RFC3339Nano}, timeRangeFloatingLayouts...)
+)
+
+func (r *TimeRange) Set(s string) error {
+	startStr, endStr, ok := strings.Cut(s, "..")
+	if !ok {
+		return fmt.Errorf("invalid time range %q: expected START..END", s)
+	}
+	start, startFloating, err := parseRangeTime(startStr)
+	if err != nil {
+		return fmt.Errorf("invalid time range %q: %w", s, err)
+	}
+	end, endFloating, err := parseRangeTime(endStr)
+	if err != nil {
+		return fmt.Errorf("invalid time range %q: %w", s, err)
+	}
+	if !start.IsZero() && !end.IsZero() && end.Before(start) {
+		return fmt.Errorf("invalid time range %q: end is before start", s)
+	}
+	*r = TimeRange{
+		Start:         start,
+		End:           end,
+		startFloating: startFloating,
+		endFloating:   endFloating,
+	}
+	return nil
+}
+
+// parseRangeTime parses s using timeRangeLayouts, also returning whether s
+// had no offset.
+func parseRangeTime(s string) (time.Time, bool, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, false, nil
+	}
+	for i, layout := range timeRangeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, i > 0, nil
+		}
+	}
+	return time.Time{}, false, fmt.Errorf("cannot parse %q as a date or RFC 3339 time", s)
+}
+
+// In returns a copy of the range in which any dates and times which were
+// written without an offset are interpreted in loc instead of UTC. Times with
+// an explicit offset are unchanged.
+func (r TimeRange) In(loc *time.Location) TimeRange {
+	if r.startFloating {
+		r.Start = inLocation(r.Start, loc)
+	}
+	if r.endFloating {
+		r.End = inLocation(r.End, loc)
+	}
+	return r
+}
+
+// inLocation returns the time with the same wall clock as t in loc.
+func inLocation(t time.Time, loc *time.Location) time.Time {
+	year, month, day := t.Date()
+	hour, min, sec := t.Clock()
+	return time.Date(year, month, day, hour, min, sec, t.Nanosecond(), loc)
+}
+
+// String returns the range as "START..END", or an empty string for the zero
+// range. Times at midnight UTC, or at midnight in the location given to In,
+// are formatted as dates.
+func (r TimeRange) String() string {
+	if r.Start.IsZero() && r.End.IsZero() {
+		return ""
+	}
+	return formatRangeTime(r.Start, r.startFloating) + ".." + formatRangeTime(r.End, r.endFloating)
+}
+
+func formatRangeTime(t time.Time, floating bool) string {
+	if t.IsZero() {
+		return ""
+	}
+	hour, min, sec := t.Clock()
+	midnight := hour == 0 && min == 0 && sec == 0 && t.Nanosecond() == 0
+	switch {
+	case midnight && (floating || t.Location() == time.UTC):
+		return t.Format("2006-01-02")
+	case floating:
+		return t.Format("2006-01-02T15:04:05.999999999")
+	default:
+		return t.Format(time.RFC3339Nano)
+	}
+}
+
+func (TimeRange) Placeholder() string {
+	return "START..END"
+}
+
+// Contains reports whether t is within the range, which includes its start
+// but not its end.
+func (r TimeRange) Contains(t time.Time) bool {
+	if !r.Start.IsZero() && t.Before(r.Start) {
+		return false
+	}
+	if !r.End.IsZero() && !t.Before(r.End) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntRange(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want IntRange
+		err  string
+	}{
+		{in: "10-20", want: IntRange{10, 20}},
+		{in: "5", want: IntRange{5, 5}},
+		{in: "-5-10", want: IntRange{-5, 10}},
+		{in: "-10..-5", want: IntRange{-10, -5}},
+		{in: "20-10", err: "max 10 is less than min 20"},
+		{in: "a-b", err: "expected MIN-MAX"},
+		{in: "", err: "expected MIN-MAX"},
+	} {
+		var r IntRange
+		err := r.Set(tc.in)
+		if tc.err != "" {
+			require.Error(t, err, tc.in)
+			assert.Contains(t, err.Error(), tc.err, tc.in)
+			continue
+		}
+		require.NoError(t, err, tc.in)
+		assert.Equal(t, tc.want, r, tc.in)
+
+		var roundTrip IntRange
+		require.NoError(t, roundTrip.Set(r.String()), tc.in)
+		assert.Equal(t, r, roundTrip, tc.in)
+	}
+
+	r := IntRange{1, 3}
+	assert.True(t, r.Contains(1))
+	assert.True(t, r.Contains(3))
+	assert.False(t, r.Contains(4))
+}
+
+func TestTimeRange(t *testing.T) {
+	day := func(s string) time.Time {
+		tt, err := time.Parse("2006-01-02", s)
+		require.NoError(t, err)
+		return tt
+	}
+
+	var r TimeRange
+	require.NoError(t, r.Set("2024-01-01..2024-02-01"))
+	assert.Equal(t, day("2024-01-01"), r.Start)
+	assert.Equal(t, day("2024-02-01"), r.End)
+	assert.Equal(t, "2024-01-01..2024-02-01", r.String())
+	assert.True(t, r.Contains(day("2024-01-01")))
+	assert.False(t, r.Contains(day("2024-02-01")))
+
+	require.NoError(t, r.Set("2024-01-01T12:00:00Z.."))
+	assert.Equal(t, "2024-01-01T12:00:00Z..", r.String())
+	assert.True(t, r.End.IsZero())
+	assert.True(t, r.Contains(day("2030-01-01")))
+
+	require.NoError(t, r.Set("2024-01-01T09:30:00..2024-01-01T17:00:00Z"))
+	assert.Equal(t, "2024-01-01T09:30:00..2024-01-01T17:00:00Z", r.String())
+
+	loc := time.FixedZone("UTC-5", -5*60*60)
+	require.NoError(t, r.Set("2024-01-01..2024-01-02T12:00:00Z"))
+	inLoc := r.In(loc)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, loc), inLoc.Start)
+	assert.Equal(t, r.End, inLoc.End)
+	assert.Equal(t, "2024-01-01..2024-01-02T12:00:00Z", inLoc.String())
+	assert.False(t, inLoc.Contains(time.Date(2024, 1, 1, 2, 0, 0, 0, time.UTC)))
+	assert.True(t, inLoc.Contains(time.Date(2024, 1, 1, 6, 0, 0, 0, time.UTC)))
+	assert.Equal(t, inLoc.Start, inLoc.In(loc).Start)
+
+	err := r.Set("2024-02-01..2024-01-01")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "end is before start")
+
+	err = r.Set("2024-01-01")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "expected START..END")
+
+	err = r.Set("yesterday..today")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `cannot parse "yesterday"`)
+}
+
+func TestRangeFields(t *testing.T) {
+	config := &struct {
+		Lines  IntRange
+		Period TimeRange
+		Pages  []IntRange `cli:"append"`
+		Other  IntRange   `cli:"placeholder=N-M"`
+	}{
+		Lines: IntRange{1, 10},
+	}
+	cmd := New("test", config)
+	help := cmd.HelpString()
+	assert.Regexp(t, `--lines <MIN-MAX> +\(default: 1-10\)`, help)
+	assert.Contains(t, help, "--period <START..END>")
+	assert.Contains(t, help, "--pages <MIN-MAX>")
+	assert.Contains(t, help, "--other <N-M>")
+
+	require.NoError(t, cmd.ParseArgs([]string{
+		"--lines", "5-7",
+		"--period", "2024-01-01..2024-02-01",
+		"--pages", "1-2", "--pages", "4",
+	}).Err)
+	assert.Equal(t, IntRange{5, 7}, config.Lines)
+	assert.Equal(t, []IntRange{{1, 2}, {4, 4}}, config.Pages)
+	assert.False(t, config.Period.Start.IsZero())
+
+	r := New("test", config).ParseArgs([]string{"--lines", "7-5"})
+	require.Error(t, r.Err)
+}
@@ -0,0 +1,161 @@
+// Package redisopts provides standard Redis connection flags supporting
+// standalone, Sentinel, and Cluster deployments, which build a
+// redis.UniversalOptions for github.com/redis/go-redis/v9:
+//
+//	type Worker struct {
+//		redisopts.Options
+//	}
+//
+//	func (w *Worker) Run(ctx context.Context) error {
+//		rdb, err := w.NewClient()
+//		if err != nil {
+//			return err
+//		}
+//		defer rdb.Close()
+//		...
+//	}
+//
+// This package is a separate module so that the cli module does not depend on
+// go-redis.
+package redisopts
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/isobit/cli/tlsopts"
+	"github.com/redis/go-redis/v9"
+)
+
+// Modes are the supported values of Options.Mode.
+const (
+	ModeStandalone = "standalone"
+	ModeSentinel   = "sentinel"
+	ModeCluster    = "cluster"
+)
+
+// Options can be embedded in a command config to add Redis connection flags.
+type Options struct {
+	URL                   string `cli:"name=redis-url,env=REDIS_URL,secret,placeholder=URL,help=redis:// or rediss:// URL (standalone mode only; instead of --redis-addr)"`
+	Addr                  string `cli:"name=redis-addr,env=REDIS_ADDR,placeholder=HOST:PORT[...],help=comma-separated Redis addresses (Sentinel or Cluster seed nodes in those modes) (localhost:6379 if not set)"`
+	Mode                  string `cli:"name=redis-mode,env=REDIS_MODE,placeholder=MODE,help=deployment mode (standalone/sentinel/cluster) (standalone if not set)"`
+	MasterName            string `cli:"name=redis-master-name,env=REDIS_MASTER_NAME,placeholder=NAME,help=Sentinel master name (required in sentinel mode)"`
+	Username              string `cli:"name=redis-username,env=REDIS_USERNAME,placeholder=USER,help=ACL username"`
+	Password              string `cli:"name=redis-password,env=REDIS_PASSWORD,secret,placeholder=PASSWORD,help=password"`
+	DB                    int    `cli:"name=redis-db,env=REDIS_DB,nodefault,placeholder=N,help=database index (not supported in cluster mode)"`
+	TLS                   bool   `cli:"name=redis-tls,env=REDIS_TLS,help=connect using TLS"`
+	TLSCAFile             string `cli:"name=redis-tls-ca,env=REDIS_TLS_CA,placeholder=PATH,help=PEM file of CA certificates to trust"`
+	TLSCertFile           string `cli:"name=redis-tls-cert,env=REDIS_TLS_CERT,placeholder=PATH,help=PEM client certificate file"`
+	TLSKeyFile            string `cli:"name=redis-tls-key,env=REDIS_TLS_KEY,placeholder=PATH,help=PEM client key file"`
+	TLSInsecureSkipVerify bool   `cli:"name=redis-tls-insecure-skip-verify,help=skip server certificate verification (insecure)"`
+}
+
+// mode returns the normalized mode, defaulting to standalone.
+func (opts *Options) mode() (string, error) {
+	switch mode := strings.ToLower(opts.Mode); mode {
+	case "", ModeStandalone:
+		return ModeStandalone, nil
+	case ModeSentinel, ModeCluster:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid --redis-mode %q: must be one of: standalone, sentinel, cluster", opts.Mode)
+	}
+}
+
+// Build validates the options and returns the corresponding client options.
+func (opts *Options) Build() (*redis.UniversalOptions, error) {
+	mode, err := opts.mode()
+	if err != nil {
+		return nil, err
+	}
+	uopts := &redis.UniversalOptions{
+		Username: opts.Username,
+		Password: opts.Password,
+		DB:       opts.DB,
+	}
+
+	if opts.URL != "" {
+		if mode != ModeStandalone {
+			return nil, fmt.Errorf("--redis-url is only supported in standalone mode")
+		}
+		if opts.Addr != "" {
+			return nil, fmt.Errorf("--redis-url and --redis-addr can't be used together")
+		}
+		parsed, err := redis.ParseURL(opts.URL)
+		if err != nil {
+			// Errors from ParseURL don't include the URL, so there's
+			// no password to leak.
+			return nil, fmt.Errorf("invalid --redis-url: %w", err)
+		}
+		uopts.Addrs = []string{parsed.Addr}
+		if uopts.Username == "" {
+			uopts.Username = parsed.Username
+		}
+		if uopts.Password == "" {
+			uopts.Password = parsed.Password
+		}
+		if uopts.DB == 0 {
+			uopts.DB = parsed.DB
+		}
+		uopts.TLSConfig = parsed.TLSConfig
+	} else {
+		for _, addr := range strings.Split(opts.Addr, ",") {
+			if addr = strings.TrimSpace(addr); addr != "" {
+				uopts.Addrs = append(uopts.Addrs, addr)
+			}
+		}
+		if len(uopts.Addrs) == 0 {
+			if mode != ModeStandalone {
+				return nil, fmt.Errorf("--redis-addr is required in %s mode", mode)
+			}
+			uopts.Addrs = []string{"localhost:6379"}
+		}
+	}
+
+	switch mode {
+	case ModeStandalone:
+		if len(uopts.Addrs) > 1 {
+			return nil, fmt.Errorf("only one --redis-addr can be given in standalone mode")
+		}
+	case ModeSentinel:
+		if opts.MasterName == "" {
+			return nil, fmt.Errorf("--redis-master-name is required in sentinel mode")
+		}
+		uopts.MasterName = opts.MasterName
+	case ModeCluster:
+		if opts.DB != 0 {
+			return nil, fmt.Errorf("--redis-db is not supported in cluster mode")
+		}
+	}
+
+	usesTLS := opts.TLSCAFile != "" || opts.TLSCertFile != "" || opts.TLSInsecureSkipVerify
+	if opts.TLS || usesTLS {
+		tlsConfig, err := tlsopts.Load(opts.TLSCAFile, opts.TLSCertFile, opts.TLSKeyFile, opts.TLSInsecureSkipVerify)
+		if err != nil {
+			return nil, err
+		}
+		if uopts.TLSConfig != nil {
+			tlsConfig.ServerName = uopts.TLSConfig.ServerName
+		}
+		uopts.TLSConfig = tlsConfig
+	}
+	return uopts, nil
+}
+
+// NewClient returns a client for the configured mode: a *redis.Client,
+// *redis.Client using Sentinel failover, or *redis.ClusterClient.
+func (opts *Options) NewClient() (redis.UniversalClient, error) {
+	uopts, err := opts.Build()
+	if err != nil {
+		return nil, err
+	}
+	mode, _ := opts.mode()
+	switch mode {
+	case ModeSentinel:
+		return redis.NewFailoverClient(uopts.Failover()), nil
+	case ModeCluster:
+		return redis.NewClusterClient(uopts.Cluster()), nil
+	default:
+		return redis.NewClient(uopts.Simple()), nil
+	}
+}
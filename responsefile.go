@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// expandResponseFiles returns a copy of args where each "@file" argument has
+// been replaced by the arguments read from that file. Arguments after a "--"
+// terminator are not expanded.
+func expandResponseFiles(args []string) ([]string, error) {
+	expanded := make([]string, 0, len(args))
+	for i, arg := range args {
+		if arg == "--" {
+			expanded = append(expanded, args[i:]...)
+			break
+		}
+		if len(arg) < 2 || arg[0] != '@' {
+			expanded = append(expanded, arg)
+			continue
+		}
+		fileArgs, err := readResponseFile(arg[1:])
+		if err != nil {
+			return nil, err
+		}
+		expanded = append(expanded, fileArgs...)
+	}
+	return expanded, nil
+}
+
+// readResponseFile reads arguments from the file at path, one per line.
+// Leading and trailing whitespace is trimmed, and blank lines and lines
+// starting with "#" are ignored. A line may be enclosed in double quotes, in
+// which case it is unquoted using Go string literal syntax, or in single
+// quotes, in which case its contents are used verbatim.
+func readResponseFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response file: %w", err)
+	}
+	defer f.Close()
+
+	args := []string{}
+	scanner := bufio.NewScanner(f)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum += 1
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		arg, err := unquoteResponseFileLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse response file %s line %d: %w", path, lineNum, err)
+		}
+		args = append(args, arg)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read response file: %w", err)
+	}
+	return args, nil
+}
+
+func unquoteResponseFileLine(line string) (string, error) {
+	switch line[0] {
+	case '"':
+		return strconv.Unquote(line)
+	case '\'':
+		if len(line) < 2 || line[len(line)-1] != '\'' {
+			return "", fmt.Errorf("unterminated single quote")
+		}
+		return line[1 : len(line)-1], nil
+	default:
+		return line, nil
+	}
+}
@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "args.txt")
+	content := `
+# comment
+--string
+  "  hello\tworld  "
+--other
+'it''s'
+`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+	type Cmd struct {
+		Bool   bool
+		String string
+		Other  string
+		Args   []string `cli:"args"`
+	}
+	cli := NewCLI()
+	cli.ResponseFiles = true
+	cmd := &Cmd{}
+	r := cli.New("test", cmd).
+		ParseArgs([]string{"--bool", "@" + path, "--", "@" + path})
+	require.NoError(t, r.Err)
+
+	expected := &Cmd{
+		Bool:   true,
+		String: "  hello\tworld  ",
+		Other:  "it''s",
+		Args:   []string{"@" + path},
+	}
+	assert.Equal(t, expected, cmd)
+}
+
+func TestResponseFileDecodedFlag(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.json")
+	require.NoError(t, os.WriteFile(path, []byte("{\"a\":\n\"b\"}\n"), 0644))
+
+	type Cmd struct {
+		Data map[string]string `cli:"json"`
+	}
+	cli := NewCLI()
+	cli.ResponseFiles = true
+	cmd := &Cmd{}
+	r := cli.New("test", cmd).
+		ParseArgs([]string{"--data=@" + path})
+	require.NoError(t, r.Err)
+	assert.Equal(t, map[string]string{"a": "b"}, cmd.Data)
+
+	// A separate "@file" argument is expanded as a response file instead.
+	r = cli.New("test", &Cmd{}).
+		ParseArgs([]string{"--data", "@" + path})
+	assert.Error(t, r.Err)
+}
+
+func TestResponseFileErrors(t *testing.T) {
+	cli := NewCLI()
+	cli.ResponseFiles = true
+
+	r := cli.New("test", nil).
+		ParseArgs([]string{"@" + filepath.Join(t.TempDir(), "missing.txt")})
+	assert.Error(t, r.Err)
+
+	path := filepath.Join(t.TempDir(), "args.txt")
+	require.NoError(t, os.WriteFile(path, []byte("'unterminated\n"), 0644))
+	r = cli.New("test", nil).
+		ParseArgs([]string{"@" + path})
+	assert.Error(t, r.Err)
+}
@@ -0,0 +1,212 @@
+// Package scaffold generates the skeleton of a new CLI project which uses
+// this library: a go.mod, a main.go defining the root command, and a package
+// for each subcommand under cmd/.
+//
+//	err := scaffold.Generate("mytool", scaffold.Options{
+//		Module:   "example.com/mytool",
+//		Commands: []string{"serve", "migrate"},
+//	})
+//
+// The cli-scaffold command (in cmd/cli-scaffold) exposes this on the command
+// line.
+package scaffold
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// Options configure the generated project.
+type Options struct {
+	// Module is the Go module path of the project, e.g.
+	// "example.com/mytool". It is required.
+	Module string
+
+	// Name is the name of the CLI. If empty, the last element of Module is
+	// used.
+	Name string
+
+	// Commands are the names of subcommands to generate packages for.
+	Commands []string
+}
+
+var commandNameRegexp = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+
+type command struct {
+	Name    string
+	Package string
+}
+
+type templateData struct {
+	Module   string
+	Name     string
+	Commands []command
+}
+
+func (opts Options) data() (templateData, error) {
+	if opts.Module == "" {
+		return templateData{}, fmt.Errorf("module path is required")
+	}
+	name := opts.Name
+	if name == "" {
+		name = path.Base(opts.Module)
+	}
+	d := templateData{Module: opts.Module, Name: name}
+	seen := map[string]bool{}
+	for _, c := range opts.Commands {
+		if !commandNameRegexp.MatchString(c) {
+			return templateData{}, fmt.Errorf("invalid command name %q: must be lowercase letters, digits, and dashes", c)
+		}
+		pkg := strings.ReplaceAll(c, "-", "")
+		if seen[pkg] {
+			return templateData{}, fmt.Errorf("duplicate command name %q", c)
+		}
+		seen[pkg] = true
+		d.Commands = append(d.Commands, command{Name: c, Package: pkg})
+	}
+	return d, nil
+}
+
+// Files returns the contents of the generated files, keyed by their
+// slash-separated paths relative to the project directory.
+func Files(opts Options) (map[string][]byte, error) {
+	d, err := opts.data()
+	if err != nil {
+		return nil, err
+	}
+	files := map[string][]byte{}
+	render := func(name string, tmpl *template.Template, data interface{}) error {
+		b := &bytes.Buffer{}
+		if err := tmpl.Execute(b, data); err != nil {
+			return err
+		}
+		content := b.Bytes()
+		if strings.HasSuffix(name, ".go") {
+			formatted, err := format.Source(content)
+			if err != nil {
+				return fmt.Errorf("generated invalid Go source for %s: %w", name, err)
+			}
+			content = formatted
+		}
+		files[name] = content
+		return nil
+	}
+	if err := render("go.mod", goModTemplate, d); err != nil {
+		return nil, err
+	}
+	if err := render("main.go", mainTemplate, d); err != nil {
+		return nil, err
+	}
+	for _, c := range d.Commands {
+		data := struct {
+			templateData
+			Command command
+		}{d, c}
+		if err := render("cmd/"+c.Package+"/"+c.Package+".go", commandTemplate, data); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// Generate writes the files returned by Files to dir, creating it if
+// necessary. It returns an error without writing anything if any of the
+// files already exist.
+func Generate(dir string, opts Options) error {
+	files, err := Files(opts)
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if _, err := os.Stat(p); err == nil {
+			return fmt.Errorf("%s already exists", p)
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	for _, name := range names {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(p, files[name], 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var goModTemplate = template.Must(template.New("go.mod").Parse(`module {{.Module}}
+
+go 1.18
+`))
+
+var mainTemplate = template.Must(template.New("main.go").Parse(`package main
+
+import (
+	"github.com/isobit/cli"
+{{- if .Commands}}
+{{range .Commands}}
+	"{{$.Module}}/cmd/{{.Package}}"
+{{- end}}
+{{- end}}
+)
+
+// App is the config for the root {{.Name}} command. Its fields are flags
+// which are given before any subcommand name (e.g. "{{.Name}} -v ...").
+type App struct {
+	Verbose bool ` + "`" + `cli:"short=v,help=enable verbose output"` + "`" + `
+}
+{{if not .Commands}}
+func (app *App) Run() error {
+	return nil
+}
+{{end}}
+func main() {
+	cli.New("{{.Name}}", &App{}).
+{{- range .Commands}}
+		AddCommand(cli.New("{{.Name}}", &{{.Package}}.Command{}, cli.WithHelp("TODO: describe {{.Name}}"))).
+{{- end}}
+		Parse().
+		RunFatalWithSigCancel()
+}
+`))
+
+var commandTemplate = template.Must(template.New("command.go").Parse(`// Package {{.Command.Package}} implements the "{{.Name}} {{.Command.Name}}" command.
+package {{.Command.Package}}
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/isobit/cli"
+)
+
+// Command is the config for the {{.Command.Name}} command. Add fields with cli
+// struct tags to define its flags.
+type Command struct {
+	DryRun bool ` + "`" + `cli:"help=print what would be done without doing it"` + "`" + `
+}
+
+func (cmd *Command) Run(ctx context.Context) error {
+	fmt.Fprintln(cli.Stdout(ctx), "TODO: implement {{.Name}} {{.Command.Name}}")
+	return nil
+}
+`))
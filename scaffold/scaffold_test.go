@@ -0,0 +1,79 @@
+package scaffold
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFiles(t *testing.T) {
+	files, err := Files(Options{
+		Module:   "example.com/tools/mytool",
+		Commands: []string{"serve", "new-project"},
+	})
+	require.NoError(t, err)
+
+	names := []string{}
+	for name := range files {
+		names = append(names, name)
+	}
+	assert.ElementsMatch(t, []string{
+		"go.mod",
+		"main.go",
+		"cmd/serve/serve.go",
+		"cmd/newproject/newproject.go",
+	}, names)
+
+	assert.Equal(t, "module example.com/tools/mytool\n\ngo 1.18\n", string(files["go.mod"]))
+	main := string(files["main.go"])
+	assert.Contains(t, main, `"example.com/tools/mytool/cmd/newproject"`)
+	assert.Contains(t, main, `cli.New("mytool", &App{}).`)
+	assert.Contains(t, main, `AddCommand(cli.New("new-project", &newproject.Command{}`)
+	assert.Contains(t, string(files["cmd/newproject/newproject.go"]), "package newproject\n")
+
+	for name, content := range files {
+		if filepath.Ext(name) != ".go" {
+			continue
+		}
+		_, err := parser.ParseFile(token.NewFileSet(), name, content, 0)
+		assert.NoError(t, err, name)
+	}
+}
+
+func TestFilesNoCommands(t *testing.T) {
+	files, err := Files(Options{Module: "example.com/mytool", Name: "tool"})
+	require.NoError(t, err)
+	assert.Len(t, files, 2)
+	assert.Contains(t, string(files["main.go"]), "func (app *App) Run() error {")
+	assert.Contains(t, string(files["main.go"]), `cli.New("tool", &App{}).`)
+}
+
+func TestFilesErrors(t *testing.T) {
+	_, err := Files(Options{})
+	assert.EqualError(t, err, "module path is required")
+
+	_, err = Files(Options{Module: "example.com/mytool", Commands: []string{"Serve"}})
+	assert.EqualError(t, err, `invalid command name "Serve": must be lowercase letters, digits, and dashes`)
+
+	_, err = Files(Options{Module: "example.com/mytool", Commands: []string{"new-project", "newproject"}})
+	assert.EqualError(t, err, `duplicate command name "newproject"`)
+}
+
+func TestGenerate(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mytool")
+	opts := Options{Module: "example.com/mytool", Commands: []string{"serve"}}
+	require.NoError(t, Generate(dir, opts))
+
+	b, err := os.ReadFile(filepath.Join(dir, "cmd", "serve", "serve.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(b), "package serve")
+
+	err = Generate(dir, opts)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "already exists")
+}
@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+)
+
+// WithSearch returns a CommandOption which adds a "search" subcommand that
+// lists every command in the tree whose name, help, or description contains
+// the given text (ignoring case), which helps users discover functionality in
+// CLIs with many commands:
+//
+//	$ mycli search network
+//	mycli net list    list networks
+//	mycli vm attach   attach a network to a virtual machine
+//
+// Hidden commands and unavailable experimental commands are not searched.
+func WithSearch() CommandOption {
+	return commandOptionFunc(func(cmd *Command) {
+		searchCmd := cmd.cli.New(
+			"search",
+			&searchCommand{root: cmd},
+			WithHelp("search for commands"),
+		)
+		cmd.AddCommand(searchCmd)
+	})
+}
+
+type searchCommand struct {
+	Query []string `cli:"args"`
+
+	root *Command
+}
+
+func (cmd *searchCommand) Run(ctx context.Context) error {
+	query := strings.ToLower(strings.Join(cmd.Query, " "))
+	if query == "" {
+		return UsageErrorf("search text is required")
+	}
+
+	matches := []*Command{}
+	var walk func(c *Command)
+	walk = func(c *Command) {
+		for _, subCmd := range c.commands {
+			if subCmd.hidden || !c.commandAvailable(subCmd) {
+				continue
+			}
+			if _, ok := subCmd.config.(*searchCommand); !ok && subCmd.matchesSearch(query) {
+				matches = append(matches, subCmd)
+			}
+			walk(subCmd)
+		}
+	}
+	walk(cmd.root)
+	if len(matches) == 0 {
+		return fmt.Errorf("no commands matching %q", strings.Join(cmd.Query, " "))
+	}
+
+	tw := tabwriter.NewWriter(Stdout(ctx), 0, 0, 2, ' ', 0)
+	for _, c := range matches {
+		fmt.Fprintf(tw, "%s\t%s\n", c.fullName(), c.help)
+	}
+	return tw.Flush()
+}
+
+// matchesSearch returns true if query (which must be lower case) is contained
+// in the command's name, help, or description.
+func (cmd *Command) matchesSearch(query string) bool {
+	for _, s := range []string{cmd.name, cmd.help, cmd.description} {
+		if strings.Contains(strings.ToLower(s), query) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearch(t *testing.T) {
+	b := &strings.Builder{}
+	cli := NewCLI()
+	cli.Stdout = b
+	newCommand := func() *Command {
+		return cli.New(
+			"test", &struct{}{},
+			cli.New(
+				"net", &struct{}{},
+				cli.New("list", &struct{}{}, WithHelp("list networks")),
+			),
+			cli.New(
+				"vm", &struct{}{},
+				cli.New("attach", &struct{}{}, WithHelp("attach a disk"), WithDescription("Attaches a disk or NETWORK to a VM.")),
+				cli.New("secret", &struct{}{}, WithHelp("network internals"), WithHidden(true)),
+			),
+			WithSearch(),
+		)
+	}
+
+	r := newCommand().ParseArgs([]string{"search", "network"})
+	require.NoError(t, r.Err)
+	require.NoError(t, r.Run())
+	assert.Equal(t, "test net list   list networks\ntest vm attach  attach a disk\n", b.String())
+
+	r = newCommand().ParseArgs([]string{"search", "nothing"})
+	require.NoError(t, r.Err)
+	assert.EqualError(t, r.Run(), `no commands matching "nothing"`)
+
+	r = newCommand().ParseArgs([]string{"search"})
+	require.NoError(t, r.Err)
+	assert.Error(t, r.Run())
+}
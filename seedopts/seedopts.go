@@ -0,0 +1,118 @@
+// Package seedopts provides a standard --seed flag for commands which use
+// random numbers (e.g. simulations and test tooling), so that runs can be
+// reproduced:
+//
+//	type App struct {
+//		seedopts.Options
+//	}
+//
+//	func (app *App) Run() error {
+//		rng := app.Rand()
+//		...
+//	}
+//
+//	app := &App{}
+//	cli.NewCLI().
+//		Use(seedopts.Middleware(&app.Options)).
+//		New("simulate", app).
+//		Parse().
+//		RunFatal()
+//
+// By default, the seed is "random": a seed is chosen when the command is run,
+// and the middleware logs it to stderr so that the run can be repeated by
+// passing it with --seed.
+package seedopts
+
+import (
+	"context"
+	crand "crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+	"strconv"
+
+	"github.com/isobit/cli"
+)
+
+// Seed is a field type for random seeds, which are either an integer or
+// "random". The zero value is random.
+type Seed struct {
+	value int64
+	fixed bool
+}
+
+func (s *Seed) Set(v string) error {
+	if v == "random" {
+		*s = Seed{}
+		return nil
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid seed %q: must be an integer or \"random\"", v)
+	}
+	*s = Seed{value: n, fixed: true}
+	return nil
+}
+
+func (s Seed) String() string {
+	if !s.fixed {
+		return "random"
+	}
+	return strconv.FormatInt(s.value, 10)
+}
+
+func (Seed) Placeholder() string {
+	return "SEED"
+}
+
+// IsRandom reports whether a seed should be chosen at random.
+func (s Seed) IsRandom() bool {
+	return !s.fixed
+}
+
+// Options can be embedded in a command config to add a --seed flag.
+type Options struct {
+	Seed Seed `cli:"name=seed,env=SEED,help=random seed to use (an integer or \"random\")"`
+
+	chosen *int64
+}
+
+// Value returns the seed to use. If the seed is random, one is chosen the first
+// time Value is called, and the same seed is returned for subsequent calls.
+func (opts *Options) Value() int64 {
+	if !opts.Seed.IsRandom() {
+		return opts.Seed.value
+	}
+	if opts.chosen == nil {
+		seed := randomSeed()
+		opts.chosen = &seed
+	}
+	return *opts.chosen
+}
+
+// Rand returns a new math/rand source seeded with Value.
+func (opts *Options) Rand() *rand.Rand {
+	return rand.New(rand.NewSource(opts.Value()))
+}
+
+func randomSeed() int64 {
+	var b [8]byte
+	if _, err := crand.Read(b[:]); err != nil {
+		panic(fmt.Sprintf("seedopts: failed to read random seed: %s", err))
+	}
+	// Clear the sign bit so the seed is easy to pass back with --seed.
+	return int64(binary.LittleEndian.Uint64(b[:]) &^ (1 << 63))
+}
+
+// Middleware returns cli.Middleware which chooses the seed before the command
+// is run. If the seed is random, the chosen seed is logged to the command's
+// stderr (see cli.Stderr) so that the run can be reproduced.
+func Middleware(opts *Options) cli.Middleware {
+	return func(ctx context.Context, cmd *cli.Command, next func(context.Context) error) error {
+		seed := opts.Value()
+		if opts.Seed.IsRandom() {
+			fmt.Fprintf(cli.Stderr(ctx), "using random seed %d (pass --seed %d to reproduce)\n", seed, seed)
+		}
+		return next(ctx)
+	}
+}
@@ -0,0 +1,75 @@
+package seedopts
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/isobit/cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeed(t *testing.T) {
+	var s Seed
+	assert.True(t, s.IsRandom())
+	assert.Equal(t, "random", s.String())
+
+	require.NoError(t, s.Set("42"))
+	assert.False(t, s.IsRandom())
+	assert.Equal(t, "42", s.String())
+
+	require.NoError(t, s.Set("random"))
+	assert.True(t, s.IsRandom())
+
+	err := s.Set("abc")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid seed "abc"`)
+}
+
+func TestOptionsValue(t *testing.T) {
+	opts := &Options{}
+	seed := opts.Value()
+	assert.GreaterOrEqual(t, seed, int64(0))
+	assert.Equal(t, seed, opts.Value())
+
+	require.NoError(t, opts.Seed.Set("7"))
+	assert.Equal(t, int64(7), opts.Value())
+	assert.Equal(t, opts.Rand().Int63(), (&Options{Seed: Seed{value: 7, fixed: true}}).Rand().Int63())
+}
+
+type testCommand struct {
+	Options
+	got int64
+}
+
+func (c *testCommand) Run() error {
+	c.got = c.Value()
+	return nil
+}
+
+func TestMiddleware(t *testing.T) {
+	run := func(args ...string) (*testCommand, string) {
+		stderr := &strings.Builder{}
+		cmd := &testCommand{}
+		c := cli.NewCLI()
+		c.Stderr = stderr
+		err := c.Use(Middleware(&cmd.Options)).
+			New("test", cmd).
+			ParseArgs(args).
+			Run()
+		require.NoError(t, err)
+		return cmd, stderr.String()
+	}
+
+	cmd, stderr := run()
+	assert.Equal(t, fmt.Sprintf("using random seed %d (pass --seed %d to reproduce)\n", cmd.got, cmd.got), stderr)
+
+	cmd, stderr = run("--seed", "123")
+	assert.Equal(t, int64(123), cmd.got)
+	assert.Empty(t, stderr)
+
+	help := cli.New("test", &testCommand{}).HelpString()
+	assert.Contains(t, help, "--seed <SEED>")
+	assert.Contains(t, help, "(default: random)")
+}
@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// SetupStepper can be implemented by configs which need to perform several
+// independent setup steps before running, such as dialing multiple services.
+// The steps returned by SetupSteps are run concurrently (after all Before
+// methods, and before Run), and any errors are aggregated into a SetupError.
+// Steps for parent commands are run before those of their subcommands.
+type SetupStepper interface {
+	SetupSteps() []func(ctx context.Context) error
+}
+
+// SetupError is returned when one or more setup steps returned an error.
+type SetupError struct {
+	Errs []error
+}
+
+func (e *SetupError) Error() string {
+	msgs := make([]string, len(e.Errs))
+	for i, err := range e.Errs {
+		msgs[i] = err.Error()
+	}
+	return "setup failed: " + strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the errors returned by the setup steps.
+func (e *SetupError) Unwrap() []error {
+	return e.Errs
+}
+
+// runSetupSteps runs the setup steps of the command and its parents, starting
+// with the root command.
+func (cmd *Command) runSetupSteps(ctx context.Context) error {
+	if cmd.parent != nil {
+		if err := cmd.parent.runSetupSteps(ctx); err != nil {
+			return err
+		}
+	}
+	stepper, ok := cmd.config.(SetupStepper)
+	if !ok {
+		return nil
+	}
+	steps := stepper.SetupSteps()
+
+	errs := make([]error, len(steps))
+	wg := sync.WaitGroup{}
+	for i, step := range steps {
+		wg.Add(1)
+		go func(i int, step func(context.Context) error) {
+			defer wg.Done()
+			errs[i] = step(ctx)
+		}(i, step)
+	}
+	wg.Wait()
+
+	setupErr := &SetupError{}
+	for _, err := range errs {
+		if err != nil {
+			setupErr.Errs = append(setupErr.Errs, err)
+		}
+	}
+	if len(setupErr.Errs) > 0 {
+		return setupErr
+	}
+	return nil
+}
+
+type setupDoneContextKey struct{}
+
+// OnSetupDone returns a copy of ctx in which fn is called once all setup
+// steps (see SetupStepper) have completed successfully, just before the
+// command's Run method is called. This is intended for middleware which
+// needs to know when a command is ready, such as health checks.
+func OnSetupDone(ctx context.Context, fn func()) context.Context {
+	existing, _ := ctx.Value(setupDoneContextKey{}).([]func())
+	funcs := make([]func(), len(existing), len(existing)+1)
+	copy(funcs, existing)
+	return context.WithValue(ctx, setupDoneContextKey{}, append(funcs, fn))
+}
+
+// setupDone calls the functions registered with OnSetupDone.
+func setupDone(ctx context.Context) {
+	funcs, _ := ctx.Value(setupDoneContextKey{}).([]func())
+	for _, fn := range funcs {
+		fn()
+	}
+}
@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type setupTestCmd struct {
+	mu    sync.Mutex
+	order []string
+	fail  bool
+	ran   bool
+}
+
+func (cmd *setupTestCmd) record(s string) {
+	cmd.mu.Lock()
+	defer cmd.mu.Unlock()
+	cmd.order = append(cmd.order, s)
+}
+
+func (cmd *setupTestCmd) SetupSteps() []func(ctx context.Context) error {
+	started := make(chan struct{})
+	return []func(ctx context.Context) error{
+		func(ctx context.Context) error {
+			// Wait for the other step to start, to ensure they run
+			// concurrently.
+			select {
+			case <-started:
+			case <-time.After(time.Second):
+				return fmt.Errorf("steps did not run concurrently")
+			}
+			cmd.record("a")
+			if cmd.fail {
+				return fmt.Errorf("a failed")
+			}
+			return nil
+		},
+		func(ctx context.Context) error {
+			close(started)
+			if cmd.fail {
+				return fmt.Errorf("b failed")
+			}
+			return nil
+		},
+	}
+}
+
+func (cmd *setupTestCmd) Run() error {
+	cmd.ran = true
+	return nil
+}
+
+func TestSetupSteps(t *testing.T) {
+	cmd := &setupTestCmd{}
+	err := New("test", cmd).ParseArgs([]string{}).Run()
+	require.NoError(t, err)
+	assert.True(t, cmd.ran)
+	assert.Equal(t, []string{"a"}, cmd.order)
+
+	cmd = &setupTestCmd{fail: true}
+	cli := NewCLI()
+	cli.HelpWriter = nil
+	err = cli.New("test", cmd).ParseArgs([]string{}).Run()
+	require.Error(t, err)
+	assert.False(t, cmd.ran)
+	assert.Equal(t, "setup failed: a failed; b failed", err.Error())
+	var setupErr *SetupError
+	require.True(t, errors.As(err, &setupErr))
+	assert.Len(t, setupErr.Errs, 2)
+}
+
+func TestOnSetupDone(t *testing.T) {
+	cmd := &setupTestCmd{}
+	ctx := OnSetupDone(context.Background(), func() { cmd.record("done 1") })
+	ctx = OnSetupDone(ctx, func() { cmd.record("done 2") })
+	err := New("test", cmd).ParseArgs([]string{}).RunWithContext(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "done 1", "done 2"}, cmd.order)
+
+	cmd = &setupTestCmd{fail: true}
+	cli := NewCLI()
+	cli.HelpWriter = nil
+	ctx = OnSetupDone(context.Background(), func() { cmd.record("done") })
+	err = cli.New("test", cmd).ParseArgs([]string{}).RunWithContext(ctx)
+	require.Error(t, err)
+	assert.Equal(t, []string{"a"}, cmd.order)
+}
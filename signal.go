@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+)
+
+// ExitOnSignal returns a function for CLI.OnSecondSignal which immediately
+// exits the process with the given exit code. Functions registered with
+// AtExit are not called, since cleanup is likely what is taking too long.
+func ExitOnSignal(code int) func(os.Signal) {
+	return func(os.Signal) {
+		os.Exit(code)
+	}
+}
+
+func (r ParseResult) contextWithSigCancelIfSupported(ctx context.Context) (context.Context, context.CancelFunc) {
+	if r.runFunc == nil || !r.runFunc.supportsContext {
+		return ctx, func() {}
+	}
+	if onSecondSignal := r.Command.cli.OnSecondSignal; onSecondSignal != nil {
+		return contextWithSigCancel(ctx, onSecondSignal)
+	}
+	return ContextWithSigCancel(ctx)
+}
+
+// ContextWithSigCancel returns a copy of ctx which is cancelled when the
+// process receives a shutdown signal: SIGINT or SIGTERM on Unix, or Ctrl+C,
+// Ctrl+Break, or the console being closed on Windows. After the first
+// signal, subsequent signals terminate the process using the default Go
+// runtime behavior. The returned stop function should be called to release
+// resources once the context is no longer needed.
+func ContextWithSigCancel(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := signal.NotifyContext(ctx, shutdownSignals...)
+	go func() {
+		// Cancel the signal notify on the first signal so that subsequent
+		// signals immediately interrupt the program using the usual go
+		// runtime handling.
+		<-ctx.Done()
+		cancel()
+	}()
+	return ctx, cancel
+}
+
+// contextWithSigCancel returns a context which is cancelled by the first
+// shutdown signal, and calls onSecondSignal if another is received before
+// the returned stop function is called.
+func contextWithSigCancel(ctx context.Context, onSecondSignal func(os.Signal)) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, shutdownSignals...)
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-sigs:
+			cancel()
+		case <-done:
+			return
+		}
+		select {
+		case sig := <-sigs:
+			onSecondSignal(sig)
+		case <-done:
+		}
+	}()
+
+	once := sync.Once{}
+	stop := func() {
+		once.Do(func() {
+			signal.Stop(sigs)
+			close(done)
+			cancel()
+		})
+	}
+	return ctx, stop
+}
@@ -0,0 +1,13 @@
+//go:build !windows
+// +build !windows
+
+package cli
+
+import (
+	"os"
+	"syscall"
+)
+
+// shutdownSignals are the signals which cancel the context passed to
+// commands run with RunFatalWithSigCancel.
+var shutdownSignals = []os.Signal{syscall.SIGINT, syscall.SIGTERM}
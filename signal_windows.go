@@ -0,0 +1,14 @@
+package cli
+
+import (
+	"os"
+	"syscall"
+)
+
+// shutdownSignals are the signals which cancel the context passed to
+// commands run with RunFatalWithSigCancel. On Windows, the Go runtime
+// delivers both Ctrl+C and Ctrl+Break as os.Interrupt, and closing the
+// console window, logging off, or shutting down as syscall.SIGTERM. Note that
+// Windows terminates the process shortly after the console is closed (around
+// 5 seconds), so cleanup should be quick.
+var shutdownSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
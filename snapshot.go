@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// ConfigSnapshot holds a deep copy of the fields of a config struct. See
+// SnapshotConfig.
+type ConfigSnapshot struct {
+	value reflect.Value
+}
+
+// SnapshotConfig returns a deep copy of the fields of config, which must be a
+// struct pointer, which can later be restored using RestoreConfig. Only fields
+// which would be handled by this package are copied; unexported fields and
+// fields with the "-" tag are ignored. This can be used to reset a command's
+// config to its pre-parse or post-parse state, e.g. between retries, REPL
+// iterations, or tests.
+func SnapshotConfig(config interface{}) (*ConfigSnapshot, error) {
+	v, err := configStructValue(config)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := reflect.New(v.Type()).Elem()
+	copyConfigFields(snapshot, v)
+	return &ConfigSnapshot{value: snapshot}, nil
+}
+
+// RestoreConfig sets the fields of config, which must be a struct pointer of
+// the same type that the snapshot was taken from, to the values in snapshot.
+// The snapshot is not modified, so it can be restored multiple times.
+func RestoreConfig(config interface{}, snapshot *ConfigSnapshot) error {
+	v, err := configStructValue(config)
+	if err != nil {
+		return err
+	}
+	if v.Type() != snapshot.value.Type() {
+		return fmt.Errorf("snapshot type %s does not match config type %s", snapshot.value.Type(), v.Type())
+	}
+	copyConfigFields(v, snapshot.value)
+	return nil
+}
+
+// copyConfigFields deep copies the settable fields of the src struct into
+// dst, skipping fields with the "-" tag and recursing into embedded structs.
+func copyConfigFields(dst reflect.Value, src reflect.Value) {
+	for i := 0; i < dst.NumField(); i++ {
+		sf := dst.Type().Field(i)
+		dstField := dst.Field(i)
+		if !dstField.CanSet() {
+			continue
+		}
+		if _, ok := parseStructTagInner(sf.Tag.Get("cli"))["-"]; ok {
+			continue
+		}
+		if sf.Anonymous && dstField.Kind() == reflect.Struct {
+			copyConfigFields(dstField, src.Field(i))
+			continue
+		}
+		assignCopy(dstField, src.Field(i))
+	}
+}
+
+// assignCopy sets dst to a deep copy of src. If both dst and src are non-nil
+// pointers, the value pointed to by dst is set instead of replacing the
+// pointer, since setters may hold a reference to it.
+func assignCopy(dst reflect.Value, src reflect.Value) {
+	if dst.Kind() == reflect.Ptr && !dst.IsNil() && !src.IsNil() {
+		assignCopy(dst.Elem(), src.Elem())
+		return
+	}
+	dst.Set(deepCopy(src))
+}
+
+// deepCopy returns a copy of v which does not share any pointers, slices, or
+// maps with v, except those held in unexported struct fields or interfaces.
+func deepCopy(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type().Elem())
+		c.Elem().Set(deepCopy(v.Elem()))
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return c
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(iter.Key(), deepCopy(iter.Value()))
+		}
+		return c
+	case reflect.Struct:
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		for i := 0; i < c.NumField(); i++ {
+			if c.Field(i).CanSet() {
+				c.Field(i).Set(deepCopy(v.Field(i)))
+			}
+		}
+		return c
+	default:
+		return v
+	}
+}
@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotConfig(t *testing.T) {
+	type Embedded struct {
+		Level int
+	}
+	type Cmd struct {
+		Embedded
+		Name     string
+		Count    *int
+		URL      *url.URL
+		Tags     []string `cli:"append"`
+		Ignored  string   `cli:"-"`
+		internal string
+	}
+	count := 1
+	cmd := &Cmd{
+		Embedded: Embedded{Level: 2},
+		Name:     "default",
+		Count:    &count,
+		URL:      &url.URL{Scheme: "https", Host: "example.com"},
+		Tags:     []string{"a"},
+	}
+	snapshot, err := SnapshotConfig(cmd)
+	require.NoError(t, err)
+
+	r := New("test", cmd).ParseArgs([]string{
+		"--level", "3",
+		"--name", "changed",
+		"--count", "5",
+		"--url", "http://other.example.com",
+		"--tags", "b",
+	})
+	require.NoError(t, r.Err)
+	cmd.Ignored = "ignored"
+	cmd.internal = "internal"
+	assert.Equal(t, 5, count)
+
+	require.NoError(t, RestoreConfig(cmd, snapshot))
+	one := 1
+	expected := &Cmd{
+		Embedded: Embedded{Level: 2},
+		Name:     "default",
+		Count:    &one,
+		URL:      &url.URL{Scheme: "https", Host: "example.com"},
+		Tags:     []string{"a"},
+		Ignored:  "ignored",
+		internal: "internal",
+	}
+	assert.Equal(t, expected, cmd)
+
+	// Non-nil pointers are restored in place, since setters may reference
+	// them.
+	assert.Same(t, &count, cmd.Count)
+
+	// Snapshots can be restored more than once.
+	cmd.Tags[0] = "changed"
+	require.NoError(t, RestoreConfig(cmd, snapshot))
+	assert.Equal(t, []string{"a"}, cmd.Tags)
+}
+
+func TestSnapshotConfigErrors(t *testing.T) {
+	_, err := SnapshotConfig(struct{}{})
+	assert.Error(t, err)
+
+	snapshot, err := SnapshotConfig(&struct{ Foo string }{})
+	require.NoError(t, err)
+	assert.Error(t, RestoreConfig(&struct{ Bar string }{}, snapshot))
+}
@@ -0,0 +1,19 @@
+module github.com/isobit/cli/sshopts
+
+go 1.22
+
+require (
+	github.com/isobit/cli v0.0.0
+	github.com/stretchr/testify v1.7.0
+	golang.org/x/crypto v0.31.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/huandu/xstrings v1.4.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/sys v0.28.0 // indirect
+	gopkg.in/yaml.v3 v3.0.0 // indirect
+)
+
+replace github.com/isobit/cli => ../
@@ -0,0 +1,213 @@
+// Package sshopts provides a standard set of SSH connection flags which build
+// an *ssh.ClientConfig, for ops CLIs which run commands on or tunnel through
+// remote hosts:
+//
+//	type Deploy struct {
+//		sshopts.Options
+//	}
+//
+//	func (d *Deploy) Run(ctx context.Context) error {
+//		client, err := d.Dial(ctx)
+//		if err != nil {
+//			return err
+//		}
+//		defer client.Close()
+//		...
+//	}
+//
+// Keys are read from --ssh-key if set, and otherwise from the agent at
+// SSH_AUTH_SOCK. Host keys are checked against --ssh-known-hosts (default
+// ~/.ssh/known_hosts) unless --ssh-insecure-ignore-host-key is passed.
+//
+// This package is a separate module so that the cli module does not depend on
+// golang.org/x/crypto.
+package sshopts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// Options can be embedded in a command config to add flags for connecting to
+// a host over SSH.
+type Options struct {
+	Host                  string        `cli:"name=ssh-host,env=SSH_HOST,placeholder=[USER@]HOST[:PORT],help=host to connect to over SSH"`
+	Port                  int           `cli:"name=ssh-port,env=SSH_PORT,nodefault,placeholder=PORT,help=SSH port (22 if not set and not given in --ssh-host)"`
+	User                  string        `cli:"name=ssh-user,env=SSH_USER,placeholder=USER,help=SSH user (the current user if not set and not given in --ssh-host)"`
+	Key                   string        `cli:"name=ssh-key,env=SSH_KEY,placeholder=PATH,help=private key file to authenticate with (uses SSH_AUTH_SOCK if not set)"`
+	KnownHosts            string        `cli:"name=ssh-known-hosts,env=SSH_KNOWN_HOSTS,placeholder=PATH,help=known_hosts file to check host keys against (~/.ssh/known_hosts if not set)"`
+	InsecureIgnoreHostKey bool          `cli:"name=ssh-insecure-ignore-host-key,help=skip host key checking (insecure)"`
+	Timeout               time.Duration `cli:"name=ssh-timeout,env=SSH_TIMEOUT,nodefault,placeholder=DURATION,help=timeout for establishing connections (none if not set)"`
+}
+
+// target returns the user, host, and port to connect to, taking any user and
+// port given in Host into account.
+func (opts *Options) target() (string, string, int, error) {
+	host := opts.Host
+	if host == "" {
+		return "", "", 0, fmt.Errorf("no SSH host specified")
+	}
+	username := opts.User
+	if i := strings.LastIndex(host, "@"); i >= 0 {
+		username, host = host[:i], host[i+1:]
+	}
+	port := opts.Port
+	if h, p, err := net.SplitHostPort(host); err == nil {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return "", "", 0, fmt.Errorf("invalid SSH port %q", p)
+		}
+		host, port = h, n
+	}
+	if port == 0 {
+		port = 22
+	}
+	if username == "" {
+		u, err := user.Current()
+		if err != nil {
+			return "", "", 0, fmt.Errorf("no SSH user specified: %w", err)
+		}
+		username = u.Username
+	}
+	return username, host, port, nil
+}
+
+// Addr returns the "host:port" address to connect to.
+func (opts *Options) Addr() (string, error) {
+	_, host, port, err := opts.target()
+	if err != nil {
+		return "", err
+	}
+	return net.JoinHostPort(host, strconv.Itoa(port)), nil
+}
+
+// ClientConfig builds an *ssh.ClientConfig from the options.
+func (opts *Options) ClientConfig() (*ssh.ClientConfig, error) {
+	username, _, _, err := opts.target()
+	if err != nil {
+		return nil, err
+	}
+	auth, err := opts.authMethod()
+	if err != nil {
+		return nil, err
+	}
+	hostKeyCallback, err := opts.hostKeyCallback()
+	if err != nil {
+		return nil, err
+	}
+	return &ssh.ClientConfig{
+		User:            username,
+		Auth:            []ssh.AuthMethod{auth},
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         opts.Timeout,
+	}, nil
+}
+
+func (opts *Options) authMethod() (ssh.AuthMethod, error) {
+	if opts.Key != "" {
+		path, err := expandHome(opts.Key)
+		if err != nil {
+			return nil, err
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read SSH key: %w", err)
+		}
+		signer, err := ssh.ParsePrivateKey(b)
+		var passphraseErr *ssh.PassphraseMissingError
+		if errors.As(err, &passphraseErr) {
+			return nil, fmt.Errorf("SSH key %s is passphrase protected; add it to ssh-agent instead", opts.Key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse SSH key %s: %w", opts.Key, err)
+		}
+		return ssh.PublicKeys(signer), nil
+	}
+
+	socket := os.Getenv("SSH_AUTH_SOCK")
+	if socket == "" {
+		return nil, fmt.Errorf("no SSH key specified and SSH_AUTH_SOCK is not set")
+	}
+	// The connection is left open for the lifetime of the process, since the
+	// signers may be used whenever a new SSH connection is made.
+	conn, err := net.Dial("unix", socket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to ssh-agent: %w", err)
+	}
+	return ssh.PublicKeysCallback(agent.NewClient(conn).Signers), nil
+}
+
+func (opts *Options) hostKeyCallback() (ssh.HostKeyCallback, error) {
+	if opts.InsecureIgnoreHostKey {
+		return ssh.InsecureIgnoreHostKey(), nil
+	}
+	path := opts.KnownHosts
+	if path == "" {
+		path = "~/.ssh/known_hosts"
+	}
+	path, err := expandHome(path)
+	if err != nil {
+		return nil, err
+	}
+	callback, err := knownhosts.New(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read known hosts: %w", err)
+	}
+	return callback, nil
+}
+
+func expandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, path[1:]), nil
+}
+
+// Dial connects to the host using ClientConfig. The context only applies to
+// establishing the connection.
+func (opts *Options) Dial(ctx context.Context) (*ssh.Client, error) {
+	config, err := opts.ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+	addr, err := opts.Addr()
+	if err != nil {
+		return nil, err
+	}
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+	d := net.Dialer{}
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	c, chans, reqs, err := ssh.NewClientConn(conn, addr, config)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	conn.SetDeadline(time.Time{})
+	return ssh.NewClient(c, chans, reqs), nil
+}
@@ -0,0 +1,151 @@
+package sshopts
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/isobit/cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+func TestTarget(t *testing.T) {
+	for _, tc := range []struct {
+		opts Options
+		user string
+		addr string
+	}{
+		{Options{Host: "example.com", User: "alice"}, "alice", "example.com:22"},
+		{Options{Host: "bob@example.com:2222", User: "alice", Port: 23}, "bob", "example.com:2222"},
+		{Options{Host: "[::1]:2200", User: "alice"}, "alice", "[::1]:2200"},
+		{Options{Host: "example.com", User: "alice", Port: 2022}, "alice", "example.com:2022"},
+	} {
+		username, _, _, err := tc.opts.target()
+		require.NoError(t, err)
+		assert.Equal(t, tc.user, username)
+		addr, err := tc.opts.Addr()
+		require.NoError(t, err)
+		assert.Equal(t, tc.addr, addr)
+	}
+
+	_, err := (&Options{}).Addr()
+	assert.EqualError(t, err, "no SSH host specified")
+}
+
+func writeKey(t *testing.T, dir string) (ssh.Signer, string) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	block, err := ssh.MarshalPrivateKey(priv, "")
+	require.NoError(t, err)
+	path := filepath.Join(dir, "id_ed25519")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(block), 0o600))
+	signer, err := ssh.NewSignerFromKey(priv)
+	require.NoError(t, err)
+	return signer, path
+}
+
+// startServer starts an SSH server which accepts clientKey and returns its
+// address.
+func startServer(t *testing.T, hostKey ssh.Signer, clientKey ssh.PublicKey) string {
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if conn.User() == "alice" && string(key.Marshal()) == string(clientKey.Marshal()) {
+				return nil, nil
+			}
+			return nil, assert.AnError
+		},
+	}
+	config.AddHostKey(hostKey)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				_, chans, reqs, err := ssh.NewServerConn(conn, config)
+				if err != nil {
+					conn.Close()
+					return
+				}
+				go ssh.DiscardRequests(reqs)
+				for ch := range chans {
+					ch.Reject(ssh.Prohibited, "no channels")
+				}
+			}()
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func TestDial(t *testing.T) {
+	dir := t.TempDir()
+	clientKey, keyPath := writeKey(t, dir)
+	hostKey, _ := writeKey(t, t.TempDir())
+	addr := startServer(t, hostKey, clientKey.PublicKey())
+
+	knownHostsPath := filepath.Join(dir, "known_hosts")
+	line := knownhosts.Line([]string{knownhosts.Normalize(addr)}, hostKey.PublicKey())
+	require.NoError(t, os.WriteFile(knownHostsPath, []byte(line+"\n"), 0o600))
+
+	config := &struct {
+		Options
+	}{}
+	cmd := cli.New("test", config)
+	require.NoError(t, cmd.ParseArgs([]string{
+		"--ssh-host", "alice@" + addr,
+		"--ssh-key", keyPath,
+		"--ssh-known-hosts", knownHostsPath,
+	}).Err)
+	client, err := config.Dial(context.Background())
+	require.NoError(t, err)
+	client.Close()
+
+	// Unknown host keys are rejected.
+	emptyKnownHosts := filepath.Join(dir, "empty_known_hosts")
+	require.NoError(t, os.WriteFile(emptyKnownHosts, nil, 0o600))
+	opts := config.Options
+	opts.KnownHosts = emptyKnownHosts
+	_, err = opts.Dial(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "knownhosts: key is unknown")
+
+	opts.InsecureIgnoreHostKey = true
+	client, err = opts.Dial(context.Background())
+	require.NoError(t, err)
+	client.Close()
+}
+
+func TestClientConfigErrors(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("SSH_AUTH_SOCK", "")
+
+	_, err := (&Options{Host: "example.com", User: "alice"}).ClientConfig()
+	assert.EqualError(t, err, "no SSH key specified and SSH_AUTH_SOCK is not set")
+
+	_, err = (&Options{Host: "example.com", User: "alice", Key: filepath.Join(dir, "missing")}).ClientConfig()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to read SSH key")
+
+	_, keyPath := writeKey(t, dir)
+	_, err = (&Options{
+		Host:       "example.com",
+		User:       "alice",
+		Key:        keyPath,
+		KnownHosts: filepath.Join(dir, "missing_known_hosts"),
+	}).ClientConfig()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to read known hosts")
+}
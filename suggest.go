@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"sort"
+	"strings"
+)
+
+// maxSuggestionDistance is the maximum edit distance between an unknown
+// command name and a command for the command to be suggested.
+const maxSuggestionDistance = 2
+
+// unknownCommandError returns a usage error for an unknown subcommand name,
+// which suggests the closest matching subcommands if there are any.
+func (cmd *Command) unknownCommandError(name string) error {
+	suggestions := cmd.suggestCommands(name)
+	if len(suggestions) == 0 {
+		return UsageErrorf("unknown command: %s", name)
+	}
+	return UsageErrorf(
+		"unknown command: %s (did you mean %s?)",
+		name, strings.Join(suggestions, " or "),
+	)
+}
+
+// suggestCommands returns the names of visible subcommands which are similar
+// to name, either by edit distance or because name is a prefix of them,
+// closest first.
+func (cmd *Command) suggestCommands(name string) []string {
+	type suggestion struct {
+		name     string
+		distance int
+	}
+	suggestions := []suggestion{}
+	for _, subCmd := range cmd.commands {
+		if subCmd.hidden || !cmd.commandAvailable(subCmd) {
+			continue
+		}
+		lowerName, lowerSubName := strings.ToLower(name), strings.ToLower(subCmd.name)
+		d := levenshtein(lowerName, lowerSubName)
+		if d <= maxSuggestionDistance || strings.HasPrefix(lowerSubName, lowerName) {
+			suggestions = append(suggestions, suggestion{subCmd.name, d})
+		}
+	}
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].distance < suggestions[j].distance
+	})
+	names := make([]string, len(suggestions))
+	for i, s := range suggestions {
+		names[i] = s.name
+	}
+	return names
+}
+
+// levenshtein returns the edit distance between a and b, i.e. the minimum
+// number of single character insertions, deletions, or substitutions needed
+// to change a into b.
+func levenshtein(a, b string) int {
+	ar, br := []rune(a), []rune(b)
+	prev := make([]int, len(br)+1)
+	curr := make([]int, len(br)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ar); i++ {
+		curr[0] = i
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			curr[j] = min3(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(br)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
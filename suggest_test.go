@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLevenshtein(t *testing.T) {
+	assert.Equal(t, 0, levenshtein("foo", "foo"))
+	assert.Equal(t, 3, levenshtein("", "foo"))
+	assert.Equal(t, 1, levenshtein("fobar", "foobar"))
+	assert.Equal(t, 3, levenshtein("kitten", "sitting"))
+}
+
+func TestUnknownCommandSuggestions(t *testing.T) {
+	newCommand := func() *Command {
+		return New(
+			"test", &struct{}{},
+			New("foobar", &struct{}{}),
+			New("foobaz", &struct{}{}),
+			New("status", &struct{}{}),
+			New("fobar-internal", &struct{}{}, WithHidden(true)),
+		)
+	}
+
+	r := newCommand().ParseArgs([]string{"fobar"})
+	assert.EqualError(t, r.Err, "unknown command: fobar (did you mean foobar or foobaz?)")
+
+	r = newCommand().ParseArgs([]string{"help", "fobar"})
+	assert.EqualError(t, r.Err, "unknown command: fobar (did you mean foobar or foobaz?)")
+
+	r = newCommand().ParseArgs([]string{"help", "stat"})
+	assert.EqualError(t, r.Err, "unknown command: stat (did you mean status?)")
+
+	r = newCommand().ParseArgs([]string{"help", "Sta"})
+	assert.EqualError(t, r.Err, "unknown command: Sta (did you mean status?)")
+
+	r = newCommand().ParseArgs([]string{"xyzzy"})
+	assert.EqualError(t, r.Err, "unknown command: xyzzy")
+}
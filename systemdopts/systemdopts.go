@@ -0,0 +1,122 @@
+// Package systemdopts integrates commands with systemd's service notification
+// protocol (see sd_notify(3)), so that long-running CLIs can be used as
+// Type=notify services, optionally with a watchdog:
+//
+//	cli.NewCLI().
+//		Use(systemdopts.Middleware()).
+//		New("myservice", &MyService{}).
+//		Parse().
+//		RunFatalWithSigCancel()
+//
+// If the NOTIFY_SOCKET environment variable is not set (i.e. the command is
+// not running as a systemd notify service), nothing is sent.
+package systemdopts
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/isobit/cli"
+)
+
+// Notify sends state (e.g. "READY=1" or "STATUS=loading data") to the socket
+// given by the NOTIFY_SOCKET environment variable. If NOTIFY_SOCKET is not
+// set, it does nothing and returns false.
+func Notify(state string) (bool, error) {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return false, nil
+	}
+	// Names starting with @ refer to sockets in the abstract namespace.
+	if socket[0] == '@' {
+		socket = "\x00" + socket[1:]
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Watchdog returns the interval within which the service must send
+// "WATCHDOG=1" notifications, according to the WATCHDOG_USEC and
+// WATCHDOG_PID environment variables. The second return value is false if
+// the watchdog is not enabled for this process.
+func Watchdog() (time.Duration, bool) {
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0, false
+	}
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0, false
+	}
+	return time.Duration(usec) * time.Microsecond, true
+}
+
+// Middleware returns cli.Middleware which notifies systemd that the service
+// is ready once its setup steps (see cli.SetupStepper) have completed, just
+// before the command's Run method is called, and that it is stopping
+// when the context passed to Run is cancelled (e.g. by SIGTERM) or Run
+// returns. If the watchdog is enabled, "WATCHDOG=1" is sent at half the
+// watchdog interval for as long as Run is running; commands which can detect
+// that they are stuck should instead send watchdog notifications themselves
+// using Notify and Watchdog, without this middleware.
+func Middleware() cli.Middleware {
+	return func(ctx context.Context, cmd *cli.Command, next func(context.Context) error) error {
+		if os.Getenv("NOTIFY_SOCKET") == "" {
+			return next(ctx)
+		}
+		ctx = cli.OnSetupDone(ctx, func() {
+			if _, err := Notify("READY=1"); err != nil {
+				fmt.Fprintf(cli.Stderr(ctx), "warning: failed to notify systemd: %s\n", err)
+			}
+		})
+
+		stopping := sync.Once{}
+		notifyStopping := func() {
+			stopping.Do(func() {
+				Notify("STOPPING=1")
+			})
+		}
+
+		done := make(chan struct{})
+		wg := sync.WaitGroup{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var tick <-chan time.Time
+			if interval, ok := Watchdog(); ok {
+				ticker := time.NewTicker(interval / 2)
+				defer ticker.Stop()
+				tick = ticker.C
+			}
+			ctxDone := ctx.Done()
+			for {
+				select {
+				case <-tick:
+					Notify("WATCHDOG=1")
+				case <-ctxDone:
+					notifyStopping()
+					ctxDone = nil
+				case <-done:
+					return
+				}
+			}
+		}()
+
+		err := next(ctx)
+		close(done)
+		wg.Wait()
+		notifyStopping()
+		return err
+	}
+}
@@ -0,0 +1,123 @@
+package systemdopts
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/isobit/cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func listen(t *testing.T) *net.UnixConn {
+	path := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	t.Setenv("NOTIFY_SOCKET", path)
+	return conn
+}
+
+func read(t *testing.T, conn *net.UnixConn) string {
+	b := make([]byte, 256)
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	n, err := conn.Read(b)
+	require.NoError(t, err)
+	return string(b[:n])
+}
+
+func TestNotifyUnset(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", "")
+	sent, err := Notify("READY=1")
+	assert.NoError(t, err)
+	assert.False(t, sent)
+}
+
+func TestWatchdog(t *testing.T) {
+	t.Setenv("WATCHDOG_USEC", "")
+	_, ok := Watchdog()
+	assert.False(t, ok)
+
+	t.Setenv("WATCHDOG_USEC", "2000000")
+	interval, ok := Watchdog()
+	assert.True(t, ok)
+	assert.Equal(t, 2*time.Second, interval)
+
+	t.Setenv("WATCHDOG_PID", strconv.Itoa(os.Getpid()+1))
+	_, ok = Watchdog()
+	assert.False(t, ok)
+}
+
+type testCommand struct {
+	conn *net.UnixConn
+	t    *testing.T
+}
+
+func (c *testCommand) Run(ctx context.Context) error {
+	assert.Equal(c.t, "READY=1", read(c.t, c.conn))
+	assert.Equal(c.t, "WATCHDOG=1", read(c.t, c.conn))
+	return nil
+}
+
+func TestMiddleware(t *testing.T) {
+	conn := listen(t)
+	t.Setenv("WATCHDOG_USEC", "20000")
+	t.Setenv("WATCHDOG_PID", "")
+
+	err := cli.NewCLI().
+		Use(Middleware()).
+		New("test", &testCommand{conn: conn, t: t}).
+		ParseArgs([]string{}).
+		Run()
+	require.NoError(t, err)
+
+	// Drain any remaining watchdog notifications.
+	for {
+		msg := read(t, conn)
+		if msg != "WATCHDOG=1" {
+			assert.Equal(t, "STOPPING=1", msg)
+			break
+		}
+	}
+}
+
+type setupTestCommand struct {
+	conn *net.UnixConn
+	t    *testing.T
+}
+
+func (c *setupTestCommand) SetupSteps() []func(ctx context.Context) error {
+	return []func(ctx context.Context) error{
+		func(ctx context.Context) error {
+			// Nothing should have been sent before setup is done.
+			require.NoError(c.t, c.conn.SetReadDeadline(time.Now().Add(50*time.Millisecond)))
+			_, err := c.conn.Read(make([]byte, 256))
+			var netErr net.Error
+			assert.ErrorAs(c.t, err, &netErr)
+			return nil
+		},
+	}
+}
+
+func (c *setupTestCommand) Run(ctx context.Context) error {
+	assert.Equal(c.t, "READY=1", read(c.t, c.conn))
+	return nil
+}
+
+func TestMiddlewareSetupSteps(t *testing.T) {
+	conn := listen(t)
+	t.Setenv("WATCHDOG_USEC", "")
+
+	err := cli.NewCLI().
+		Use(Middleware()).
+		New("test", &setupTestCommand{conn: conn, t: t}).
+		ParseArgs([]string{}).
+		Run()
+	require.NoError(t, err)
+	assert.Equal(t, "STOPPING=1", read(t, conn))
+}
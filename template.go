@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"text/template"
+)
+
+// Template is a field type for text/template templates, e.g. for a
+// "--format '{{.Name}}'" flag. The template is parsed when the flag is set,
+// so syntax errors are reported as usage errors before Run is called.
+type Template struct {
+	// Funcs are added to the template before it is parsed. To use custom
+	// functions, set this in the config's default value.
+	Funcs template.FuncMap
+
+	text string
+	tmpl *template.Template
+}
+
+// MustTemplate returns a Template parsed from text (with the given
+// functions), which is useful for default values. It panics if text can't be
+// parsed.
+func MustTemplate(text string, funcs ...template.FuncMap) Template {
+	t := Template{}
+	for _, fm := range funcs {
+		if t.Funcs == nil {
+			t.Funcs = template.FuncMap{}
+		}
+		for name, fn := range fm {
+			t.Funcs[name] = fn
+		}
+	}
+	if err := t.Set(text); err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func (t *Template) Set(s string) error {
+	tmpl := template.New("template")
+	if t.Funcs != nil {
+		tmpl = tmpl.Funcs(t.Funcs)
+	}
+	tmpl, err := tmpl.Parse(s)
+	if err != nil {
+		return templateError(err)
+	}
+	t.text = s
+	t.tmpl = tmpl
+	return nil
+}
+
+func (t Template) String() string {
+	return t.text
+}
+
+// Template returns the parsed template, or nil if none has been set.
+func (t *Template) Template() *template.Template {
+	return t.tmpl
+}
+
+// Execute applies the template to data, writing the output to w. If no
+// template has been set, nothing is written.
+func (t *Template) Execute(w io.Writer, data interface{}) error {
+	if t.tmpl == nil {
+		return nil
+	}
+	return t.tmpl.Execute(w, data)
+}
+
+var templateErrorRegexp = regexp.MustCompile(`^template: [^:]*:(\d+):(?:(\d+):)? (.*)$`)
+
+// templateError reformats template parse errors, which look like "template:
+// NAME:LINE: MESSAGE", to mention the line (and column, if known) more
+// readably.
+func templateError(err error) error {
+	m := templateErrorRegexp.FindStringSubmatch(err.Error())
+	if m == nil {
+		return err
+	}
+	if m[2] != "" {
+		return fmt.Errorf("invalid template at line %s, column %s: %s", m[1], m[2], m[3])
+	}
+	return fmt.Errorf("invalid template at line %s: %s", m[1], m[3])
+}
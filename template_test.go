@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplate(t *testing.T) {
+	config := &struct {
+		Format Template
+	}{
+		Format: MustTemplate("{{.Name | upper}}", template.FuncMap{"upper": strings.ToUpper}),
+	}
+	cmd := New("test", config)
+	assert.Contains(t, cmd.HelpString(), "(default: {{.Name | upper}})")
+
+	b := &strings.Builder{}
+	require.NoError(t, config.Format.Execute(b, map[string]string{"Name": "foo"}))
+	assert.Equal(t, "FOO", b.String())
+
+	require.NoError(t, cmd.ParseArgs([]string{"--format", "{{.Name}}: {{upper .Name}}"}).Err)
+	b.Reset()
+	require.NoError(t, config.Format.Execute(b, map[string]string{"Name": "foo"}))
+	assert.Equal(t, "foo: FOO", b.String())
+
+	r := New("test", config).ParseArgs([]string{"--format", "ok\n{{.Name"})
+	require.Error(t, r.Err)
+	assert.Contains(t, r.Err.Error(), "invalid template at line 2: unclosed action")
+
+	r = New("test", config).ParseArgs([]string{"--format", "{{lower .Name}}"})
+	require.Error(t, r.Err)
+	assert.Contains(t, r.Err.Error(), `invalid template at line 1: function "lower" not defined`)
+
+	var empty Template
+	b.Reset()
+	require.NoError(t, empty.Execute(b, nil))
+	assert.Empty(t, b.String())
+}
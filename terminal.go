@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"io"
+	"os"
+	"strconv"
+)
+
+// IsTerminal returns true if w is a file which refers to a terminal (i.e. a
+// character device), such as os.Stdout when it has not been redirected.
+func IsTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok || f == nil {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// styledWriter returns w unchanged if it is a terminal, otherwise it returns a
+// writer which strips ANSI escape sequences (colors, cursor movement, etc.)
+// before writing to w.
+func styledWriter(w io.Writer) io.Writer {
+	if IsTerminal(w) {
+		return w
+	}
+	return &ansiStripWriter{w: w}
+}
+
+const (
+	ansiStateText = iota
+	ansiStateEscape
+	ansiStateCSI
+	ansiStateOSC
+	ansiStateOSCEscape
+)
+
+// ansiStripWriter removes ANSI escape sequences from the bytes written to it.
+// It keeps track of state between writes so that sequences split across
+// multiple writes are also removed.
+type ansiStripWriter struct {
+	w     io.Writer
+	state int
+}
+
+func (sw *ansiStripWriter) Write(p []byte) (int, error) {
+	out := make([]byte, 0, len(p))
+	for _, c := range p {
+		switch sw.state {
+		case ansiStateText:
+			if c == 0x1b {
+				sw.state = ansiStateEscape
+			} else {
+				out = append(out, c)
+			}
+		case ansiStateEscape:
+			switch c {
+			case '[':
+				sw.state = ansiStateCSI
+			case ']':
+				sw.state = ansiStateOSC
+			default:
+				// Two-byte sequence, e.g. "ESC c".
+				sw.state = ansiStateText
+			}
+		case ansiStateCSI:
+			// CSI sequences end with a byte in the range 0x40-0x7e.
+			if c >= 0x40 && c <= 0x7e {
+				sw.state = ansiStateText
+			}
+		case ansiStateOSC:
+			// OSC sequences end with BEL or ST ("ESC \").
+			if c == 0x07 {
+				sw.state = ansiStateText
+			} else if c == 0x1b {
+				sw.state = ansiStateOSCEscape
+			}
+		case ansiStateOSCEscape:
+			if c == '\\' {
+				sw.state = ansiStateText
+			} else {
+				sw.state = ansiStateOSC
+			}
+		}
+	}
+	if _, err := sw.w.Write(out); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// TerminalSize returns the width and height (in columns and rows) of the
+// terminal which w refers to. If w is not a terminal or its size can't be
+// determined, the COLUMNS and LINES environment variables are used if set,
+// otherwise ok is false.
+func TerminalSize(w io.Writer) (cols, rows int, ok bool) {
+	if f, isFile := w.(*os.File); isFile && f != nil && IsTerminal(f) {
+		cols, rows, ok = terminalSize(f.Fd())
+		if ok && cols > 0 && rows > 0 {
+			return cols, rows, true
+		}
+	}
+	cols, colsErr := strconv.Atoi(os.Getenv("COLUMNS"))
+	rows, rowsErr := strconv.Atoi(os.Getenv("LINES"))
+	if colsErr != nil || rowsErr != nil || cols <= 0 || rows <= 0 {
+		return 0, 0, false
+	}
+	return cols, rows, true
+}
@@ -0,0 +1,8 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd
+// +build !linux,!darwin,!dragonfly,!freebsd,!netbsd,!openbsd
+
+package cli
+
+func terminalSize(fd uintptr) (cols, rows int, ok bool) {
+	return 0, 0, false
+}
@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsTerminal(t *testing.T) {
+	assert.False(t, IsTerminal(&strings.Builder{}))
+	assert.False(t, IsTerminal((*os.File)(nil)))
+
+	f, err := os.CreateTemp(t.TempDir(), "out")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	assert.False(t, IsTerminal(f))
+}
+
+func TestANSIStripWriter(t *testing.T) {
+	b := &strings.Builder{}
+	w := styledWriter(b)
+	w.Write([]byte("\x1b[1mbold\x1b[0m and \x1b[31"))
+	w.Write([]byte(";1mred\x1b[0m \x1b]8;;http://example.com\x1b\\link\x1b]8;;\x07"))
+	assert.Equal(t, "bold and red link", b.String())
+}
+
+func TestHelpStripsANSI(t *testing.T) {
+	b := &strings.Builder{}
+	cli := CLI{HelpWriter: b}
+	c := cli.New("test", &struct {
+		Foo string `cli:"help=\x1b[1mimportant\x1b[0m"`
+	}{})
+	c.WriteHelp(b)
+	assert.NotContains(t, b.String(), "\x1b")
+	assert.Contains(t, b.String(), "important")
+}
+
+func TestTerminalSizeFromEnv(t *testing.T) {
+	t.Setenv("COLUMNS", "")
+	t.Setenv("LINES", "")
+	_, _, ok := TerminalSize(&strings.Builder{})
+	assert.False(t, ok)
+
+	t.Setenv("COLUMNS", "120")
+	t.Setenv("LINES", "40")
+	cols, rows, ok := TerminalSize(&strings.Builder{})
+	assert.True(t, ok)
+	assert.Equal(t, 120, cols)
+	assert.Equal(t, 40, rows)
+}
@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+)
+
+// TimingsOptions can be embedded in a command config to add a standard
+// --timings flag. When it is passed, the durations of the Before and Run
+// methods of the command and each of its parents are printed to the CLI's
+// ErrWriter once the command completes.
+type TimingsOptions struct {
+	Timings bool `cli:"name=timings,help=print command timings on completion"`
+}
+
+func (o *TimingsOptions) timings() bool {
+	return o.Timings
+}
+
+type timingser interface {
+	timings() bool
+}
+
+type commandTimings struct {
+	before time.Duration
+	run    time.Duration
+}
+
+// timingsEnabled returns true if --timings was passed to this command or any
+// of its parents.
+func (cmd *Command) timingsEnabled() bool {
+	for c := cmd; c != nil; c = c.parent {
+		if t, ok := c.config.(timingser); ok && t.timings() {
+			return true
+		}
+	}
+	return false
+}
+
+// writeTimings writes the recorded durations for the command and its parents
+// to w, starting with the root command.
+func (cmd *Command) writeTimings(w io.Writer) {
+	chain := []*Command{}
+	for c := cmd; c != nil; c = c.parent {
+		chain = append([]*Command{c}, chain...)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "timings:")
+	for _, c := range chain {
+		if _, ok := c.config.(Beforer); ok {
+			fmt.Fprintf(tw, "    %s\tbefore\t%s\n", c.fullName(), c.timings.before)
+		}
+	}
+	fmt.Fprintf(tw, "    %s\trun\t%s\n", cmd.fullName(), cmd.timings.run)
+	tw.Flush()
+}
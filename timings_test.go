@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type timingsTestRoot struct {
+	TimingsOptions
+}
+
+func (*timingsTestRoot) Before() error {
+	return nil
+}
+
+type timingsTestSub struct{}
+
+func (*timingsTestSub) Run() error {
+	return nil
+}
+
+func TestTimings(t *testing.T) {
+	b := &strings.Builder{}
+	cli := CLI{ErrWriter: b}
+	newCommand := func() *Command {
+		return cli.New("test", &timingsTestRoot{},
+			cli.New("sub", &timingsTestSub{}),
+		)
+	}
+
+	err := newCommand().ParseArgs([]string{"sub"}).Run()
+	require.NoError(t, err)
+	assert.Equal(t, "", b.String())
+
+	err = newCommand().ParseArgs([]string{"--timings", "sub"}).Run()
+	require.NoError(t, err)
+	assert.Regexp(t, `^timings:
+    test      before  \S+
+    test sub  run     \S+
+$`, b.String())
+}
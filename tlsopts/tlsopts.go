@@ -0,0 +1,67 @@
+// Package tlsopts provides embeddable flags for configuring TLS clients with
+// custom CAs and client certificates:
+//
+//	type App struct {
+//		tlsopts.Options
+//	}
+//
+//	func (app *App) Run() error {
+//		tlsConfig, err := app.Config()
+//		...
+//	}
+//
+// Option packages for specific services (e.g. kafkaopts) define their own
+// prefixed flags and use Load to build the *tls.Config.
+package tlsopts
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// Options can be embedded in a command config to add TLS client flags.
+type Options struct {
+	CAFile             string `cli:"name=tls-ca,env=TLS_CA,placeholder=PATH,help=PEM file of CA certificates to trust (the system roots if not set)"`
+	CertFile           string `cli:"name=tls-cert,env=TLS_CERT,placeholder=PATH,help=PEM client certificate file"`
+	KeyFile            string `cli:"name=tls-key,env=TLS_KEY,placeholder=PATH,help=PEM client key file"`
+	InsecureSkipVerify bool   `cli:"name=tls-insecure-skip-verify,help=skip server certificate verification (insecure)"`
+}
+
+// Config builds a *tls.Config from the options.
+func (opts *Options) Config() (*tls.Config, error) {
+	return Load(opts.CAFile, opts.CertFile, opts.KeyFile, opts.InsecureSkipVerify)
+}
+
+// Load builds a *tls.Config which trusts the CA certificates in caFile (if
+// set) and presents the client certificate in certFile and keyFile (if set).
+// certFile and keyFile must be set together.
+func Load(caFile, certFile, keyFile string, insecureSkipVerify bool) (*tls.Config, error) {
+	config := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: insecureSkipVerify,
+	}
+	if caFile != "" {
+		b, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(b) {
+			return nil, fmt.Errorf("no PEM certificates found in CA file %s", caFile)
+		}
+		config.RootCAs = pool
+	}
+	if (certFile == "") != (keyFile == "") {
+		return nil, fmt.Errorf("client certificate and key files must be given together")
+	}
+	if certFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
@@ -0,0 +1,89 @@
+package tlsopts
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/isobit/cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeCert writes a self-signed certificate and its key to dir.
+func writeCert(t *testing.T, dir string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return certFile, keyFile
+}
+
+func TestConfig(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeCert(t, dir)
+
+	config := &struct {
+		Options
+	}{}
+	cmd := cli.New("test", config)
+	assert.Contains(t, cmd.HelpString(), "--tls-ca <PATH>")
+	require.NoError(t, cmd.ParseArgs([]string{
+		"--tls-ca", certFile,
+		"--tls-cert", certFile,
+		"--tls-key", keyFile,
+	}).Err)
+
+	tlsConfig, err := config.Config()
+	require.NoError(t, err)
+	assert.NotNil(t, tlsConfig.RootCAs)
+	assert.Len(t, tlsConfig.Certificates, 1)
+	assert.False(t, tlsConfig.InsecureSkipVerify)
+
+	tlsConfig, err = (&Options{}).Config()
+	require.NoError(t, err)
+	assert.Nil(t, tlsConfig.RootCAs)
+	assert.Empty(t, tlsConfig.Certificates)
+}
+
+func TestLoadErrors(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeCert(t, dir)
+
+	_, err := Load(keyFile, "", "", false)
+	assert.EqualError(t, err, "no PEM certificates found in CA file "+keyFile)
+
+	_, err = Load("", certFile, "", false)
+	assert.EqualError(t, err, "client certificate and key files must be given together")
+
+	_, err = Load("", keyFile, certFile, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to load client certificate")
+
+	_, err = Load(filepath.Join(dir, "missing.pem"), "", "", false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to read CA file")
+}
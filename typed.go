@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"context"
+)
+
+// TypedCommand is a Command whose config is known to be a *T, so that run
+// handlers can receive the config directly without type assertions. It embeds
+// *Command, so it can be used anywhere a Command can (including as a
+// CommandOption to register it as a subcommand).
+type TypedCommand[T any] struct {
+	*Command
+
+	// Config is the config which flags are parsed into.
+	Config *T
+}
+
+// NewT creates a new TypedCommand with the provided name, using a newly
+// allocated *T as the config. Default values can be set by implementing
+// Defaulter on *T, so that they are shown in help text.
+func NewT[T any](name string, opts ...CommandOption) *TypedCommand[T] {
+	return NewTWithCLI[T](defaultCLI, name, opts...)
+}
+
+// NewTWithCLI is like NewT, but it uses the settings of the given CLI (since
+// methods can't have type parameters).
+func NewTWithCLI[T any](cli *CLI, name string, opts ...CommandOption) *TypedCommand[T] {
+	config := new(T)
+	return &TypedCommand[T]{
+		Command: cli.New(name, config, opts...),
+		Config:  config,
+	}
+}
+
+// SetRun sets the function which is called to run the command, which takes
+// precedence over any Run method implemented by T.
+func (tc *TypedCommand[T]) SetRun(run func(ctx context.Context, config *T) error) *TypedCommand[T] {
+	tc.Command.runFunc = &runFunc{
+		run: func(ctx context.Context) error {
+			return run(ctx, tc.Config)
+		},
+		supportsContext: true,
+	}
+	return tc
+}
+
+// ConfigOf returns the config of the command which was parsed into r, if it
+// is a *T.
+func ConfigOf[T any](r ParseResult) (*T, bool) {
+	if r.Command == nil {
+		return nil, false
+	}
+	config, ok := r.Command.config.(*T)
+	return config, ok
+}
@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type typedTestConfig struct {
+	Name string `cli:"short=n"`
+}
+
+func (c *typedTestConfig) Defaults() {
+	c.Name = "world"
+}
+
+func TestNewT(t *testing.T) {
+	var got *typedTestConfig
+	sub := NewT[typedTestConfig]("greet").
+		SetRun(func(ctx context.Context, config *typedTestConfig) error {
+			got = config
+			return nil
+		})
+	c := New("test", &struct{}{}, sub)
+	assert.Contains(t, sub.HelpString(), "(default: world)")
+
+	r := c.ParseArgs([]string{"greet", "-n", "foo"})
+	require.NoError(t, r.Err)
+
+	config, ok := ConfigOf[typedTestConfig](r)
+	require.True(t, ok)
+	assert.Same(t, sub.Config, config)
+	assert.Equal(t, "foo", config.Name)
+
+	_, ok = ConfigOf[struct{}](r)
+	assert.False(t, ok)
+
+	require.NoError(t, r.Run())
+	assert.Same(t, sub.Config, got)
+}
@@ -1,16 +1,27 @@
 package cli
 
 import (
+	"bytes"
 	"encoding"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
 	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
 // setters
 
 func tryGetSetter(i interface{}) Setter {
 	switch v := i.(type) {
+	case ContextSetter:
+		return contextSetter{v}
 	case Setter:
 		return v
 	case encoding.TextUnmarshaler:
@@ -32,6 +43,176 @@ func tryGetSetter(i interface{}) Setter {
 	}
 }
 
+// ContextSetter
+
+type contextSetter struct {
+	ContextSetter
+}
+
+func (cs contextSetter) Set(s string) error {
+	return cs.SetWithContext(SetterContext{}, s)
+}
+
+// help flag
+
+// helpSetter sets the help flag of a command, which is a boolean flag that
+// can optionally be passed a help topic instead of a boolean value.
+type helpSetter struct {
+	cmd *Command
+}
+
+func (hs helpSetter) Set(s string) error {
+	if b, err := strconv.ParseBool(s); err == nil {
+		hs.cmd.helpRequested = b
+		return nil
+	}
+	hs.cmd.helpRequested = true
+	hs.cmd.helpTopic = s
+	return nil
+}
+
+// key-value struct
+
+// kvSetter sets the Key and Value fields of a struct by splitting values on
+// a separator, e.g. "k=v" or "X-Foo: bar".
+type kvSetter struct {
+	key   reflect.Value
+	value reflect.Value
+	sep   string
+}
+
+func newKVSetter(v reflect.Value, sep string) (kvSetter, error) {
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return kvSetter{}, fmt.Errorf("field has kv tag but type is not a struct with Key and Value string fields")
+	}
+	key, value := v.FieldByName("Key"), v.FieldByName("Value")
+	if !key.IsValid() || key.Kind() != reflect.String || !value.IsValid() || value.Kind() != reflect.String {
+		return kvSetter{}, fmt.Errorf("field has kv tag but type is not a struct with Key and Value string fields")
+	}
+	return kvSetter{key: key, value: value, sep: sep}, nil
+}
+
+func (kv kvSetter) Set(s string) error {
+	k, v, ok := strings.Cut(s, kv.sep)
+	if !ok {
+		return fmt.Errorf("expected KEY%sVALUE", kv.sep)
+	}
+	kv.key.SetString(strings.TrimSpace(k))
+	kv.value.SetString(strings.TrimSpace(v))
+	return nil
+}
+
+// kvStringer formats a kv struct, or a slice of them, as comma separated
+// KEY=VALUE pairs (using the separator).
+type kvStringer struct {
+	v   reflect.Value
+	sep string
+}
+
+func (ks kvStringer) String() string {
+	v := reflect.Indirect(ks.v)
+	if v.Kind() != reflect.Slice {
+		return ks.pair(v)
+	}
+	pairs := []string{}
+	for i := 0; i < v.Len(); i++ {
+		if pair := ks.pair(v.Index(i)); pair != "" {
+			pairs = append(pairs, pair)
+		}
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (ks kvStringer) pair(v reflect.Value) string {
+	v = reflect.Indirect(v)
+	if !v.IsValid() || v.IsZero() {
+		return ""
+	}
+	return v.FieldByName("Key").String() + ks.sep + v.FieldByName("Value").String()
+}
+
+// encoded values
+
+// decodeSetter sets a value by decoding inline data (e.g. JSON), or the
+// contents of a file if the value starts with "@". Values are decoded into a
+// new value which is only assigned if decoding succeeds. If CLI.ResponseFiles
+// is enabled, a separate "@file" argument is expanded as a response file
+// before it gets here, so the "--flag=@file" form must be used instead.
+type decodeSetter struct {
+	v      reflect.Value
+	decode func(b []byte, v interface{}) error
+}
+
+func newDecodeSetter(v reflect.Value, decode func([]byte, interface{}) error) decodeSetter {
+	if v.Kind() != reflect.Ptr {
+		v = v.Addr()
+	}
+	return decodeSetter{v: v, decode: decode}
+}
+
+func (ds decodeSetter) Set(s string) error {
+	b := []byte(s)
+	if strings.HasPrefix(s, "@") {
+		var err error
+		b, err = os.ReadFile(s[1:])
+		if err != nil {
+			return err
+		}
+	}
+	decoded := reflect.New(ds.v.Elem().Type())
+	if err := ds.decode(b, decoded.Interface()); err != nil {
+		return err
+	}
+	ds.v.Elem().Set(decoded.Elem())
+	return nil
+}
+
+// decodeJSON decodes a single JSON value from b into v, returning an error
+// for unknown object keys (if v is a struct) or trailing data.
+func decodeJSON(b []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if dec.More() {
+		return fmt.Errorf("unexpected data after JSON value")
+	}
+	return nil
+}
+
+// decodeYAML decodes a single YAML document from b into v, returning an
+// error for unknown keys (if v is a struct).
+func decodeYAML(b []byte, v interface{}) error {
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+	if err := dec.Decode(v); err != nil && err != io.EOF {
+		return err
+	}
+	return nil
+}
+
+// encodeStringer formats a value using an encoding function such as
+// json.Marshal, or as an empty string if it is a zero value.
+type encodeStringer struct {
+	v      interface{}
+	encode func(v interface{}) ([]byte, error)
+}
+
+func (es encodeStringer) String() string {
+	if v := reflect.ValueOf(es.v); !v.IsValid() || v.IsZero() {
+		return ""
+	}
+	b, err := es.encode(es.v)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(b))
+}
+
 // string
 
 type stringSetter struct {
@@ -116,5 +297,14 @@ type sprintfStringer struct {
 }
 
 func (ss sprintfStringer) String() string {
-	return fmt.Sprintf("%v", ss.v)
+	// Dereference non-nil pointers so that the pointed-to value is printed
+	// instead of an address.
+	v := reflect.ValueOf(ss.v)
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return fmt.Sprintf("%v", ss.v)
+	}
+	return fmt.Sprintf("%v", v.Interface())
 }
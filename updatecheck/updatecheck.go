@@ -0,0 +1,289 @@
+// Package updatecheck provides cli middleware which notifies users when a
+// newer version of the program is available.
+//
+//	checker := &updatecheck.Checker{
+//		Name:           "mycli",
+//		CurrentVersion: version,
+//		Latest:         updatecheck.GitHubLatestRelease("owner", "mycli"),
+//	}
+//	cli.NewCLI().
+//		Use(checker.Middleware()).
+//		New("mycli", &MyCLI{}).
+//		Parse().
+//		RunFatal()
+package updatecheck
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/isobit/cli"
+)
+
+const (
+	// DefaultInterval is the default minimum time between checks.
+	DefaultInterval = 24 * time.Hour
+
+	// DefaultRetryInterval is the default minimum time between checks after
+	// a check fails.
+	DefaultRetryInterval = time.Hour
+
+	// DefaultTimeout is the default time limit for a check.
+	DefaultTimeout = 2 * time.Second
+)
+
+// Checker checks for newer versions of a program.
+type Checker struct {
+	// Name is the name of the program, which is used in the notice and for
+	// the default cache file location.
+	Name string
+
+	// CurrentVersion is the version of the running program. If empty or
+	// "dev", no checks are made.
+	CurrentVersion string
+
+	// Latest is called to get the latest released version.
+	Latest func(ctx context.Context) (string, error)
+
+	// CacheFile is the path of the file which stores the result of the last
+	// check. If empty, "update-check.json" in a directory named after Name in
+	// os.UserCacheDir is used.
+	CacheFile string
+
+	// Interval is the minimum time between checks; in between, the cached
+	// result is used. If zero, DefaultInterval is used.
+	Interval time.Duration
+
+	// RetryInterval is the minimum time between checks after a check fails
+	// (e.g. when offline or rate limited), so that failing checks aren't
+	// repeated on every run. If zero, DefaultRetryInterval is used.
+	RetryInterval time.Duration
+
+	// Timeout limits how long a check can take, including how long the
+	// program will wait for it after the command completes. If zero,
+	// DefaultTimeout is used.
+	Timeout time.Duration
+
+	// Writer is where the notice is printed. If nil, os.Stderr is used. The
+	// notice is only printed if Writer is a terminal.
+	Writer io.Writer
+}
+
+type cacheEntry struct {
+	CheckedAt time.Time `json:"checked_at"`
+	Latest    string    `json:"latest"`
+
+	// Failed is true if the last check failed, in which case Latest is the
+	// result of the last successful check, if any.
+	Failed bool `json:"failed,omitempty"`
+}
+
+// Middleware returns cli.Middleware which starts a check in the background
+// while the command runs, and prints a one-line notice once it completes if a
+// newer version is available. Checks are skipped if the notice would not be
+// shown, i.e. if Writer is not a terminal or a CI environment is detected.
+// Errors are ignored, since a failed check shouldn't affect the command.
+func (c *Checker) Middleware() cli.Middleware {
+	return func(ctx context.Context, cmd *cli.Command, next func(context.Context) error) error {
+		w := c.writer()
+		if !c.enabled(w) {
+			return next(ctx)
+		}
+
+		timeout := c.Timeout
+		if timeout == 0 {
+			timeout = DefaultTimeout
+		}
+		checkCtx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		result := make(chan string, 1)
+		go func() {
+			latest, err := c.Check(checkCtx)
+			if err != nil {
+				latest = ""
+			}
+			result <- latest
+		}()
+
+		err := next(ctx)
+
+		select {
+		case latest := <-result:
+			if latest != "" && CompareVersions(latest, c.CurrentVersion) > 0 {
+				fmt.Fprintf(w, "A new version of %s is available: %s (current: %s)\n", c.Name, latest, c.CurrentVersion)
+			}
+		case <-checkCtx.Done():
+		}
+		return err
+	}
+}
+
+func (c *Checker) writer() io.Writer {
+	if c.Writer != nil {
+		return c.Writer
+	}
+	return os.Stderr
+}
+
+func (c *Checker) enabled(w io.Writer) bool {
+	if c.CurrentVersion == "" || c.CurrentVersion == "dev" || c.Latest == nil {
+		return false
+	}
+	if _, isCI := cli.DetectCI(); isCI {
+		return false
+	}
+	return cli.IsTerminal(w)
+}
+
+// Check returns the latest version, using the cached result if the last
+// check was less than Interval ago (or RetryInterval, if it failed).
+// Otherwise, Latest is called and the result is cached. Failures are also
+// recorded in the cache, so that they are not retried until RetryInterval
+// has passed.
+func (c *Checker) Check(ctx context.Context) (string, error) {
+	interval := c.Interval
+	if interval == 0 {
+		interval = DefaultInterval
+	}
+	retryInterval := c.RetryInterval
+	if retryInterval == 0 {
+		retryInterval = DefaultRetryInterval
+	}
+
+	path, err := c.cacheFile()
+	if err != nil {
+		return "", err
+	}
+	entry, err := readCache(path)
+	if err == nil {
+		if entry.Failed {
+			interval = retryInterval
+		}
+		if time.Since(entry.CheckedAt) < interval {
+			return entry.Latest, nil
+		}
+	}
+
+	latest, err := c.Latest(ctx)
+	if err != nil {
+		// Keep the last known latest version, and ignore any error writing
+		// the cache in favor of the check error.
+		writeCache(path, cacheEntry{CheckedAt: time.Now(), Latest: entry.Latest, Failed: true})
+		return "", err
+	}
+	if err := writeCache(path, cacheEntry{CheckedAt: time.Now(), Latest: latest}); err != nil {
+		return "", err
+	}
+	return latest, nil
+}
+
+func (c *Checker) cacheFile() (string, error) {
+	if c.CacheFile != "" {
+		return c.CacheFile, nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, c.Name, "update-check.json"), nil
+}
+
+func readCache(path string) (cacheEntry, error) {
+	entry := cacheEntry{}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return entry, err
+	}
+	err = json.Unmarshal(b, &entry)
+	return entry, err
+}
+
+func writeCache(path string, entry cacheEntry) error {
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0o644)
+}
+
+// GitHubLatestRelease returns a function which can be used as Checker.Latest
+// to get the tag name of the latest release of a GitHub repository.
+func GitHubLatestRelease(owner string, repo string) func(ctx context.Context) (string, error) {
+	return func(ctx context.Context) (string, error) {
+		url := fmt.Sprintf("https://api.github.com/repos/%s/%s/releases/latest", owner, repo)
+		return fetchLatestRelease(ctx, url)
+	}
+}
+
+func fetchLatestRelease(ctx context.Context, url string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected response status from %s: %s", url, resp.Status)
+	}
+	release := struct {
+		TagName string `json:"tag_name"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return "", err
+	}
+	return release.TagName, nil
+}
+
+// CompareVersions compares two dotted version strings (e.g. "v1.2.3"),
+// returning -1, 0, or 1 if a is less than, equal to, or greater than b. A
+// leading "v" and any pre-release or build suffix (starting with "-" or "+")
+// are ignored, and missing or non-numeric components are treated as 0.
+func CompareVersions(a string, b string) int {
+	aParts := versionParts(a)
+	bParts := versionParts(b)
+	for i := 0; i < len(aParts) || i < len(bParts); i++ {
+		var x, y int
+		if i < len(aParts) {
+			x = aParts[i]
+		}
+		if i < len(bParts) {
+			y = bParts[i]
+		}
+		if x < y {
+			return -1
+		}
+		if x > y {
+			return 1
+		}
+	}
+	return 0
+}
+
+func versionParts(v string) []int {
+	v = strings.TrimPrefix(v, "v")
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v = v[:i]
+	}
+	parts := []int{}
+	for _, s := range strings.Split(v, ".") {
+		n, _ := strconv.Atoi(s)
+		parts = append(parts, n)
+	}
+	return parts
+}
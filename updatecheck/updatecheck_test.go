@@ -0,0 +1,136 @@
+package updatecheck
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/isobit/cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareVersions(t *testing.T) {
+	assert.Equal(t, 0, CompareVersions("v1.2.3", "1.2.3"))
+	assert.Equal(t, 1, CompareVersions("v1.10.0", "v1.9.9"))
+	assert.Equal(t, -1, CompareVersions("1.2", "1.2.1"))
+	assert.Equal(t, 0, CompareVersions("1.2.0-rc.1", "1.2"))
+}
+
+func TestCheckCaching(t *testing.T) {
+	calls := 0
+	c := &Checker{
+		Name:           "test",
+		CurrentVersion: "v1.0.0",
+		CacheFile:      filepath.Join(t.TempDir(), "test", "cache.json"),
+		Latest: func(ctx context.Context) (string, error) {
+			calls++
+			return "v1.1.0", nil
+		},
+	}
+
+	for i := 0; i < 2; i++ {
+		latest, err := c.Check(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "v1.1.0", latest)
+	}
+	assert.Equal(t, 1, calls)
+
+	require.NoError(t, writeCache(c.CacheFile, cacheEntry{
+		CheckedAt: time.Now().Add(-2 * DefaultInterval),
+		Latest:    "v1.0.0",
+	}))
+	latest, err := c.Check(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "v1.1.0", latest)
+	assert.Equal(t, 2, calls)
+}
+
+func TestCheckCachesFailures(t *testing.T) {
+	calls := 0
+	fail := true
+	c := &Checker{
+		Name:           "test",
+		CurrentVersion: "v1.0.0",
+		CacheFile:      filepath.Join(t.TempDir(), "cache.json"),
+		Latest: func(ctx context.Context) (string, error) {
+			calls++
+			if fail {
+				return "", fmt.Errorf("offline")
+			}
+			return "v1.2.0", nil
+		},
+	}
+	require.NoError(t, writeCache(c.CacheFile, cacheEntry{
+		CheckedAt: time.Now().Add(-2 * DefaultInterval),
+		Latest:    "v1.1.0",
+	}))
+
+	_, err := c.Check(context.Background())
+	require.EqualError(t, err, "offline")
+	assert.Equal(t, 1, calls)
+
+	// The failure is cached until RetryInterval has passed, and the last
+	// known version is still returned.
+	latest, err := c.Check(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "v1.1.0", latest)
+	assert.Equal(t, 1, calls)
+
+	entry, err := readCache(c.CacheFile)
+	require.NoError(t, err)
+	assert.True(t, entry.Failed)
+	entry.CheckedAt = time.Now().Add(-2 * DefaultRetryInterval)
+	require.NoError(t, writeCache(c.CacheFile, entry))
+
+	fail = false
+	latest, err = c.Check(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "v1.2.0", latest)
+	assert.Equal(t, 2, calls)
+}
+
+func TestFetchLatestRelease(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"tag_name": "v2.0.0", "name": "Release 2"}`))
+	}))
+	defer server.Close()
+
+	latest, err := fetchLatestRelease(context.Background(), server.URL)
+	require.NoError(t, err)
+	assert.Equal(t, "v2.0.0", latest)
+}
+
+type testCmd struct {
+	ran bool
+}
+
+func (c *testCmd) Run() error {
+	c.ran = true
+	return nil
+}
+
+func TestMiddlewareNotTerminal(t *testing.T) {
+	b := &strings.Builder{}
+	checker := &Checker{
+		Name:           "test",
+		CurrentVersion: "v1.0.0",
+		CacheFile:      filepath.Join(t.TempDir(), "cache.json"),
+		Writer:         b,
+		Latest: func(ctx context.Context) (string, error) {
+			t.Fatal("Latest should not be called")
+			return "", nil
+		},
+	}
+
+	cmd := &testCmd{}
+	err := cli.NewCLI().Use(checker.Middleware()).New("test", cmd).ParseArgs([]string{}).Run()
+	require.NoError(t, err)
+	assert.True(t, cmd.ran)
+	assert.Equal(t, "", b.String())
+}
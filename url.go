@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// URL is a field type for absolute URLs. Unlike url.URL (which can also be
+// used as a field type), it requires a scheme and host, can restrict which
+// schemes are allowed, and redacts any password in help text and errors.
+//
+// Schemes and DefaultScheme are set in the config's default value, e.g. for a
+// proxy flag:
+//
+//	Proxy: cli.URL{Schemes: []string{"http", "https", "socks5"}, DefaultScheme: "http"}
+type URL struct {
+	// Schemes, if not empty, are the schemes which are allowed.
+	Schemes []string
+
+	// DefaultScheme, if set, is used for values without a scheme, e.g.
+	// "localhost:8080".
+	DefaultScheme string
+
+	url *url.URL
+}
+
+// MustURL returns a URL parsed from s, which is useful for default values. It
+// panics if s can't be parsed.
+func MustURL(s string) URL {
+	u := URL{}
+	if err := u.Set(s); err != nil {
+		panic(err)
+	}
+	return u
+}
+
+func (u *URL) Set(s string) error {
+	if u.DefaultScheme != "" && !strings.Contains(s, "://") {
+		s = u.DefaultScheme + "://" + s
+	}
+	parsed, err := url.Parse(s)
+	if err != nil {
+		// url.Error includes the full URL, which might contain a password.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("invalid URL %q: %s", redactURLString(s), err)
+	}
+	if parsed.Scheme == "" {
+		return fmt.Errorf("invalid URL %q: missing scheme", parsed.Redacted())
+	}
+	if parsed.Host == "" && parsed.Opaque == "" && parsed.Scheme != "file" {
+		return fmt.Errorf("invalid URL %q: missing host", parsed.Redacted())
+	}
+	if len(u.Schemes) > 0 && !containsFold(u.Schemes, parsed.Scheme) {
+		return fmt.Errorf(
+			"invalid URL %q: scheme must be one of: %s",
+			parsed.Redacted(), strings.Join(u.Schemes, ", "),
+		)
+	}
+	u.url = parsed
+	return nil
+}
+
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactURLString replaces the password in a URL string which couldn't be
+// parsed, in the same way as url.URL.Redacted.
+func redactURLString(s string) string {
+	i := strings.Index(s, "://")
+	if i < 0 {
+		return s
+	}
+	authority := s[i+3:]
+	if end := strings.IndexAny(authority, "/?#"); end >= 0 {
+		authority = authority[:end]
+	}
+	at := strings.LastIndex(authority, "@")
+	if at < 0 {
+		return s
+	}
+	colon := strings.Index(authority[:at], ":")
+	if colon < 0 {
+		return s
+	}
+	start := i + 3 + colon + 1
+	return s[:start] + "xxxxx" + s[i+3+at:]
+}
+
+// String returns the URL with any password redacted.
+func (u URL) String() string {
+	if u.url == nil {
+		return ""
+	}
+	return u.url.Redacted()
+}
+
+// RedactValue replaces the password in s, so that it isn't shown in errors
+// when s can't be set.
+func (URL) RedactValue(s string) string {
+	if !strings.Contains(s, "://") {
+		// The value may be missing its scheme if DefaultScheme is set.
+		return strings.TrimPrefix(redactURLString("x://"+s), "x://")
+	}
+	return redactURLString(s)
+}
+
+func (URL) Placeholder() string {
+	return "URL"
+}
+
+// URL returns a copy of the parsed URL (including any password), or nil if
+// none has been set.
+func (u URL) URL() *url.URL {
+	if u.url == nil {
+		return nil
+	}
+	c := *u.url
+	if u.url.User != nil {
+		user := *u.url.User
+		c.User = &user
+	}
+	return &c
+}
@@ -0,0 +1,156 @@
+package cli
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// ValueSource provides values for fields which are not set by argument or
+// environment variable. See CLI.ValueSources.
+type ValueSource interface {
+	// LookupValues returns the values for the field with the given flag
+	// name. Multiple values can be returned to set append fields multiple
+	// times. If there is no value for the field, ok should be false.
+	LookupValues(name string) (vals []string, ok bool, err error)
+}
+
+// MapValueSource is a ValueSource which looks up values by flag name in a map.
+// Values are converted to strings in the same way as config file values:
+// slices are converted to multiple values, and maps are converted to JSON.
+//
+// This can be used to consume other configuration libraries, e.g.
+// cli.MapValueSource(viper.AllSettings()) or cli.MapValueSource(k.All()) for
+// koanf.
+type MapValueSource map[string]interface{}
+
+func (s MapValueSource) LookupValues(name string) ([]string, bool, error) {
+	val, ok := s[name]
+	if !ok {
+		return nil, false, nil
+	}
+	vals, err := configValueStrings(normalizeConfigValue(val))
+	if err != nil {
+		return nil, false, err
+	}
+	return vals, true, nil
+}
+
+// normalizeConfigValue converts arbitrarily typed slices and maps (e.g.
+// []string or map[string]string) to the []interface{} and
+// map[string]interface{} types produced by encoding/json.
+func normalizeConfigValue(val interface{}) interface{} {
+	v := reflect.ValueOf(val)
+	switch v.Kind() {
+	case reflect.Slice:
+		if b, ok := val.([]byte); ok {
+			return string(b)
+		}
+		s := make([]interface{}, v.Len())
+		for i := range s {
+			s[i] = normalizeConfigValue(v.Index(i).Interface())
+		}
+		return s
+	case reflect.Map:
+		m := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			m[fmt.Sprintf("%v", iter.Key().Interface())] = normalizeConfigValue(iter.Value().Interface())
+		}
+		return m
+	default:
+		return val
+	}
+}
+
+// parseValueSources sets any unset field values using the values in the
+// config files referenced by fields with the config tag (of this command or
+// its parents), then the CLI's ConfigFile, if there is one, followed by the
+// CLI's ValueSources.
+func (cmd *Command) parseValueSources() error {
+	configSources, err := cmd.configFileSources()
+	if err != nil {
+		return err
+	}
+	cmd.configSources = configSources
+	sources := []ValueSource{}
+	for c := cmd; c != nil; c = c.parent {
+		sources = append(sources, c.configSources...)
+	}
+	if cmd.cli.ConfigFile != "" {
+		values, err := readConfigFile(cmd.cli.ConfigFile)
+		if err != nil {
+			return err
+		}
+		sources = append(sources, configFileSource(values))
+	}
+	sources = append(sources, cmd.cli.ValueSources...)
+
+	for _, f := range cmd.fields {
+		if !cmd.fieldAvailable(f) {
+			continue
+		}
+		for _, source := range sources {
+			if f.value.setCount > 0 {
+				break
+			}
+			vals, ok, err := source.LookupValues(f.Name)
+			if err != nil {
+				return fmt.Errorf("error looking up value for %s: %w", f.Name, err)
+			}
+			// Config files (e.g. dotenv files) may also contain values
+			// keyed by environment variable name.
+			if cs, isConfigFile := source.(configFileSource); !ok && isConfigFile && f.EnvVarName != "" {
+				vals, ok = cs[f.EnvVarName]
+			}
+			if !ok {
+				continue
+			}
+			for _, val := range vals {
+				if err := cmd.setFieldValue(f, val); err != nil {
+					return &FieldError{
+						Name: f.Name,
+						Err:  fmt.Errorf("error parsing %s: %w", f.Name, err),
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Values returns a map of flag names to the current values of the Command's
+// fields (excluding the help flag). This can be used to expose parsed values
+// to other configuration libraries, e.g. viper.MergeConfigMap(cmd.Values()).
+// See also Provider.
+func (cmd *Command) Values() map[string]interface{} {
+	values := map[string]interface{}{}
+	for _, f := range cmd.fields {
+		if !f.value.target.IsValid() {
+			continue
+		}
+		values[f.Name] = f.value.target.Interface()
+	}
+	return values
+}
+
+// Provider returns a CommandProvider for the Command.
+func (cmd *Command) Provider() CommandProvider {
+	return CommandProvider{cmd: cmd}
+}
+
+// CommandProvider exposes the values of a Command's fields. It implements the
+// koanf Provider interface, so it can be passed to koanf's Load method with a
+// nil parser.
+type CommandProvider struct {
+	cmd *Command
+}
+
+// Read returns the result of Command.Values.
+func (p CommandProvider) Read() (map[string]interface{}, error) {
+	return p.cmd.Values(), nil
+}
+
+// ReadBytes is not supported, and always returns an error.
+func (p CommandProvider) ReadBytes() ([]byte, error) {
+	return nil, fmt.Errorf("cli: CommandProvider does not support ReadBytes")
+}
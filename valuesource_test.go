@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type errValueSource struct{}
+
+func (errValueSource) LookupValues(name string) ([]string, bool, error) {
+	return nil, false, assert.AnError
+}
+
+func TestValueSources(t *testing.T) {
+	type Cmd struct {
+		Addr   string
+		Level  int
+		Tags   []string `cli:"append"`
+		Labels string
+		Other  string
+	}
+	cli := NewCLI()
+	cli.ValueSources = []ValueSource{
+		MapValueSource{
+			"addr":   ":8080",
+			"level":  3,
+			"tags":   []string{"a", "b"},
+			"labels": map[string]string{"k": "v"},
+		},
+		MapValueSource{
+			"addr":  "ignored",
+			"other": "second",
+		},
+	}
+	cmd := &Cmd{}
+	r := cli.New("test", cmd).ParseArgs([]string{"--level", "5"})
+	require.NoError(t, r.Err)
+
+	expected := &Cmd{
+		Addr:   ":8080",
+		Level:  5,
+		Tags:   []string{"a", "b"},
+		Labels: `{"k":"v"}`,
+		Other:  "second",
+	}
+	assert.Equal(t, expected, cmd)
+
+	cli.ValueSources = []ValueSource{errValueSource{}}
+	r = cli.New("test", &Cmd{}).ParseArgs([]string{})
+	assert.Error(t, r.Err)
+}
+
+func TestCommandValues(t *testing.T) {
+	type Cmd struct {
+		Addr  string
+		Level int
+		Args  []string `cli:"args"`
+	}
+	c := New("test", &Cmd{Addr: ":8080"})
+	r := c.ParseArgs([]string{"--level", "2", "foo"})
+	require.NoError(t, r.Err)
+
+	expected := map[string]interface{}{
+		"addr":  ":8080",
+		"level": 2,
+	}
+	assert.Equal(t, expected, c.Values())
+
+	values, err := c.Provider().Read()
+	require.NoError(t, err)
+	assert.Equal(t, expected, values)
+	_, err = c.Provider().ReadBytes()
+	assert.Error(t, err)
+}
@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"time"
+)
+
+// DefaultConfigWatchInterval is how often the config file is checked for
+// changes when watching it using CLI.WatchConfig.
+var DefaultConfigWatchInterval = time.Second
+
+type configWatch struct {
+	onChange func(newCfg interface{})
+	interval time.Duration
+}
+
+// WatchConfig sets ConfigFile to path and enables watching it for changes
+// while a command is running. When the file changes, a fresh copy of the
+// running command's config is parsed from the same args, environment
+// variables, and the new config file contents, using the same precedence
+// rules, and passed to onChange. Errors while reloading are printed to
+// ErrWriter. WatchConfig must be called before building commands, and returns
+// the CLI for further method chaining.
+func (cli *CLI) WatchConfig(path string, onChange func(newCfg interface{})) *CLI {
+	cli.ConfigFile = path
+	cli.configWatch = &configWatch{
+		onChange: onChange,
+		interval: DefaultConfigWatchInterval,
+	}
+	return cli
+}
+
+// watchConfig starts polling the config file for changes in a goroutine until
+// ctx is done, calling the configWatch's onChange with a freshly parsed config
+// on each change.
+func (cmd *Command) watchConfig(ctx context.Context) {
+	lastMod, lastSize := statConfigFile(cmd.cli.ConfigFile)
+	go cmd.pollConfig(ctx, lastMod, lastSize)
+}
+
+func (cmd *Command) pollConfig(ctx context.Context, lastMod time.Time, lastSize int64) {
+	w := cmd.cli.configWatch
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		mod, size := statConfigFile(cmd.cli.ConfigFile)
+		if mod.Equal(lastMod) && size == lastSize {
+			continue
+		}
+		lastMod, lastSize = mod, size
+
+		newCfg, err := cmd.reloadConfig()
+		if err != nil {
+			if errWriter := cmd.cli.errWriter(ctx); errWriter != nil {
+				fmt.Fprintf(errWriter, "error: failed to reload config: %s\n", err)
+			}
+			continue
+		}
+		w.onChange(newCfg)
+	}
+}
+
+func statConfigFile(path string) (time.Time, int64) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, -1
+	}
+	return info.ModTime(), info.Size()
+}
+
+// reloadConfig parses a fresh copy of the command's initial config from the
+// args the command was parsed with, environment variables, and the config
+// file.
+func (cmd *Command) reloadConfig() (interface{}, error) {
+	if cmd.snapshot == nil {
+		return nil, fmt.Errorf("config watch was enabled after command was built")
+	}
+	newCfg := reflect.New(reflect.TypeOf(cmd.config).Elem()).Interface()
+	if err := RestoreConfig(newCfg, cmd.snapshot); err != nil {
+		return nil, err
+	}
+	newCmd, err := cmd.cli.Build(cmd.name, newCfg)
+	if err != nil {
+		return nil, err
+	}
+	newCmd.disableEnv = cmd.disableEnv
+
+	p := parser{
+		fields:     newCmd.fieldMap,
+		slashFlags: newCmd.cli.SlashFlags,
+		setValue:   newCmd.setFieldValue,
+	}
+	if err := p.parse(cmd.parsedArgs); err != nil {
+		return nil, err
+	}
+	if newCmd.argsField != nil {
+		if err := newCmd.argsField.set(p.args, newCmd.setFieldValue); err != nil {
+			return nil, err
+		}
+	}
+	if err := newCmd.parseNonArgSources(); err != nil {
+		return nil, err
+	}
+	return newCfg, nil
+}
@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type watchTestCmd struct {
+	Addr  string
+	Level int
+
+	run func(ctx context.Context) error
+}
+
+func (cmd *watchTestCmd) Run(ctx context.Context) error {
+	return cmd.run(ctx)
+}
+
+func TestWatchConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"level": 1}`), 0644))
+
+	changes := make(chan interface{}, 1)
+	cli := NewCLI().WatchConfig(path, func(newCfg interface{}) {
+		changes <- newCfg
+	})
+	cli.configWatch.interval = 10 * time.Millisecond
+
+	cmd := &watchTestCmd{Addr: "default"}
+	cmd.run = func(ctx context.Context) error {
+		assert.Equal(t, 1, cmd.Level)
+		require.NoError(t, os.WriteFile(path, []byte(`{"level": 22, "addr": "file"}`), 0644))
+		select {
+		case newCfg := <-changes:
+			newCmd, ok := newCfg.(*watchTestCmd)
+			require.True(t, ok)
+			assert.Equal(t, "arg", newCmd.Addr)
+			assert.Equal(t, 22, newCmd.Level)
+		case <-time.After(5 * time.Second):
+			t.Error("timed out waiting for config change")
+		}
+		return nil
+	}
+
+	err := cli.New("test", cmd).
+		ParseArgs([]string{"--addr", "arg"}).
+		Run()
+	require.NoError(t, err)
+}
+
+func TestReloadConfigDisableEnv(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"level": 1}`), 0644))
+
+	type Cmd struct {
+		Addr  string `cli:"env=ADDR"`
+		Level int
+	}
+	cli := NewCLI().WatchConfig(path, func(interface{}) {})
+	cli.LookupEnv = func(key string) (string, bool, error) {
+		return "from-env", true, nil
+	}
+	cmd := cli.New("test", &Cmd{}).DisableEnv()
+	require.NoError(t, cmd.ParseArgs([]string{}).Err)
+
+	newCfg, err := cmd.reloadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, &Cmd{Level: 1}, newCfg)
+}
+
+type watchEnvFileTestCmd struct {
+	App   string `cli:"envfile=labels#app"`
+	Level int
+}
+
+func TestReloadConfigEnvFile(t *testing.T) {
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(t.TempDir()))
+	defer os.Chdir(wd)
+	require.NoError(t, os.WriteFile("config.json", []byte(`{"level": 1}`), 0644))
+	require.NoError(t, os.WriteFile("labels", []byte("app=\"web\"\n"), 0644))
+
+	cli := NewCLI().WatchConfig("config.json", func(interface{}) {})
+	cmd := cli.New("test", &watchEnvFileTestCmd{})
+	require.NoError(t, cmd.ParseArgs([]string{}).Err)
+
+	require.NoError(t, os.WriteFile("config.json", []byte(`{"level": 2}`), 0644))
+	newCfg, err := cmd.reloadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, &watchEnvFileTestCmd{App: "web", Level: 2}, newCfg)
+}
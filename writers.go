@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"context"
+	"io"
+	"os"
+	"sync"
+)
+
+type helpWriterContextKey struct{}
+
+type errWriterContextKey struct{}
+
+// writerOverride wraps a writer stored in a context, so that a nil writer
+// can be distinguished from no writer being set.
+type writerOverride struct {
+	w io.Writer
+}
+
+// WithHelpWriter returns a copy of ctx which overrides CLI.HelpWriter when
+// passed to ParseResult.RunWithContext (or RunFatalWithContext), e.g. to
+// capture output per request when embedding a CLI in a server. A nil writer
+// disables help output.
+func WithHelpWriter(ctx context.Context, w io.Writer) context.Context {
+	return context.WithValue(ctx, helpWriterContextKey{}, writerOverride{w})
+}
+
+// WithErrWriter returns a copy of ctx which overrides CLI.ErrWriter when
+// passed to ParseResult.RunWithContext (or RunFatalWithContext). A nil
+// writer disables error output.
+func WithErrWriter(ctx context.Context, w io.Writer) context.Context {
+	return context.WithValue(ctx, errWriterContextKey{}, writerOverride{w})
+}
+
+// helpWriter returns the help writer set in ctx using WithHelpWriter, or the
+// CLI's HelpWriter if there is none.
+func (cli *CLI) helpWriter(ctx context.Context) io.Writer {
+	if o, ok := ctx.Value(helpWriterContextKey{}).(writerOverride); ok {
+		return o.w
+	}
+	return cli.HelpWriter
+}
+
+// errWriter returns the error writer set in ctx using WithErrWriter, or the
+// CLI's ErrWriter if there is none.
+func (cli *CLI) errWriter(ctx context.Context) io.Writer {
+	if o, ok := ctx.Value(errWriterContextKey{}).(writerOverride); ok {
+		return o.w
+	}
+	return cli.ErrWriter
+}
+
+type stdioContextKey struct{}
+
+type stdio struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// WithStdio returns a copy of ctx in which Stdin, Stdout, and Stderr return
+// the given streams. Nil streams are left unchanged.
+func WithStdio(ctx context.Context, stdin io.Reader, stdout io.Writer, stderr io.Writer) context.Context {
+	s := contextStdio(ctx)
+	if stdin != nil {
+		s.stdin = stdin
+	}
+	if stdout != nil {
+		s.stdout = stdout
+	}
+	if stderr != nil {
+		s.stderr = stderr
+	}
+	return context.WithValue(ctx, stdioContextKey{}, s)
+}
+
+func contextStdio(ctx context.Context) stdio {
+	s, ok := ctx.Value(stdioContextKey{}).(stdio)
+	if !ok {
+		return stdio{stdin: os.Stdin, stdout: os.Stdout, stderr: os.Stderr}
+	}
+	return s
+}
+
+// Stdin returns the stream which the command should read input from, which
+// is CLI.Stdin if it was set, otherwise os.Stdin.
+func Stdin(ctx context.Context) io.Reader {
+	return contextStdio(ctx).stdin
+}
+
+// Stdout returns the stream which the command should write output to, which
+// is CLI.Stdout if it was set, otherwise os.Stdout.
+func Stdout(ctx context.Context) io.Writer {
+	return contextStdio(ctx).stdout
+}
+
+// Stderr returns the stream which the command should write diagnostics to,
+// which is CLI.Stderr if it was set, otherwise os.Stderr.
+func Stderr(ctx context.Context) io.Writer {
+	return contextStdio(ctx).stderr
+}
+
+// withTee returns a copy of ctx in which Stdout and Stderr also write to tee.
+func withTee(ctx context.Context, tee io.Writer) context.Context {
+	s := contextStdio(ctx)
+	lw := &lockedWriter{w: tee}
+	s.stdout = io.MultiWriter(s.stdout, lw)
+	s.stderr = io.MultiWriter(s.stderr, lw)
+	return context.WithValue(ctx, stdioContextKey{}, s)
+}
+
+// lockedWriter serializes writes to w, so that it can be shared by multiple
+// streams.
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (lw *lockedWriter) Write(p []byte) (int, error) {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	return lw.w.Write(p)
+}
@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextWriters(t *testing.T) {
+	defaultWriter := &strings.Builder{}
+	cli := CLI{HelpWriter: defaultWriter, ErrWriter: defaultWriter}
+	newCommand := func() *Command {
+		return cli.NewFunc("test", func(ctx context.Context, args []string) error {
+			return fmt.Errorf("oops")
+		})
+	}
+
+	helpWriter := &strings.Builder{}
+	ctx := WithHelpWriter(context.Background(), helpWriter)
+	err := newCommand().ParseArgs([]string{"--help"}).RunWithContext(ctx)
+	assert.Equal(t, ErrHelp, err)
+	assert.Contains(t, helpWriter.String(), "USAGE:")
+
+	errWriter := &strings.Builder{}
+	ctx = WithErrWriter(context.Background(), errWriter)
+	code := newCommand().ParseArgs([]string{}).MainWithContext(ctx)
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "error: oops\n", errWriter.String())
+
+	// A nil writer disables output.
+	ctx = WithHelpWriter(context.Background(), nil)
+	err = newCommand().ParseArgs([]string{"--help"}).RunWithContext(ctx)
+	assert.Equal(t, ErrHelp, err)
+
+	assert.Empty(t, defaultWriter.String())
+}
+
+func TestStdio(t *testing.T) {
+	assert.Equal(t, os.Stdout, Stdout(context.Background()))
+
+	stdout := &strings.Builder{}
+	cli := CLI{Stdin: strings.NewReader("world"), Stdout: stdout}
+	cmd := cli.NewFunc("test", func(ctx context.Context, args []string) error {
+		b, err := io.ReadAll(Stdin(ctx))
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(Stdout(ctx), "hello %s\n", b)
+		assert.Equal(t, os.Stderr, Stderr(ctx))
+		return nil
+	})
+	assert.NoError(t, cmd.ParseArgs([]string{}).Run())
+	assert.Equal(t, "hello world\n", stdout.String())
+}
+
+func TestTeeOutput(t *testing.T) {
+	stdout := &strings.Builder{}
+	stderr := &strings.Builder{}
+	tee := &strings.Builder{}
+	cli := CLI{Stdout: stdout, Stderr: stderr, TeeOutput: tee}
+	cmd := cli.NewFunc("test", func(ctx context.Context, args []string) error {
+		fmt.Fprintln(Stdout(ctx), "out 1")
+		fmt.Fprintln(Stderr(ctx), "err")
+		fmt.Fprintln(Stdout(ctx), "out 2")
+		return nil
+	})
+	assert.NoError(t, cmd.ParseArgs([]string{}).Run())
+	assert.Equal(t, "out 1\nout 2\n", stdout.String())
+	assert.Equal(t, "err\n", stderr.String())
+	assert.Equal(t, "out 1\nerr\nout 2\n", tee.String())
+}